@@ -249,3 +249,95 @@ func TestIntegrationUploadDownload(t *testing.T) {
 		t.Fatal("the uploading is not succeeding for some reason:", rf.Files[0], rf.Files[1])
 	}
 }
+
+// TestIntegrationUploadFormsContract uploads a small file to a local host and
+// checks that the renter reports the upload and that the contract used for the
+// upload appears in the consensus set.
+func TestIntegrationUploadFormsContract(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	st, err := createServerTester("TestIntegrationUploadFormsContract")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.server.Close()
+
+	// Announce the host and start accepting contracts.
+	err = st.announceHost()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = st.acceptContracts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = st.setHostStorage()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Set an allowance for the renter, allowing a contract to be formed.
+	allowanceValues := url.Values{}
+	allowanceValues.Set("funds", "10000000000000000000000000000") // 10k SC
+	allowanceValues.Set("period", "5")
+	err = st.stdPostAPI("/renter", allowanceValues)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Upload a small file.
+	path := filepath.Join(st.dir, "test.dat")
+	err = createRandFile(path, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	uploadValues := url.Values{}
+	uploadValues.Set("source", path)
+	err = st.stdPostAPI("/renter/upload/test", uploadValues)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rf RenterFiles
+	for i := 0; i < 200 && (len(rf.Files) != 1 || rf.Files[0].UploadProgress < 10); i++ {
+		st.getAPI("/renter/files", &rf)
+		time.Sleep(100 * time.Millisecond)
+	}
+	if len(rf.Files) != 1 || rf.Files[0].UploadProgress < 10 {
+		t.Fatal("the uploading is not succeeding for some reason:", rf.Files)
+	}
+	if rf.Files[0].SiaPath != "test" || rf.Files[0].Filesize != 1024 {
+		t.Fatal("renter is reporting the wrong file:", rf.Files[0])
+	}
+
+	// The renter should have a contract, and after mining a block the
+	// contract should be known to the consensus set.
+	var contracts RenterContracts
+	if err = st.getAPI("/renter/contracts", &contracts); err != nil {
+		t.Fatal(err)
+	}
+	if len(contracts.Contracts) == 0 {
+		t.Fatal("renter has no contracts after uploading")
+	}
+	_, err = st.miner.AddBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	confirmed := make(map[types.FileContractID]struct{})
+	for height := types.BlockHeight(0); height <= st.cs.Height(); height++ {
+		block, exists := st.cs.BlockAtHeight(height)
+		if !exists {
+			t.Fatal("missing block at height", height)
+		}
+		for _, txn := range block.Transactions {
+			for i := range txn.FileContracts {
+				confirmed[txn.FileContractID(uint64(i))] = struct{}{}
+			}
+		}
+	}
+	for _, c := range contracts.Contracts {
+		if _, exists := confirmed[c.ID]; !exists {
+			t.Fatal("contract", c.ID, "is not in the consensus set")
+		}
+	}
+}
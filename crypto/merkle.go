@@ -2,6 +2,7 @@ package crypto
 
 import (
 	"bytes"
+	"io"
 
 	"github.com/NebulousLabs/Sia/encoding"
 
@@ -109,6 +110,75 @@ func MerkleRoot(b []byte) Hash {
 	return t.Root()
 }
 
+// ReaderMerkleRoot returns the Merkle root of the data read from 'r'.
+func ReaderMerkleRoot(r io.Reader) (Hash, error) {
+	mtb := NewMerkleTreeBuilder()
+	buf := make([]byte, SegmentSize)
+	for {
+		n, err := io.ReadFull(r, buf)
+		mtb.PushSegment(buf[:n])
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		} else if err != nil {
+			return Hash{}, err
+		}
+	}
+	return mtb.Root(), nil
+}
+
+// A MerkleTreeBuilder computes the Merkle root of data that is provided in
+// chunks, such as a file that is being uploaded piece by piece. At most one
+// segment of data is buffered by the builder.
+type MerkleTreeBuilder struct {
+	tree *MerkleTree
+	buf  []byte
+}
+
+// NewMerkleTreeBuilder returns an empty MerkleTreeBuilder.
+func NewMerkleTreeBuilder() *MerkleTreeBuilder {
+	return &MerkleTreeBuilder{
+		tree: NewTree(),
+		buf:  make([]byte, 0, SegmentSize),
+	}
+}
+
+// PushSegment adds data to the builder. The data does not need to be aligned
+// to SegmentSize; any data that does not fill a whole segment is held until
+// the next call to PushSegment or Root.
+func (mtb *MerkleTreeBuilder) PushSegment(data []byte) {
+	// Complete the buffered segment first.
+	if len(mtb.buf) > 0 {
+		n := SegmentSize - len(mtb.buf)
+		if n > len(data) {
+			n = len(data)
+		}
+		mtb.buf = append(mtb.buf, data[:n]...)
+		data = data[n:]
+		if len(mtb.buf) < SegmentSize {
+			return
+		}
+		mtb.tree.Push(mtb.buf)
+		mtb.buf = mtb.buf[:0]
+	}
+	for len(data) >= SegmentSize {
+		mtb.tree.Push(data[:SegmentSize])
+		data = data[SegmentSize:]
+	}
+	mtb.buf = append(mtb.buf, data...)
+}
+
+// Root returns the Merkle root of all data pushed to the builder. A partial
+// final segment is added to the tree, which means that no more data should be
+// pushed after calling Root unless the data pushed so far was a multiple of
+// SegmentSize.
+func (mtb *MerkleTreeBuilder) Root() Hash {
+	if len(mtb.buf) > 0 {
+		mtb.tree.Push(mtb.buf)
+		mtb.buf = mtb.buf[:0]
+	}
+	return mtb.tree.Root()
+}
+
 // MerkleProof builds a Merkle proof that the data at segment 'proofIndex' is a
 // part of the Merkle root formed by 'b'.
 func MerkleProof(b []byte, proofIndex uint64) (base []byte, hashSet []Hash) {
@@ -137,6 +207,46 @@ func MerkleProof(b []byte, proofIndex uint64) (base []byte, hashSet []Hash) {
 	return base, hashSet
 }
 
+// BuildReaderProof builds a Merkle proof that the data at segment
+// 'proofIndex' is a part of the Merkle root formed by the data read from 'r'.
+// Only one segment of data is held in memory at a time, which makes
+// BuildReaderProof suitable for proving data that is stored on disk.
+func BuildReaderProof(r io.Reader, proofIndex uint64) (base []byte, hashSet []Hash, err error) {
+	// Create the tree.
+	t := NewTree()
+	t.SetIndex(proofIndex)
+
+	// Fill the tree, one segment at a time. A new buffer is used for each
+	// segment because the tree holds on to the data of the proven segment.
+	for {
+		buf := make([]byte, SegmentSize)
+		n, err := io.ReadFull(r, buf)
+		if n > 0 {
+			t.Push(buf[:n])
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		} else if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// Get the proof and convert it to a base + hash set.
+	_, proof, _, numLeaves := t.Prove()
+	if len(proof) == 0 || proofIndex >= numLeaves {
+		// There's no proof, because there's no data at the proof index.
+		// Return blank values.
+		return nil, nil, nil
+	}
+
+	base = proof[0]
+	hashSet = make([]Hash, len(proof)-1)
+	for i, p := range proof[1:] {
+		copy(hashSet[i][:], p)
+	}
+	return base, hashSet, nil
+}
+
 // VerifySegment will verify that a segment, given the proof, is a part of a
 // Merkle root.
 func VerifySegment(base []byte, hashSet []Hash, numSegments, proofIndex uint64, root Hash) bool {
@@ -1,6 +1,7 @@
 package crypto
 
 import (
+	"bytes"
 	"crypto/rand"
 	"testing"
 )
@@ -179,3 +180,89 @@ func TestOddDataSize(t *testing.T) {
 		}
 	}
 }
+
+// TestMerkleTreeBuilder checks that the root computed incrementally by a
+// MerkleTreeBuilder matches the root computed over the whole data.
+func TestMerkleTreeBuilder(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	// Use a size that is not a multiple of SegmentSize.
+	data := make([]byte, 4<<20+17)
+	_, err := rand.Read(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := MerkleRoot(data)
+	readerRoot, err := ReaderMerkleRoot(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if readerRoot != root {
+		t.Fatal("ReaderMerkleRoot does not match MerkleRoot")
+	}
+
+	// Push the data in chunks of varying sizes, none of which are aligned to
+	// segments.
+	chunkSizes := []int{1, 63, 65, 1000, 4096, 12345}
+	mtb := NewMerkleTreeBuilder()
+	remaining := data
+	for i := 0; len(remaining) > 0; i++ {
+		n := chunkSizes[i%len(chunkSizes)]
+		if n > len(remaining) {
+			n = len(remaining)
+		}
+		mtb.PushSegment(remaining[:n])
+		remaining = remaining[n:]
+	}
+	if mtb.Root() != root {
+		t.Fatal("incremental root does not match the whole-file root")
+	}
+
+	// Check an empty builder and a segment-aligned builder.
+	if NewMerkleTreeBuilder().Root() != MerkleRoot(nil) {
+		t.Error("empty builder has the wrong root")
+	}
+	mtb = NewMerkleTreeBuilder()
+	mtb.PushSegment(data[:SegmentSize*4])
+	if mtb.Root() != MerkleRoot(data[:SegmentSize*4]) {
+		t.Error("aligned builder has the wrong root")
+	}
+}
+
+// TestBuildReaderProof checks that BuildReaderProof produces the same proofs as
+// MerkleProof, including proofs of a padded final segment.
+func TestBuildReaderProof(t *testing.T) {
+	data := make([]byte, (5*SegmentSize)+10)
+	rand.Read(data)
+	rootHash := MerkleRoot(data)
+	numSegments := CalculateLeaves(uint64(len(data)))
+
+	for i := uint64(0); i < numSegments; i++ {
+		base, hashSet, err := BuildReaderProof(bytes.NewReader(data), i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expBase, expHashSet := MerkleProof(data, i)
+		if !bytes.Equal(base, expBase) || len(hashSet) != len(expHashSet) {
+			t.Fatal("reader proof does not match MerkleProof for index", i)
+		}
+		for j := range hashSet {
+			if hashSet[j] != expHashSet[j] {
+				t.Fatal("reader proof does not match MerkleProof for index", i)
+			}
+		}
+		if !VerifySegment(base, hashSet, numSegments, i, rootHash) {
+			t.Error("reader proof", i, "did not pass verification")
+		}
+	}
+
+	// A proof index beyond the end of the data should produce no proof.
+	base, hashSet, err := BuildReaderProof(bytes.NewReader(data), numSegments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if base != nil || hashSet != nil {
+		t.Error("expected an empty proof for an out-of-range index")
+	}
+}
@@ -0,0 +1,151 @@
+package crypto
+
+import (
+	"errors"
+	"io"
+	"os"
+)
+
+var (
+	errInvalidRange = errors.New("segment range is empty or extends past the end of the data")
+)
+
+// leafHash returns the hash of a leaf of a Merkle tree.
+func leafHash(data []byte) (h Hash) {
+	hasher := NewHash()
+	hasher.Write([]byte{0})
+	hasher.Write(data)
+	copy(h[:], hasher.Sum(nil))
+	return
+}
+
+// nodeHash returns the hash of an inner node of a Merkle tree given the
+// hashes of its children.
+func nodeHash(left, right Hash) (h Hash) {
+	hasher := NewHash()
+	hasher.Write([]byte{1})
+	hasher.Write(left[:])
+	hasher.Write(right[:])
+	copy(h[:], hasher.Sum(nil))
+	return
+}
+
+// splitPoint returns the number of leaves in the left subtree of a Merkle
+// tree with 'numLeaves' leaves, which is the largest power of two that is
+// smaller than 'numLeaves'. 'numLeaves' must be at least 2.
+func splitPoint(numLeaves uint64) uint64 {
+	p := uint64(1)
+	for p*2 < numLeaves {
+		p *= 2
+	}
+	return p
+}
+
+// BuildRangeProof builds a Merkle proof that the segments in the range
+// [startSegment, endSegment) are a part of the Merkle root of the data in
+// 'r'. The proof consists of the segments in the range, and the roots of the
+// subtrees that contain no segments of the range, ordered from left to right.
+// Subtrees shared by the path of multiple segments only appear in the hash
+// set once, which makes a range proof smaller than a proof per segment.
+func BuildRangeProof(r io.ReadSeeker, startSegment, endSegment uint64) (segments [][]byte, hashSet []Hash, err error) {
+	size, err := r.Seek(0, os.SEEK_END)
+	if err != nil {
+		return nil, nil, err
+	}
+	numSegments := uint64(size) / SegmentSize
+	if uint64(size)%SegmentSize != 0 {
+		numSegments++
+	}
+	if startSegment >= endSegment || endSegment > numSegments {
+		return nil, nil, errInvalidRange
+	}
+
+	var build func(lo, hi uint64) error
+	build = func(lo, hi uint64) error {
+		// Subtrees outside of the range are added to the hash set.
+		if hi <= startSegment || lo >= endSegment {
+			_, err := r.Seek(int64(lo*SegmentSize), os.SEEK_SET)
+			if err != nil {
+				return err
+			}
+			root, err := ReaderMerkleRoot(io.LimitReader(r, int64((hi-lo)*SegmentSize)))
+			if err != nil {
+				return err
+			}
+			hashSet = append(hashSet, root)
+			return nil
+		}
+		// Leaves inside of the range are added to the segments.
+		if hi-lo == 1 {
+			_, err := r.Seek(int64(lo*SegmentSize), os.SEEK_SET)
+			if err != nil {
+				return err
+			}
+			segment := make([]byte, SegmentSize)
+			n, err := io.ReadFull(r, segment)
+			if err != nil && err != io.ErrUnexpectedEOF {
+				return err
+			}
+			segments = append(segments, segment[:n])
+			return nil
+		}
+		mid := lo + splitPoint(hi-lo)
+		if err := build(lo, mid); err != nil {
+			return err
+		}
+		return build(mid, hi)
+	}
+	err = build(0, numSegments)
+	if err != nil {
+		return nil, nil, err
+	}
+	return segments, hashSet, nil
+}
+
+// VerifyRangeProof verifies that 'segments' are the segments in the range
+// [startSegment, endSegment) of data that consists of 'numSegments' segments
+// and has the Merkle root 'root'. 'hashSet' is the hash set returned by
+// BuildRangeProof.
+func VerifyRangeProof(segments [][]byte, hashSet []Hash, startSegment, endSegment, numSegments uint64, root Hash) bool {
+	if startSegment >= endSegment || endSegment > numSegments {
+		return false
+	}
+	if uint64(len(segments)) != endSegment-startSegment {
+		return false
+	}
+	// Only the final segment of the data may be shorter than SegmentSize.
+	for i, segment := range segments {
+		index := startSegment + uint64(i)
+		if len(segment) == 0 || len(segment) > SegmentSize || (len(segment) != SegmentSize && index != numSegments-1) {
+			return false
+		}
+	}
+
+	// Rebuild the root, consuming the hash set from left to right.
+	var verify func(lo, hi uint64) (Hash, bool)
+	verify = func(lo, hi uint64) (Hash, bool) {
+		if hi <= startSegment || lo >= endSegment {
+			if len(hashSet) == 0 {
+				return Hash{}, false
+			}
+			h := hashSet[0]
+			hashSet = hashSet[1:]
+			return h, true
+		}
+		if hi-lo == 1 {
+			return leafHash(segments[lo-startSegment]), true
+		}
+		mid := lo + splitPoint(hi-lo)
+		left, ok := verify(lo, mid)
+		if !ok {
+			return Hash{}, false
+		}
+		right, ok := verify(mid, hi)
+		if !ok {
+			return Hash{}, false
+		}
+		return nodeHash(left, right), true
+	}
+	computed, ok := verify(0, numSegments)
+	return ok && len(hashSet) == 0 && computed == root
+}
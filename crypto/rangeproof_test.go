@@ -0,0 +1,102 @@
+package crypto
+
+import (
+	"bytes"
+	"testing"
+)
+
+// TestRangeProof builds and verifies range proofs over data whose final
+// segment is not a full segment.
+func TestRangeProof(t *testing.T) {
+	data, err := RandBytes(SegmentSize*13 + 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := MerkleRoot(data)
+	numSegments := CalculateLeaves(uint64(len(data)))
+
+	tests := []struct {
+		start, end uint64
+	}{
+		{0, 1},                         // first segment
+		{5, 6},                         // single segment
+		{numSegments - 1, numSegments}, // final, partial segment
+		{3, 9},                         // range spanning several subtrees
+		{0, numSegments},               // full file
+	}
+	for _, test := range tests {
+		segments, hashSet, err := BuildRangeProof(bytes.NewReader(data), test.start, test.end)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, segment := range segments {
+			offset := (test.start + uint64(i)) * SegmentSize
+			if !bytes.Equal(segment, data[offset:offset+uint64(len(segment))]) {
+				t.Fatal("range proof contains the wrong segment data")
+			}
+		}
+		if test.start == 0 && test.end == numSegments && len(hashSet) != 0 {
+			t.Error("full file proof should not need a hash set")
+		}
+		if !VerifyRangeProof(segments, hashSet, test.start, test.end, numSegments, root) {
+			t.Fatalf("valid range proof [%v, %v) was rejected", test.start, test.end)
+		}
+
+		// A proof should not verify against a different range or root.
+		if VerifyRangeProof(segments, hashSet, test.start, test.end, numSegments, Hash{}) {
+			t.Error("range proof verified against the wrong root")
+		}
+		if test.end < numSegments && VerifyRangeProof(segments, hashSet, test.start+1, test.end+1, numSegments, root) {
+			t.Error("range proof verified for the wrong range")
+		}
+
+		// Tamper with the segments and the hash set.
+		tampered := make([][]byte, len(segments))
+		copy(tampered, segments)
+		tampered[0] = append([]byte(nil), segments[0]...)
+		tampered[0][0]++
+		if VerifyRangeProof(tampered, hashSet, test.start, test.end, numSegments, root) {
+			t.Error("range proof with a tampered segment was accepted")
+		}
+		if len(hashSet) > 0 {
+			tamperedSet := append([]Hash(nil), hashSet...)
+			tamperedSet[len(tamperedSet)-1][0]++
+			if VerifyRangeProof(segments, tamperedSet, test.start, test.end, numSegments, root) {
+				t.Error("range proof with a tampered hash set was accepted")
+			}
+			if VerifyRangeProof(segments, hashSet[1:], test.start, test.end, numSegments, root) {
+				t.Error("range proof with a truncated hash set was accepted")
+			}
+		}
+	}
+}
+
+// TestRangeProofOutOfRange checks that range proofs cannot be built or
+// verified for invalid ranges.
+func TestRangeProofOutOfRange(t *testing.T) {
+	data, err := RandBytes(SegmentSize * 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		start, end uint64
+	}{
+		{2, 2},
+		{3, 2},
+		{0, 5},
+		{4, 5},
+	}
+	for _, test := range tests {
+		_, _, err := BuildRangeProof(bytes.NewReader(data), test.start, test.end)
+		if err != errInvalidRange {
+			t.Errorf("expected errInvalidRange for [%v, %v), got %v", test.start, test.end, err)
+		}
+		if VerifyRangeProof(nil, nil, test.start, test.end, 4, MerkleRoot(data)) {
+			t.Errorf("proof for [%v, %v) was accepted", test.start, test.end)
+		}
+	}
+	_, _, err = BuildRangeProof(bytes.NewReader(nil), 0, 1)
+	if err != errInvalidRange {
+		t.Error("expected errInvalidRange for empty data, got", err)
+	}
+}
@@ -20,6 +20,20 @@ const (
 	DiffRevert DiffDirection = false
 )
 
+const (
+	// FileContractWindowOpened indicates that the storage proof window of a
+	// file contract has opened.
+	FileContractWindowOpened FileContractEventType = iota
+
+	// FileContractProofValid indicates that a file contract was resolved by
+	// a valid storage proof.
+	FileContractProofValid
+
+	// FileContractProofMissed indicates that the storage proof window of a
+	// file contract closed without a storage proof being submitted.
+	FileContractProofMissed
+)
+
 var (
 	// ConsensusChangeBeginning is a special consensus change id that tells the
 	// consensus set to provide all consensus changes starting from the very
@@ -39,6 +53,14 @@ var (
 	// target.
 	ErrBlockUnsolved = errors.New("block does not meet target")
 
+	// ErrClosed is returned by the consensus set when an operation is
+	// attempted after the consensus set has been closed.
+	ErrClosed = errors.New("consensus set has been closed")
+
+	// ErrDuplicateTransaction indicates that a block contains the same
+	// transaction more than once.
+	ErrDuplicateTransaction = errors.New("block contains the same transaction more than once")
+
 	// ErrInvalidConsensusChangeID indicates that ConsensusSetPersistSubscribe
 	// was called with a consensus change id that is not recognized. Most
 	// commonly, this means that the consensus set was deleted or replaced and
@@ -46,6 +68,12 @@ var (
 	// should be handled by the module, and not reported to the user.
 	ErrInvalidConsensusChangeID = errors.New("consensus subscription has invalid id - files are inconsistent")
 
+	// ErrMissingSiacoinOutput indicates that a transaction spends a siacoin
+	// output that does not exist in the consensus set. The output may have
+	// been spent already, or may be created by a transaction that has not
+	// been seen yet.
+	ErrMissingSiacoinOutput = errors.New("transaction spends a nonexisting siacoin output")
+
 	// ErrNonExtendingBlock indicates that a block is valid but does not result
 	// in a fork that is the heaviest known fork - the consensus set has not
 	// changed as a result of seeing the block.
@@ -88,6 +116,16 @@ type (
 		// applied.
 		AppliedBlocks []types.Block
 
+		// RevertedTransactionIDs lists the ids of the transactions in the
+		// reverted blocks, in the order that the transactions were reverted.
+		// Subscribers can use the list to mark transactions as unconfirmed
+		// without inspecting the reverted blocks.
+		RevertedTransactionIDs []types.TransactionID
+
+		// AppliedTransactionIDs lists the ids of the transactions in the
+		// applied blocks, in the order that the transactions were applied.
+		AppliedTransactionIDs []types.TransactionID
+
 		// SiacoinOutputDiffs contains the set of siacoin diffs that were applied
 		// to the consensus set in the recent change. The direction for the set of
 		// diffs is 'DiffApply'.
@@ -111,6 +149,11 @@ type (
 		// consensus set in the recent change.
 		SiafundPoolDiffs []SiafundPoolDiff
 
+		// FileContractEvents are the file contract events that happened in
+		// the blocks of the recent change. Events of reverted blocks have the
+		// direction 'DiffRevert', indicating that the event was undone.
+		FileContractEvents []FileContractEvent
+
 		// ChildTarget defines the target of any block that would be the child
 		// of the block most recently appended to the consensus set.
 		ChildTarget types.Target
@@ -159,6 +202,20 @@ type (
 		MaturityHeight types.BlockHeight
 	}
 
+	// A FileContractEventType indicates what happened to a file contract in
+	// a FileContractEvent.
+	FileContractEventType int
+
+	// A FileContractEvent indicates that the storage proof window of a file
+	// contract opened, or that the file contract was resolved, at a given
+	// height.
+	FileContractEvent struct {
+		Direction DiffDirection
+		ID        types.FileContractID
+		Type      FileContractEventType
+		Height    types.BlockHeight
+	}
+
 	// A SiafundPoolDiff contains the value of the siafundPool before the block
 	// was applied, and after the block was applied. When applying the diff, set
 	// siafundPool to 'Adjusted'. When reverting the diff, set siafundPool to
@@ -184,6 +241,11 @@ type (
 		// bool to indicate whether that block exists.
 		BlockAtHeight(types.BlockHeight) (types.Block, bool)
 
+		// CheckConsistency verifies the siacoin and siafund totals of the
+		// consensus set and the stability of its checksum, returning an error
+		// if an inconsistency is found.
+		CheckConsistency() error
+
 		// ChildTarget returns the target required to extend the current heaviest
 		// fork. This function is typically used by miners looking to extend the
 		// heaviest fork.
@@ -203,6 +265,14 @@ type (
 		// blockchain.
 		CurrentBlock() types.Block
 
+		// CurrentBlockAndHeight returns the latest block in the heaviest
+		// known blockchain and its height, read atomically.
+		CurrentBlockAndHeight() (types.Block, types.BlockHeight)
+
+		// CurrentSupply returns the total number of siacoins that have been
+		// created as of the current block.
+		CurrentSupply() types.Currency
+
 		// Flush will cause the consensus set to finish all in-progress
 		// routines.
 		Flush() error
@@ -213,16 +283,35 @@ type (
 		// Synced returns true if the consensus set is synced with the network.
 		Synced() bool
 
+		// SyncSubscribers blocks until every subscriber has finished
+		// processing the most recent consensus change.
+		SyncSubscribers()
+
+		// BlockSizeLimit returns the maximum size of a block in bytes.
+		BlockSizeLimit() uint64
+
+		// Coinbase returns the coinbase of a block at the given height, not
+		// including any miner fees.
+		Coinbase(types.BlockHeight) types.Currency
+
 		// InCurrentPath returns true if the block id presented is found in the
 		// current path, false otherwise.
 		InCurrentPath(types.BlockID) bool
 
+		// MaximumValidChildTimestamp returns the latest timestamp that the
+		// next block can have without being treated as a future block.
+		MaximumValidChildTimestamp() types.Timestamp
+
 		// MinimumValidChildTimestamp returns the earliest timestamp that is
 		// valid on the current longest fork according to the consensus set. This is
 		// a required piece of information for the miner, who could otherwise be at
 		// risk of mining invalid blocks.
 		MinimumValidChildTimestamp(types.BlockID) (types.Timestamp, bool)
 
+		// SiafundClaimValue returns the number of siacoins that would be
+		// claimed by spending the siafund output with the given id.
+		SiafundClaimValue(types.SiafundOutputID) (types.Currency, error)
+
 		// StorageProofSegment returns the segment to be used in the storage proof for
 		// a given file contract.
 		StorageProofSegment(types.FileContractID) (uint64, error)
@@ -233,6 +322,10 @@ type (
 		// transaction.
 		TryTransactionSet([]types.Transaction) (ConsensusChange, error)
 
+		// ValidTransaction checks that a transaction is valid in the current
+		// consensus set, without accepting it.
+		ValidTransaction(types.Transaction) error
+
 		// Unsubscribe removes a subscriber from the list of subscribers,
 		// allowing for garbage collection and rescanning. If the subscriber is
 		// not found in the subscriber database, no action is taken.
@@ -248,6 +341,8 @@ func (cc ConsensusChange) Append(cc2 ConsensusChange) ConsensusChange {
 	return ConsensusChange{
 		RevertedBlocks:            append(cc.RevertedBlocks, cc2.RevertedBlocks...),
 		AppliedBlocks:             append(cc.AppliedBlocks, cc2.AppliedBlocks...),
+		RevertedTransactionIDs:    append(cc.RevertedTransactionIDs, cc2.RevertedTransactionIDs...),
+		AppliedTransactionIDs:     append(cc.AppliedTransactionIDs, cc2.AppliedTransactionIDs...),
 		SiacoinOutputDiffs:        append(cc.SiacoinOutputDiffs, cc2.SiacoinOutputDiffs...),
 		FileContractDiffs:         append(cc.FileContractDiffs, cc2.FileContractDiffs...),
 		SiafundOutputDiffs:        append(cc.SiafundOutputDiffs, cc2.SiafundOutputDiffs...),
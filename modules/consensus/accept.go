@@ -3,7 +3,6 @@ package consensus
 import (
 	"bytes"
 	"errors"
-	"time"
 
 	"github.com/NebulousLabs/Sia/build"
 	"github.com/NebulousLabs/Sia/modules"
@@ -129,7 +128,8 @@ func (cs *ConsensusSet) validateHeader(tx dbTx, h types.BlockHeader) error {
 	// future and extreme future because there is an assumption that by the time
 	// the extreme future arrives, this block will no longer be a part of the
 	// longest fork because it will have been ignored by all of the miners.
-	if h.Timestamp > types.CurrentTimestamp()+types.ExtremeFutureThreshold {
+	_, extremeFutureThreshold := futureThresholds(cs.futureThreshold, cs.extremeFutureThreshold)
+	if h.Timestamp > cs.clock.Now()+extremeFutureThreshold {
 		return errExtremeFutureTimestamp
 	}
 
@@ -154,6 +154,7 @@ func (cs *ConsensusSet) validateHeader(tx dbTx, h types.BlockHeader) error {
 // unneeded.
 func (cs *ConsensusSet) addBlockToTree(b types.Block) (ce changeEntry, err error) {
 	var nonExtending bool
+	cs.pendingChanges = nil
 	err = cs.db.Update(func(tx *bolt.Tx) error {
 		pb, err := getBlockMap(tx, b.ParentID)
 		if build.DEBUG && err != nil {
@@ -197,8 +198,10 @@ func (cs *ConsensusSet) addBlockToTree(b types.Block) (ce changeEntry, err error
 		return nil
 	})
 	if err != nil {
+		cs.pendingChanges = nil
 		return changeEntry{}, err
 	}
+	cs.commitChanges()
 	if nonExtending {
 		return changeEntry{}, modules.ErrNonExtendingBlock
 	}
@@ -221,6 +224,14 @@ func (cs *ConsensusSet) managedAcceptBlock(b types.Block) error {
 	// failure to unlock before returning an error will cause a deadlock.
 	cs.mu.Lock()
 
+	// Blocks that were recently seen can be rejected without opening a
+	// database transaction.
+	id := b.ID()
+	if cs.recentBlocks.contains(id) {
+		cs.mu.Unlock()
+		return modules.ErrBlockKnown
+	}
+
 	// Start verification inside of a bolt View tx.
 	err := cs.db.View(func(tx *bolt.Tx) error {
 		// Do not accept a block if the database is inconsistent.
@@ -238,26 +249,14 @@ func (cs *ConsensusSet) managedAcceptBlock(b types.Block) error {
 			// too far in the future.
 			//
 			// TODO: an attacker could mine many blocks off the genesis block all in the
-			// future and we would spawn a goroutine per each block. To fix this, either
-			// ban peers that send lots of future blocks and stop spawning goroutines
-			// after we are already waiting on a large number of future blocks.
-			//
-			// TODO: an attacker could broadcast a future block many times and we would
-			// spawn a goroutine for each broadcast. To fix this we should create a
-			// cache of future blocks, like we already do for DoS blocks, and only spawn
-			// a goroutine if we haven't already spawned one for that block. To limit
-			// the size of the cache of future blocks, make it a constant size (say 50)
-			// over which we would evict the block furthest in the future before adding
-			// a new block to the cache.
+			// future and the queue of future blocks would grow without bound. To fix
+			// this, either ban peers that send lots of future blocks or limit the size
+			// of the queue, evicting the block furthest in the future.
 			if err == errFutureTimestamp {
-				go func() {
-					time.Sleep(time.Duration(b.Timestamp-(types.CurrentTimestamp()+types.FutureThreshold)) * time.Second)
-					err := cs.managedAcceptBlock(b)
-					if err != nil {
-						cs.log.Debugln("WARN: failed to accept a future block:", err)
-					}
-					cs.managedBroadcastBlock(b)
-				}()
+				cs.queueFutureBlock(b)
+			}
+			if err == modules.ErrBlockKnown {
+				cs.recentBlocks.add(id)
 			}
 			return err
 		}
@@ -273,6 +272,10 @@ func (cs *ConsensusSet) managedAcceptBlock(b types.Block) error {
 	// error is returned if verification fails or if the block does not extend
 	// the longest fork.
 	changeEntry, err := cs.addBlockToTree(b)
+	if err == nil || err == modules.ErrNonExtendingBlock {
+		// The block is now in the block map.
+		cs.recentBlocks.add(id)
+	}
 	if err != nil {
 		cs.mu.Unlock()
 		return err
@@ -287,6 +290,14 @@ func (cs *ConsensusSet) managedAcceptBlock(b types.Block) error {
 	defer cs.mu.DemotedUnlock()
 	if len(changeEntry.AppliedBlocks) > 0 {
 		cs.readlockUpdateSubscribers(changeEntry)
+
+		// Update the height that the gateway shares with peers.
+		var height types.BlockHeight
+		_ = cs.db.View(func(tx *bolt.Tx) error {
+			height = blockHeight(tx)
+			return nil
+		})
+		cs.gateway.SetBlockHeight(height)
 	}
 	return nil
 }
@@ -301,7 +312,7 @@ func (cs *ConsensusSet) managedAcceptBlock(b types.Block) error {
 func (cs *ConsensusSet) AcceptBlock(b types.Block) error {
 	err := cs.tg.Add()
 	if err != nil {
-		return err
+		return modules.ErrClosed
 	}
 	defer cs.tg.Done()
 
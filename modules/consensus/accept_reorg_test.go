@@ -274,6 +274,86 @@ func TestIntegrationComplexReorg(t *testing.T) {
 	rs.fullReorg()
 }
 
+// TestIntegrationTieBreak creates two competing blocks of equal work and
+// checks that each consensus set keeps the block it saw first, and that the
+// consensus sets converge once one of the blocks is extended.
+func TestIntegrationTieBreak(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	cst1, err := blankConsensusSetTester("TestIntegrationTieBreak - 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cst1.Close()
+	cst2, err := blankConsensusSetTester("TestIntegrationTieBreak - 2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cst2.Close()
+
+	// Create two competing children of the genesis block.
+	var blocks []types.Block
+	for i := 0; i < 2; i++ {
+		b, target, err := cst1.miner.BlockForWork()
+		if err != nil {
+			t.Fatal(err)
+		}
+		b.MinerPayouts[0].UnlockHash[0] = byte(i)
+		solved := false
+		for !solved {
+			b, solved = cst1.miner.SolveBlock(b, target)
+		}
+		blocks = append(blocks, b)
+	}
+
+	// Give the blocks to the consensus sets in opposite orders. Each
+	// consensus set should keep the block that it saw first.
+	for _, b := range []types.Block{blocks[0], blocks[1]} {
+		err = cst1.cs.AcceptBlock(b)
+		if err != nil && err != modules.ErrNonExtendingBlock {
+			t.Fatal(err)
+		}
+	}
+	for _, b := range []types.Block{blocks[1], blocks[0]} {
+		err = cst2.cs.AcceptBlock(b)
+		if err != nil && err != modules.ErrNonExtendingBlock {
+			t.Fatal(err)
+		}
+	}
+	if cst1.cs.CurrentBlock().ID() != blocks[0].ID() || cst2.cs.CurrentBlock().ID() != blocks[1].ID() {
+		t.Fatal("consensus sets did not keep the first seen block")
+	}
+
+	// Submitting the other block again should not flip the current block.
+	err = cst1.cs.AcceptBlock(blocks[1])
+	if err != modules.ErrBlockKnown {
+		t.Fatal("expected ErrBlockKnown, got", err)
+	}
+	if cst1.cs.CurrentBlock().ID() != blocks[0].ID() {
+		t.Fatal("current block changed after resubmitting the competing block")
+	}
+
+	// Extend the first block. Both consensus sets should end up on the
+	// extended chain.
+	child, err := cst1.miner.FindBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = cst1.cs.AcceptBlock(child)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = cst2.cs.AcceptBlock(child)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cst1.cs.CurrentBlock().ID() != child.ID() || cst2.cs.CurrentBlock().ID() != child.ID() {
+		t.Fatal("consensus sets did not converge on the extended chain")
+	}
+}
+
 /// All functions below this point are deprecated. ///
 
 // TestBuriedBadFork creates a block with an invalid transaction that's not on
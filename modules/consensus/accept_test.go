@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/NebulousLabs/Sia/crypto"
 	"github.com/NebulousLabs/Sia/modules"
 	"github.com/NebulousLabs/Sia/types"
 )
@@ -474,6 +475,7 @@ func TestUnitValidateHeader(t *testing.T) {
 
 		cs := ConsensusSet{
 			dosBlocks: tt.dosBlocks,
+			clock:     types.StdClock{},
 			marshaler: tt.marshaler,
 			blockRuleHelper: mockBlockRuleHelper{
 				minTimestamp: tt.earliestValidTimestamp,
@@ -679,6 +681,172 @@ func TestMinerPayoutHandling(t *testing.T) {
 	}
 }
 
+// TestMinerPayoutWithFees checks that the miner payouts of a block must
+// include the miner fees of the transactions in the block.
+func TestMinerPayoutWithFees(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	cst, err := createConsensusSetTester("TestMinerPayoutWithFees")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cst.Close()
+
+	// Put a fee-bearing transaction in the transaction pool.
+	_, err = cst.wallet.SendSiacoins(types.NewCurrency64(1e3), types.UnlockHash{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, target, err := cst.miner.BlockForWork()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fees types.Currency
+	for _, txn := range block.Transactions {
+		fees = fees.Add(txn.TotalMinerFees())
+	}
+	if fees.IsZero() {
+		t.Fatal("block does not contain any miner fees")
+	}
+	height := cst.cs.Height() + 1
+	if block.MinerPayouts[0].Value.Cmp(types.CalculateCoinbase(height).Add(fees)) != 0 {
+		t.Fatal("miner payout does not include the miner fees")
+	}
+
+	// A block that pays out only the coinbase should be rejected.
+	badBlock := block
+	badBlock.MinerPayouts = []types.SiacoinOutput{{Value: types.CalculateCoinbase(height), UnlockHash: block.MinerPayouts[0].UnlockHash}}
+	solvedBlock, _ := cst.miner.SolveBlock(badBlock, target)
+	err = cst.cs.AcceptBlock(solvedBlock)
+	if err != errBadMinerPayouts {
+		t.Fatalf("expected %v, got %v", errBadMinerPayouts, err)
+	}
+
+	// The block with the correct payout should be accepted.
+	solvedBlock, _ = cst.miner.SolveBlock(block, target)
+	err = cst.cs.AcceptBlock(solvedBlock)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestDuplicateTransactionHandling checks that blocks containing the same
+// transaction twice, or two transactions spending the same output, are
+// rejected before any of their transactions are applied.
+func TestDuplicateTransactionHandling(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	cst, err := createConsensusSetTester("TestDuplicateTransactionHandling")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cst.Close()
+
+	// checkRejected submits a block with the given transactions and checks
+	// that it is rejected with the expected error, leaving the consensus set
+	// untouched.
+	checkRejected := func(txns []types.Transaction, expected error) {
+		height := cst.cs.dbBlockHeight()
+		checksum := cst.cs.dbConsensusChecksum()
+		block, err := cst.miner.FindBlockWithTransactions(txns)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = cst.cs.AcceptBlock(block)
+		if err != expected {
+			t.Fatalf("expected %v, got %v", expected, err)
+		}
+		if cst.cs.dbBlockHeight() != height || cst.cs.dbConsensusChecksum() != checksum {
+			t.Fatal("rejected block changed the consensus set")
+		}
+		_, err = cst.cs.dbGetBlockMap(block.ID())
+		if err != errNilItem {
+			t.Fatal("rejected block was added to the block map")
+		}
+	}
+
+	// Submit a block that repeats a transaction. Repeated transactions are
+	// only rejected starting at types.DuplicateTransactionHardforkHeight.
+	for cst.cs.dbBlockHeight()+1 < types.DuplicateTransactionHardforkHeight {
+		_, err = cst.miner.AddBlock()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	txn := types.Transaction{
+		ArbitraryData: [][]byte{append(modules.PrefixNonSia[:], []byte("duplicate")...)},
+	}
+	checkRejected([]types.Transaction{txn, txn}, modules.ErrDuplicateTransaction)
+
+	// Create a siacoin output with spendable unlock conditions.
+	sk, pk, err := crypto.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	uc := types.UnlockConditions{
+		PublicKeys: []types.SiaPublicKey{{
+			Algorithm: types.SignatureEd25519,
+			Key:       pk[:],
+		}},
+		SignaturesRequired: 1,
+	}
+	value := types.NewCurrency64(1e6)
+	txnBuilder := cst.wallet.StartTransaction()
+	err = txnBuilder.FundSiacoins(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	outputIndex := txnBuilder.AddSiacoinOutput(types.SiacoinOutput{Value: value, UnlockHash: uc.UnlockHash()})
+	txnSet, err := txnBuilder.Sign(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = cst.tpool.AcceptTransactionSet(txnSet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = cst.miner.AddBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	scoid := txnSet[len(txnSet)-1].SiacoinOutputID(outputIndex)
+
+	// Submit a block with two different transactions that spend the output.
+	spend := func(dest types.UnlockHash) types.Transaction {
+		txn := types.Transaction{
+			SiacoinInputs:  []types.SiacoinInput{{ParentID: scoid, UnlockConditions: uc}},
+			SiacoinOutputs: []types.SiacoinOutput{{Value: value, UnlockHash: dest}},
+			TransactionSignatures: []types.TransactionSignature{{
+				ParentID:      crypto.Hash(scoid),
+				CoveredFields: types.CoveredFields{WholeTransaction: true},
+			}},
+		}
+		encodedSig, err := crypto.SignHash(txn.SigHash(0), sk)
+		if err != nil {
+			t.Fatal(err)
+		}
+		txn.TransactionSignatures[0].Signature = encodedSig[:]
+		return txn
+	}
+	txn1 := spend(randAddress())
+	txn2 := spend(randAddress())
+	checkRejected([]types.Transaction{txn1, txn2}, errBlockDoubleSpend)
+
+	// A block with only one of the transactions is accepted.
+	block, err := cst.miner.FindBlockWithTransactions([]types.Transaction{txn1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = cst.cs.AcceptBlock(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
 // TestEarlyTimestampHandling checks that blocks too far in the past are
 // rejected.
 func TestEarlyTimestampHandling(t *testing.T) {
@@ -736,8 +904,8 @@ func TestFutureTimestampHandling(t *testing.T) {
 	}
 
 	// Poll the consensus set until the future block appears.
-	for i := 0; i < 30; i++ {
-		time.Sleep(time.Second * 3)
+	for i := 0; i < 900; i++ {
+		time.Sleep(time.Millisecond * 100)
 		_, err = cst.cs.dbGetBlockMap(solvedBlock.ID())
 		if err == nil {
 			break
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/modules"
 	"github.com/NebulousLabs/Sia/types"
 )
 
@@ -299,6 +300,121 @@ func TestIntegrationValidStorageProofBlocks(t *testing.T) {
 	cst.testValidStorageProofBlocks()
 }
 
+// TestIntegrationRepeatedStorageProof submits a second storage proof for a
+// file contract that has already been resolved by a storage proof, and checks
+// that it is rejected.
+func TestIntegrationRepeatedStorageProof(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	cst, err := createConsensusSetTester("TestIntegrationRepeatedStorageProof")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cst.Close()
+
+	// COMPATv0.4.0 - Step the block height up past the hardfork amount.
+	for cst.cs.dbBlockHeight() <= 10 {
+		_, err := cst.miner.AddBlock()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Create a file contract with a proof window that stays open for a few
+	// blocks.
+	filesize := uint64(4e3)
+	file, err := crypto.RandBytes(int(filesize))
+	if err != nil {
+		t.Fatal(err)
+	}
+	payout := types.NewCurrency64(400e6)
+	fc := types.FileContract{
+		FileSize:       filesize,
+		FileMerkleRoot: crypto.MerkleRoot(file),
+		WindowStart:    cst.cs.dbBlockHeight() + 1,
+		WindowEnd:      cst.cs.dbBlockHeight() + 5,
+		Payout:         payout,
+		ValidProofOutputs: []types.SiacoinOutput{{
+			UnlockHash: randAddress(),
+			Value:      types.PostTax(cst.cs.dbBlockHeight(), payout),
+		}},
+		MissedProofOutputs: []types.SiacoinOutput{{
+			UnlockHash: types.UnlockHash{},
+			Value:      types.PostTax(cst.cs.dbBlockHeight(), payout),
+		}},
+	}
+	txnBuilder := cst.wallet.StartTransaction()
+	err = txnBuilder.FundSiacoins(payout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fcIndex := txnBuilder.AddFileContract(fc)
+	txnSet, err := txnBuilder.Sign(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = cst.tpool.AcceptTransactionSet(txnSet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = cst.miner.AddBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fcid := txnSet[len(txnSet)-1].FileContractID(fcIndex)
+
+	// Submit a storage proof for the contract.
+	segmentIndex, err := cst.cs.StorageProofSegment(fcid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	segment, hashSet := crypto.MerkleProof(file, segmentIndex)
+	sp := types.StorageProof{
+		ParentID: fcid,
+		HashSet:  hashSet,
+	}
+	copy(sp.Segment[:], segment)
+	proofTxn := types.Transaction{
+		StorageProofs: []types.StorageProof{sp},
+	}
+	block, err := cst.miner.FindBlockWithTransactions([]types.Transaction{proofTxn})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = cst.cs.AcceptBlock(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = cst.cs.dbGetFileContract(fcid)
+	if err != errNilItem {
+		t.Fatal("file contract was not removed after the storage proof")
+	}
+	if cst.cs.dbBlockHeight() >= fc.WindowEnd {
+		t.Fatal("proof window closed before the second proof could be submitted")
+	}
+
+	// Submit the proof again in a different transaction, both on its own and
+	// in a block.
+	repeatTxn := types.Transaction{
+		StorageProofs: []types.StorageProof{sp},
+		ArbitraryData: [][]byte{append(modules.PrefixNonSia[:], []byte("repeat")...)},
+	}
+	_, err = cst.cs.TryTransactionSet([]types.Transaction{repeatTxn})
+	if err != errUnrecognizedFileContractID {
+		t.Fatal("expected errUnrecognizedFileContractID, got", err)
+	}
+	block, err = cst.miner.FindBlockWithTransactions([]types.Transaction{repeatTxn})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = cst.cs.AcceptBlock(block)
+	if err != errUnrecognizedFileContractID {
+		t.Fatal("expected errUnrecognizedFileContractID, got", err)
+	}
+}
+
 // testMissedStorageProofBlocks adds a block with a file contract, and then
 // fails to submit a storage proof before expiration.
 func (cst *consensusSetTester) testMissedStorageProofBlocks() {
@@ -566,6 +682,128 @@ func TestIntegrationFileContractRevision(t *testing.T) {
 	cst.testFileContractRevision()
 }
 
+// TestIntegrationRevisionAuthorization checks that file contract revisions
+// must be signed by the keys of the contract and must increase the revision
+// number of the contract.
+func TestIntegrationRevisionAuthorization(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	cst, err := createConsensusSetTester("TestIntegrationRevisionAuthorization")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cst.Close()
+
+	// Create a file contract that can be revised by a single key.
+	sk, pk, err := crypto.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	uc := types.UnlockConditions{
+		PublicKeys: []types.SiaPublicKey{{
+			Algorithm: types.SignatureEd25519,
+			Key:       pk[:],
+		}},
+		SignaturesRequired: 1,
+	}
+	payout := types.NewCurrency64(400e6)
+	fc := types.FileContract{
+		WindowStart: cst.cs.dbBlockHeight() + 10,
+		WindowEnd:   cst.cs.dbBlockHeight() + 20,
+		Payout:      payout,
+		ValidProofOutputs: []types.SiacoinOutput{{
+			Value: types.PostTax(cst.cs.dbBlockHeight(), payout),
+		}},
+		MissedProofOutputs: []types.SiacoinOutput{{
+			Value: types.PostTax(cst.cs.dbBlockHeight(), payout),
+		}},
+		UnlockHash: uc.UnlockHash(),
+	}
+	txnBuilder := cst.wallet.StartTransaction()
+	err = txnBuilder.FundSiacoins(payout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fcIndex := txnBuilder.AddFileContract(fc)
+	txnSet, err := txnBuilder.Sign(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = cst.tpool.AcceptTransactionSet(txnSet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = cst.miner.AddBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fcid := txnSet[len(txnSet)-1].FileContractID(fcIndex)
+
+	// revision creates a revision of the contract with the provided revision
+	// number, optionally signed by the contract key.
+	revision := func(revisionNumber uint64, signed bool) types.Transaction {
+		txn := types.Transaction{
+			FileContractRevisions: []types.FileContractRevision{{
+				ParentID:              fcid,
+				UnlockConditions:      uc,
+				NewRevisionNumber:     revisionNumber,
+				NewWindowStart:        fc.WindowStart,
+				NewWindowEnd:          fc.WindowEnd,
+				NewValidProofOutputs:  fc.ValidProofOutputs,
+				NewMissedProofOutputs: fc.MissedProofOutputs,
+				NewUnlockHash:         fc.UnlockHash,
+			}},
+		}
+		if !signed {
+			return txn
+		}
+		txn.TransactionSignatures = []types.TransactionSignature{{
+			ParentID:      crypto.Hash(fcid),
+			CoveredFields: types.CoveredFields{WholeTransaction: true},
+		}}
+		sig, err := crypto.SignHash(txn.SigHash(0), sk)
+		if err != nil {
+			t.Fatal(err)
+		}
+		txn.TransactionSignatures[0].Signature = sig[:]
+		return txn
+	}
+
+	// An unsigned revision should be rejected.
+	_, err = cst.cs.TryTransactionSet([]types.Transaction{revision(5, false)})
+	if err != types.ErrMissingRevisionSignature {
+		t.Fatalf("expected %v, got %v", types.ErrMissingRevisionSignature, err)
+	}
+
+	// A signed revision with a higher revision number should be accepted.
+	err = cst.tpool.AcceptTransactionSet([]types.Transaction{revision(5, true)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = cst.miner.AddBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	revised, err := cst.cs.dbGetFileContract(fcid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if revised.RevisionNumber != 5 {
+		t.Fatal("revision was not applied, revision number is", revised.RevisionNumber)
+	}
+
+	// Signed revisions that do not increase the revision number should be
+	// rejected.
+	for _, revisionNumber := range []uint64{4, 5} {
+		_, err = cst.cs.TryTransactionSet([]types.Transaction{revision(revisionNumber, true)})
+		if err != errLowRevisionNumber {
+			t.Fatalf("expected %v, got %v", errLowRevisionNumber, err)
+		}
+	}
+}
+
 // testSpendSiafunds spends siafunds on the blockchain.
 func (cst *consensusSetTester) testSpendSiafunds() {
 	// Create a random destination address for the output in the transaction.
@@ -645,6 +883,205 @@ func (cst *consensusSetTester) testSpendSiafunds() {
 	}
 }
 
+// TestIntegrationSpendMultipleSiafunds spends two siafund outputs with
+// different claim starts in a single transaction and checks that each input
+// produces a claim output with its own value.
+func TestIntegrationSpendMultipleSiafunds(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	cst, err := createConsensusSetTester("TestIntegrationSpendMultipleSiafunds")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cst.Close()
+
+	// Create a spendable unlock hash for the siafund outputs.
+	sk, pk, err := crypto.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	uc := types.UnlockConditions{
+		PublicKeys: []types.SiaPublicKey{{
+			Algorithm: types.SignatureEd25519,
+			Key:       pk[:],
+		}},
+		SignaturesRequired: 1,
+	}
+
+	// sendSiafunds sends siafunds from the wallet to the unlock hash and
+	// returns the id of the new siafund output.
+	sendSiafunds := func(value types.Currency) types.SiafundOutputID {
+		txnBuilder := cst.wallet.StartTransaction()
+		err := txnBuilder.FundSiafunds(value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		outputIndex := txnBuilder.AddSiafundOutput(types.SiafundOutput{Value: value, UnlockHash: uc.UnlockHash()})
+		txnSet, err := txnBuilder.Sign(true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = cst.tpool.AcceptTransactionSet(txnSet)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = cst.miner.AddBlock()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return txnSet[len(txnSet)-1].SiafundOutputID(outputIndex)
+	}
+	// growPool adds to the siafund pool by creating a file contract.
+	growPool := func() {
+		payout := types.NewCurrency64(400e6)
+		fc := types.FileContract{
+			WindowStart: cst.cs.dbBlockHeight() + 5,
+			WindowEnd:   cst.cs.dbBlockHeight() + 10,
+			Payout:      payout,
+			ValidProofOutputs: []types.SiacoinOutput{{
+				Value: types.PostTax(cst.cs.dbBlockHeight(), payout),
+			}},
+			MissedProofOutputs: []types.SiacoinOutput{{
+				Value: types.PostTax(cst.cs.dbBlockHeight(), payout),
+			}},
+		}
+		txnBuilder := cst.wallet.StartTransaction()
+		err := txnBuilder.FundSiacoins(payout)
+		if err != nil {
+			t.Fatal(err)
+		}
+		txnBuilder.AddFileContract(fc)
+		txnSet, err := txnBuilder.Sign(true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = cst.tpool.AcceptTransactionSet(txnSet)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = cst.miner.AddBlock()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Create two siafund outputs with different claim starts.
+	value := types.NewCurrency64(3)
+	growPool()
+	id1 := sendSiafunds(value)
+	growPool()
+	id2 := sendSiafunds(value)
+	growPool()
+	sfo1, err := cst.cs.dbGetSiafundOutput(id1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sfo2, err := cst.cs.dbGetSiafundOutput(id2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sfo1.ClaimStart.Cmp(sfo2.ClaimStart) == 0 {
+		t.Fatal("siafund outputs have the same claim start")
+	}
+	pool := cst.cs.dbGetSiafundPool()
+	expected1 := pool.Sub(sfo1.ClaimStart).Div(types.SiafundCount).Mul(value)
+	expected2 := pool.Sub(sfo2.ClaimStart).Div(types.SiafundCount).Mul(value)
+	if expected1.Cmp(expected2) == 0 {
+		t.Fatal("claims are not distinct")
+	}
+
+	// Spend both outputs in one transaction.
+	txn := types.Transaction{
+		SiafundInputs: []types.SiafundInput{
+			{ParentID: id1, UnlockConditions: uc, ClaimUnlockHash: randAddress()},
+			{ParentID: id2, UnlockConditions: uc, ClaimUnlockHash: randAddress()},
+		},
+		SiafundOutputs: []types.SiafundOutput{{Value: value.Mul64(2), UnlockHash: uc.UnlockHash()}},
+		TransactionSignatures: []types.TransactionSignature{
+			{ParentID: crypto.Hash(id1), CoveredFields: types.CoveredFields{WholeTransaction: true}},
+			{ParentID: crypto.Hash(id2), CoveredFields: types.CoveredFields{WholeTransaction: true}},
+		},
+	}
+	for i := range txn.TransactionSignatures {
+		encodedSig, err := crypto.SignHash(txn.SigHash(i), sk)
+		if err != nil {
+			t.Fatal(err)
+		}
+		txn.TransactionSignatures[i].Signature = encodedSig[:]
+	}
+	err = cst.tpool.AcceptTransactionSet([]types.Transaction{txn})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = cst.miner.AddBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Check that each input created its own claim output.
+	for i, expected := range []types.Currency{expected1, expected2} {
+		sfi := txn.SiafundInputs[i]
+		dsco, err := cst.cs.dbGetDSCO(cst.cs.dbBlockHeight()+types.MaturityDelay, sfi.ParentID.SiaClaimOutputID())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if dsco.Value.Cmp(expected) != 0 {
+			t.Errorf("claim %v: expected %v, got %v", i, expected, dsco.Value)
+		}
+		if dsco.UnlockHash != sfi.ClaimUnlockHash {
+			t.Errorf("claim %v sent to the wrong address", i)
+		}
+	}
+}
+
+// TestIntegrationSiafundInflation submits a transaction that creates more
+// siafunds than it spends, and checks that it is rejected both on its own and
+// as part of a block.
+func TestIntegrationSiafundInflation(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	cst, err := createConsensusSetTester("TestIntegrationSiafundInflation")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cst.Close()
+
+	// Spend 3 siafunds, but create outputs worth 4 siafunds.
+	txnBuilder := cst.wallet.StartTransaction()
+	err = txnBuilder.FundSiafunds(types.NewCurrency64(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	destAddr := randAddress()
+	outputIndex := txnBuilder.AddSiafundOutput(types.SiafundOutput{Value: types.NewCurrency64(3), UnlockHash: destAddr})
+	txnBuilder.AddSiafundOutput(types.SiafundOutput{Value: types.NewCurrency64(1), UnlockHash: destAddr})
+	txnSet, err := txnBuilder.Sign(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = cst.cs.TryTransactionSet(txnSet)
+	if err != errSiafundInputOutputMismatch {
+		t.Fatal("expected errSiafundInputOutputMismatch, got", err)
+	}
+	block, err := cst.miner.FindBlockWithTransactions(txnSet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = cst.cs.AcceptBlock(block)
+	if err != errSiafundInputOutputMismatch {
+		t.Fatal("expected errSiafundInputOutputMismatch, got", err)
+	}
+	_, err = cst.cs.dbGetSiafundOutput(txnSet[len(txnSet)-1].SiafundOutputID(outputIndex))
+	if err != errNilItem {
+		t.Error("siafund output from the rejected transaction was created:", err)
+	}
+}
+
 // TestIntegrationSpendSiafunds creates a consensus set tester and uses it
 // to call testSpendSiafunds.
 func (cst *consensusSetTester) TestIntegrationSpendSiafunds(t *testing.T) {
@@ -1212,3 +1649,161 @@ func TestPaymentChannelBlocks(t *testing.T) {
 	}
 }
 */
+
+// TestIntegrationTimelockedOutput checks that an output with a timelock in its
+// unlock conditions cannot be spent in a block before the timelock height, and
+// can be spent at or after the timelock height.
+func TestIntegrationTimelockedOutput(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	cst, err := createConsensusSetTester("TestIntegrationTimelockedOutput")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cst.Close()
+
+	// Send coins to an address with a timelock a few blocks in the future.
+	sk, pk, err := crypto.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	uc := types.UnlockConditions{
+		Timelock: cst.cs.dbBlockHeight() + 4,
+		PublicKeys: []types.SiaPublicKey{{
+			Algorithm: types.SignatureEd25519,
+			Key:       pk[:],
+		}},
+		SignaturesRequired: 1,
+	}
+	value := types.NewCurrency64(100e3)
+	txnBuilder := cst.wallet.StartTransaction()
+	err = txnBuilder.FundSiacoins(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	scoIndex := txnBuilder.AddSiacoinOutput(types.SiacoinOutput{Value: value, UnlockHash: uc.UnlockHash()})
+	txnSet, err := txnBuilder.Sign(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = cst.tpool.AcceptTransactionSet(txnSet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = cst.miner.AddBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Create a transaction spending the timelocked output.
+	parentID := txnSet[len(txnSet)-1].SiacoinOutputID(scoIndex)
+	spendTxn := types.Transaction{
+		SiacoinInputs: []types.SiacoinInput{{
+			ParentID:         parentID,
+			UnlockConditions: uc,
+		}},
+		SiacoinOutputs: []types.SiacoinOutput{{
+			Value: value,
+		}},
+		TransactionSignatures: []types.TransactionSignature{{
+			ParentID:       crypto.Hash(parentID),
+			PublicKeyIndex: 0,
+			CoveredFields:  types.CoveredFields{WholeTransaction: true},
+		}},
+	}
+	sig, err := crypto.SignHash(spendTxn.SigHash(0), sk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	spendTxn.TransactionSignatures[0].Signature = sig[:]
+
+	// mineSpend tries to put the spending transaction into the next block.
+	mineSpend := func() error {
+		b, target, err := cst.miner.BlockForWork()
+		if err != nil {
+			return err
+		}
+		b.Transactions = append(b.Transactions, spendTxn)
+		solved := false
+		for !solved {
+			b, solved = cst.miner.SolveBlock(b, target)
+		}
+		return cst.cs.AcceptBlock(b)
+	}
+
+	// Spending the output before the current height reaches the timelock
+	// should be rejected.
+	for cst.cs.dbBlockHeight() < uc.Timelock {
+		err = mineSpend()
+		if err != types.ErrTimelockNotSatisfied {
+			t.Fatal("expecting ErrTimelockNotSatisfied, got", err)
+		}
+		_, err = cst.miner.AddBlock()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Once the current height has reached the timelock, the spend should be
+	// accepted.
+	err = mineSpend()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = cst.cs.dbGetSiacoinOutput(parentID)
+	if err == nil {
+		t.Error("timelocked output was not spent")
+	}
+}
+
+// TestIntegrationZeroPayoutFileContract submits a file contract with a zero
+// payout and checks that it is rejected without touching the siafund pool.
+func TestIntegrationZeroPayoutFileContract(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	cst, err := createConsensusSetTester("TestIntegrationZeroPayoutFileContract")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cst.Close()
+
+	// A zero payout needs no funding and has trivially correct output sums.
+	txn := types.Transaction{
+		FileContracts: []types.FileContract{{
+			WindowStart: cst.cs.dbBlockHeight() + 2,
+			WindowEnd:   cst.cs.dbBlockHeight() + 3,
+			Payout:      types.ZeroCurrency,
+		}},
+	}
+	fcid := txn.FileContractID(0)
+	height := cst.cs.dbBlockHeight()
+	siafundPool := cst.cs.dbGetSiafundPool()
+
+	_, err = cst.cs.TryTransactionSet([]types.Transaction{txn})
+	if err != types.ErrZeroFileContractPayout {
+		t.Fatal("expected ErrZeroFileContractPayout, got", err)
+	}
+	block, err := cst.miner.FindBlockWithTransactions([]types.Transaction{txn})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = cst.cs.AcceptBlock(block)
+	if err != types.ErrZeroFileContractPayout {
+		t.Fatal("expected ErrZeroFileContractPayout, got", err)
+	}
+
+	// The rejected block should leave consensus untouched.
+	if cst.cs.dbBlockHeight() != height {
+		t.Error("block with a zero payout file contract changed the height")
+	}
+	if cst.cs.dbGetSiafundPool().Cmp(siafundPool) != 0 {
+		t.Error("siafund pool changed after rejecting a zero payout file contract")
+	}
+	_, err = cst.cs.dbGetFileContract(fcid)
+	if err != errNilItem {
+		t.Error("zero payout file contract was added to the consensus set")
+	}
+}
@@ -147,6 +147,13 @@ func applyStorageProofs(tx *bolt.Tx, pb *processedBlock, t types.Transaction) {
 	}
 }
 
+// siafundClaim returns the number of siacoins that the holder of a siafund
+// output is entitled to when spending the output, which is the output's share
+// of the growth of the siafund pool since the output was created.
+func siafundClaim(tx *bolt.Tx, sfo types.SiafundOutput) types.Currency {
+	return getSiafundPool(tx).Sub(sfo.ClaimStart).Div(types.SiafundCount).Mul(sfo.Value)
+}
+
 // applyTxSiafundInputs takes all of the siafund inputs in a transaction and
 // applies them to the state, updating the diffs in the processed block.
 func applySiafundInputs(tx *bolt.Tx, pb *processedBlock, t types.Transaction) {
@@ -156,7 +163,7 @@ func applySiafundInputs(tx *bolt.Tx, pb *processedBlock, t types.Transaction) {
 		if build.DEBUG && err != nil {
 			panic(err)
 		}
-		claimPortion := getSiafundPool(tx).Sub(sfo.ClaimStart).Div(types.SiafundCount).Mul(sfo.Value)
+		claimPortion := siafundClaim(tx, sfo)
 
 		// Add the claim output to the delayed set of outputs.
 		sco := types.SiacoinOutput{
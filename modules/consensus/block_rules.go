@@ -20,6 +20,12 @@ type stdBlockRuleHelper struct{}
 // can have while still being valid. See section 'Block Timestamps' in
 // Consensus.md.
 //
+// The median is taken over the timestamps of the previous
+// MedianTimestampWindow blocks, which are the parent and its
+// MedianTimestampWindow-1 closest ancestors. If the parent has fewer
+// ancestors, the timestamp of the genesis block is used in place of the
+// missing blocks.
+//
 // To boost performance, minimumValidChildTimestamp is passed a bucket that it
 // can use from inside of a boltdb transaction.
 func (rh stdBlockRuleHelper) minimumValidChildTimestamp(blockMap dbBucket, pb *processedBlock) types.Timestamp {
@@ -46,6 +52,16 @@ func (rh stdBlockRuleHelper) minimumValidChildTimestamp(blockMap dbBucket, pb *p
 	}
 	sort.Sort(windowTimes)
 
-	// Return the median of the sorted timestamps.
-	return windowTimes[len(windowTimes)/2]
+	median := windowTimes[len(windowTimes)/2]
+
+	// HARDFORK 135,000
+	//
+	// Originally, a child's timestamp only needed to be at least the median
+	// timestamp, allowing miners to stall the median by reusing it. Starting
+	// at block 135,000 the child's timestamp must be strictly greater than the
+	// median.
+	if pb.Height+1 >= types.StrictTimestampHardforkHeight {
+		return median + 1
+	}
+	return median
 }
@@ -0,0 +1,108 @@
+package consensus
+
+import (
+	"testing"
+
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestUnitStrictTimestamp checks that, starting at the strict timestamp
+// hardfork, a block with a timestamp equal to the median timestamp of its
+// ancestors is rejected.
+func TestUnitStrictTimestamp(t *testing.T) {
+	var rh stdBlockRuleHelper
+	bv := NewBlockValidator()
+	var target types.Target
+	for i := range target {
+		target[i] = 255
+	}
+
+	// A parent without ancestors has a median timestamp equal to its own
+	// timestamp, so the block map is never accessed.
+	median := types.Timestamp(1e6)
+	pb := &processedBlock{
+		Block:  types.Block{Timestamp: median},
+		Height: types.StrictTimestampHardforkHeight - 2,
+	}
+	b := types.Block{ParentID: pb.Block.ID(), Timestamp: median}
+
+	// Before the hardfork, a timestamp equal to the median is allowed.
+	minTimestamp := rh.minimumValidChildTimestamp(nil, pb)
+	if minTimestamp != median {
+		t.Fatal("expected the minimum timestamp to equal the median:", minTimestamp, median)
+	}
+	err := bv.ValidateBlock(b, minTimestamp, target, pb.Height+1)
+	if err == errEarlyTimestamp {
+		t.Fatal("block with a timestamp equal to the median was rejected before the hardfork")
+	}
+
+	// Starting at the hardfork, a timestamp equal to the median is rejected.
+	pb.Height++
+	minTimestamp = rh.minimumValidChildTimestamp(nil, pb)
+	if minTimestamp != median+1 {
+		t.Fatal("expected the minimum timestamp to follow the median:", minTimestamp, median)
+	}
+	err = bv.ValidateBlock(b, minTimestamp, target, pb.Height+1)
+	if err != errEarlyTimestamp {
+		t.Fatal("expected errEarlyTimestamp, got", err)
+	}
+	b.Timestamp = median + 1
+	err = bv.ValidateBlock(b, minTimestamp, target, pb.Height+1)
+	if err == errEarlyTimestamp {
+		t.Fatal("block with a timestamp after the median was rejected")
+	}
+}
+
+// TestIntegrationStrictTimestamp builds a chain of blocks that all share the
+// same timestamp and checks that such a block is no longer accepted once the
+// strict timestamp hardfork activates.
+func TestIntegrationStrictTimestamp(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	cst, err := blankConsensusSetTester("TestIntegrationStrictTimestamp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cst.Close()
+
+	// mineBlock creates and submits a child of the current block with the
+	// given timestamp.
+	mineBlock := func(timestamp types.Timestamp) (types.Block, error) {
+		b, target, err := cst.miner.BlockForWork()
+		if err != nil {
+			t.Fatal(err)
+		}
+		b.Timestamp = timestamp
+		solved := false
+		for !solved {
+			b, solved = cst.miner.SolveBlock(b, target)
+		}
+		return b, cst.cs.AcceptBlock(b)
+	}
+
+	// Before the hardfork, a block may reuse the median timestamp. The
+	// timestamp is in the past, so that mining quickly does not push the
+	// timestamps into the future.
+	timestamp := types.GenesisTimestamp + 1
+	for cst.cs.Height()+1 < types.StrictTimestampHardforkHeight {
+		_, err = mineBlock(timestamp)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Starting at the hardfork, the timestamp must follow the median.
+	_, err = mineBlock(timestamp)
+	if err != errEarlyTimestamp {
+		t.Fatal("expected errEarlyTimestamp, got", err)
+	}
+	b, err := mineBlock(timestamp + 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cst.cs.CurrentBlock().ID() != b.ID() {
+		t.Fatal("block following the median timestamp did not become the current block")
+	}
+}
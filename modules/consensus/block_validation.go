@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"errors"
 
+	"github.com/NebulousLabs/Sia/crypto"
 	"github.com/NebulousLabs/Sia/encoding"
 	"github.com/NebulousLabs/Sia/modules"
 	"github.com/NebulousLabs/Sia/types"
@@ -11,6 +12,7 @@ import (
 
 var (
 	errBadMinerPayouts        = errors.New("miner payout sum does not equal block subsidy")
+	errBlockDoubleSpend       = errors.New("block contains two transactions that spend the same output")
 	errEarlyTimestamp         = errors.New("block timestamp is too early")
 	errExtremeFutureTimestamp = errors.New("block timestamp too far in future, discarded")
 	errFutureTimestamp        = errors.New("block timestamp too far in future, but saved for later use")
@@ -31,6 +33,66 @@ type stdBlockValidator struct {
 
 	// marshaler encodes and decodes between objects and byte slices.
 	marshaler encoding.GenericMarshaler
+
+	// sizeLimit is the maximum size of a block in bytes. If sizeLimit is
+	// zero, types.BlockSizeLimit is used.
+	sizeLimit uint64
+
+	// futureThreshold and extremeFutureThreshold are the limits on how far in
+	// the future a block timestamp may be. If either is zero, the value from
+	// the types package is used.
+	futureThreshold        types.Timestamp
+	extremeFutureThreshold types.Timestamp
+
+	// coinbaseSchedule returns the coinbase of a block at a given height. If
+	// coinbaseSchedule is nil, types.CalculateCoinbase is used.
+	coinbaseSchedule func(types.BlockHeight) types.Currency
+}
+
+// blockSizeLimit returns the maximum size of a block in bytes.
+func (bv stdBlockValidator) blockSizeLimit() uint64 {
+	if bv.sizeLimit == 0 {
+		return types.BlockSizeLimit
+	}
+	return bv.sizeLimit
+}
+
+// coinbase returns the coinbase of a block at the given height.
+func (bv stdBlockValidator) coinbase(height types.BlockHeight) types.Currency {
+	if bv.coinbaseSchedule == nil {
+		return types.CalculateCoinbase(height)
+	}
+	return bv.coinbaseSchedule(height)
+}
+
+// thresholds returns the future and extreme future thresholds of the
+// validator.
+func (bv stdBlockValidator) thresholds() (future, extreme types.Timestamp) {
+	return futureThresholds(bv.futureThreshold, bv.extremeFutureThreshold)
+}
+
+// futureThresholds replaces zero thresholds with the values from the types
+// package.
+func futureThresholds(future, extreme types.Timestamp) (types.Timestamp, types.Timestamp) {
+	if future == 0 {
+		future = types.FutureThreshold
+	}
+	if extreme == 0 {
+		extreme = types.ExtremeFutureThreshold
+	}
+	return future, extreme
+}
+
+// networkClock is a Clock that reports the network-adjusted time of a
+// gateway, so that a skewed local clock does not cause valid blocks to be
+// rejected as future blocks.
+type networkClock struct {
+	gateway modules.Gateway
+}
+
+// Now returns the network-adjusted time of the gateway.
+func (nc networkClock) Now() types.Timestamp {
+	return nc.gateway.NetworkTime()
 }
 
 // NewBlockValidator creates a new stdBlockValidator with default settings.
@@ -41,9 +103,10 @@ func NewBlockValidator() stdBlockValidator {
 	}
 }
 
-// checkMinerPayouts compares a block's miner payouts to the block's subsidy and
-// returns true if they are equal.
-func checkMinerPayouts(b types.Block, height types.BlockHeight) bool {
+// checkMinerPayouts compares a block's miner payouts to the block's subsidy,
+// which is the coinbase plus the miner fees, and returns true if they are
+// equal.
+func checkMinerPayouts(b types.Block, coinbase types.Currency) bool {
 	// Add up the payouts and check that all values are legal.
 	var payoutSum types.Currency
 	for _, payout := range b.MinerPayouts {
@@ -52,7 +115,51 @@ func checkMinerPayouts(b types.Block, height types.BlockHeight) bool {
 		}
 		payoutSum = payoutSum.Add(payout.Value)
 	}
-	return b.CalculateSubsidy(height).Cmp(payoutSum) == 0
+	return coinbase.Add(b.CalculateMinerFees()).Cmp(payoutSum) == 0
+}
+
+// checkDuplicateTransactions checks that no transaction appears in the block
+// more than once, and that no two transactions in the block spend the same
+// siacoin or siafund output. A double spend would cause the block to fail when
+// its transactions are applied, but checking up front rejects the block before
+// any diffs are generated.
+func checkDuplicateTransactions(b types.Block, height types.BlockHeight) error {
+	txids := make(map[types.TransactionID]struct{}, len(b.Transactions))
+	spent := make(map[crypto.Hash]struct{})
+	for _, txn := range b.Transactions {
+		// HARDFORK 140,000
+		//
+		// Originally, a transaction that spends no outputs could appear in a
+		// block more than once. Starting at block 140,000 every transaction
+		// in a block must be unique.
+		if height >= types.DuplicateTransactionHardforkHeight {
+			txid := txn.ID()
+			if _, exists := txids[txid]; exists {
+				return modules.ErrDuplicateTransaction
+			}
+			txids[txid] = struct{}{}
+		}
+
+		// A transaction spending the same output twice is rejected during
+		// transaction validation, so only outputs spent by earlier
+		// transactions need to be checked.
+		var txnSpent []crypto.Hash
+		for _, sci := range txn.SiacoinInputs {
+			txnSpent = append(txnSpent, crypto.Hash(sci.ParentID))
+		}
+		for _, sfi := range txn.SiafundInputs {
+			txnSpent = append(txnSpent, crypto.Hash(sfi.ParentID))
+		}
+		for _, id := range txnSpent {
+			if _, exists := spent[id]; exists {
+				return errBlockDoubleSpend
+			}
+		}
+		for _, id := range txnSpent {
+			spent[id] = struct{}{}
+		}
+	}
+	return nil
 }
 
 // checkTarget returns true if the block's ID meets the given target.
@@ -76,7 +183,7 @@ func (bv stdBlockValidator) ValidateBlock(b types.Block, minTimestamp types.Time
 	}
 
 	// Check that the block is below the size limit.
-	if uint64(len(bv.marshaler.Marshal(b))) > types.BlockSizeLimit {
+	if uint64(len(bv.marshaler.Marshal(b))) > bv.blockSizeLimit() {
 		return errLargeBlock
 	}
 
@@ -84,19 +191,27 @@ func (bv stdBlockValidator) ValidateBlock(b types.Block, minTimestamp types.Time
 	// future and extreme future because there is an assumption that by the time
 	// the extreme future arrives, this block will no longer be a part of the
 	// longest fork because it will have been ignored by all of the miners.
-	if b.Timestamp > bv.clock.Now()+types.ExtremeFutureThreshold {
+	futureThreshold, extremeFutureThreshold := bv.thresholds()
+	if b.Timestamp > bv.clock.Now()+extremeFutureThreshold {
 		return errExtremeFutureTimestamp
 	}
 
+	// Check that no transaction is repeated, and that no output is spent by
+	// more than one transaction.
+	err := checkDuplicateTransactions(b, height)
+	if err != nil {
+		return err
+	}
+
 	// Verify that the miner payouts are valid.
-	if !checkMinerPayouts(b, height) {
+	if !checkMinerPayouts(b, bv.coinbase(height)) {
 		return errBadMinerPayouts
 	}
 
 	// Check if the block is in the near future, but too far to be acceptable.
 	// This is the last check because it's an expensive check, and not worth
 	// performing if the payouts are incorrect.
-	if b.Timestamp > bv.clock.Now()+types.FutureThreshold {
+	if b.Timestamp > bv.clock.Now()+futureThreshold {
 		return errFutureTimestamp
 	}
 	return nil
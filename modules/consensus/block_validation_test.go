@@ -3,6 +3,7 @@ package consensus
 import (
 	"testing"
 
+	"github.com/NebulousLabs/Sia/modules"
 	"github.com/NebulousLabs/Sia/types"
 )
 
@@ -96,7 +97,7 @@ func TestCheckMinerPayouts(t *testing.T) {
 			{Value: coinbase},
 		},
 	}
-	if !checkMinerPayouts(b, 0) {
+	if !checkMinerPayouts(b, coinbase) {
 		t.Error("payouts evaluated incorrectly when there is only one payout.")
 	}
 
@@ -106,7 +107,7 @@ func TestCheckMinerPayouts(t *testing.T) {
 			{Value: coinbase.Sub(types.NewCurrency64(1))},
 		},
 	}
-	if checkMinerPayouts(b, 0) {
+	if checkMinerPayouts(b, coinbase) {
 		t.Error("payouts evaluated incorrectly when there is a too-small payout")
 	}
 
@@ -117,7 +118,7 @@ func TestCheckMinerPayouts(t *testing.T) {
 			{Value: types.NewCurrency64(1)},
 		},
 	}
-	if !checkMinerPayouts(b, 0) {
+	if !checkMinerPayouts(b, coinbase) {
 		t.Error("payouts evaluated incorrectly when there are 2 payouts")
 	}
 
@@ -128,7 +129,7 @@ func TestCheckMinerPayouts(t *testing.T) {
 			{Value: coinbase},
 		},
 	}
-	if checkMinerPayouts(b, 0) {
+	if checkMinerPayouts(b, coinbase) {
 		t.Error("payouts evaluated incorrectly when there are two large payouts")
 	}
 
@@ -139,11 +140,39 @@ func TestCheckMinerPayouts(t *testing.T) {
 			{},
 		},
 	}
-	if checkMinerPayouts(b, 0) {
+	if checkMinerPayouts(b, coinbase) {
 		t.Error("payouts evaluated incorrectly when there is only one payout.")
 	}
 }
 
+// TestCheckDuplicateTransactions probes the checkDuplicateTransactions
+// function on both sides of the duplicate transaction hardfork.
+func TestCheckDuplicateTransactions(t *testing.T) {
+	txn := types.Transaction{ArbitraryData: [][]byte{{1}}}
+	b := types.Block{Transactions: []types.Transaction{txn, txn}}
+	if err := checkDuplicateTransactions(b, types.DuplicateTransactionHardforkHeight-1); err != nil {
+		t.Error("duplicate transactions rejected before the hardfork:", err)
+	}
+	if err := checkDuplicateTransactions(b, types.DuplicateTransactionHardforkHeight); err != modules.ErrDuplicateTransaction {
+		t.Error("expected modules.ErrDuplicateTransaction, got", err)
+	}
+
+	// Double spends are rejected regardless of the height.
+	spend := func(dest types.UnlockHash) types.Transaction {
+		return types.Transaction{
+			SiacoinInputs:  []types.SiacoinInput{{ParentID: types.SiacoinOutputID{1}}},
+			SiacoinOutputs: []types.SiacoinOutput{{UnlockHash: dest}},
+		}
+	}
+	b = types.Block{Transactions: []types.Transaction{spend(types.UnlockHash{1}), spend(types.UnlockHash{2})}}
+	if err := checkDuplicateTransactions(b, types.DuplicateTransactionHardforkHeight-1); err != errBlockDoubleSpend {
+		t.Error("expected errBlockDoubleSpend before the hardfork, got", err)
+	}
+	if err := checkDuplicateTransactions(b, types.DuplicateTransactionHardforkHeight); err != errBlockDoubleSpend {
+		t.Error("expected errBlockDoubleSpend after the hardfork, got", err)
+	}
+}
+
 // TestCheckTarget probes the checkTarget function.
 func TestCheckTarget(t *testing.T) {
 	var b types.Block
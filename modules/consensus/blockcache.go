@@ -0,0 +1,69 @@
+package consensus
+
+import (
+	"container/list"
+
+	"github.com/NebulousLabs/Sia/build"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+var (
+	// recentBlocksSize is the number of block ids that are remembered by the
+	// cache of recently seen blocks.
+	recentBlocksSize = func() int {
+		switch build.Release {
+		case "dev":
+			return 500
+		case "standard":
+			return 1000
+		case "testing":
+			return 10
+		default:
+			panic("unrecognized build.Release")
+		}
+	}()
+)
+
+// blockCache is a fixed-size, least-recently-used set of block ids. It lets
+// the consensus set reject blocks that it has recently seen without opening a
+// database transaction, which is useful when many peers relay the same block
+// at once.
+type blockCache struct {
+	size    int
+	order   *list.List
+	entries map[types.BlockID]*list.Element
+}
+
+// newBlockCache creates a blockCache that holds up to 'size' block ids.
+func newBlockCache(size int) *blockCache {
+	return &blockCache{
+		size:    size,
+		order:   list.New(),
+		entries: make(map[types.BlockID]*list.Element),
+	}
+}
+
+// add inserts a block id into the cache, evicting the least recently used id
+// if the cache is full.
+func (bc *blockCache) add(id types.BlockID) {
+	if e, exists := bc.entries[id]; exists {
+		bc.order.MoveToFront(e)
+		return
+	}
+	bc.entries[id] = bc.order.PushFront(id)
+	if bc.order.Len() > bc.size {
+		oldest := bc.order.Back()
+		bc.order.Remove(oldest)
+		delete(bc.entries, oldest.Value.(types.BlockID))
+	}
+}
+
+// contains returns true if the block id is in the cache, marking the id as
+// recently used.
+func (bc *blockCache) contains(id types.BlockID) bool {
+	e, exists := bc.entries[id]
+	if exists {
+		bc.order.MoveToFront(e)
+	}
+	return exists
+}
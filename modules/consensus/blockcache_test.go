@@ -0,0 +1,92 @@
+package consensus
+
+import (
+	"testing"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// countingBlockValidator is a blockValidator that counts the number of blocks
+// that it has validated.
+type countingBlockValidator struct {
+	blockValidator
+	calls int
+}
+
+// ValidateBlock counts the call and passes the block to the underlying
+// validator.
+func (cbv *countingBlockValidator) ValidateBlock(b types.Block, minTimestamp types.Timestamp, target types.Target, height types.BlockHeight) error {
+	cbv.calls++
+	return cbv.blockValidator.ValidateBlock(b, minTimestamp, target, height)
+}
+
+// TestBlockCache checks that the blockCache evicts the least recently used
+// block ids.
+func TestBlockCache(t *testing.T) {
+	bc := newBlockCache(2)
+	id1, id2, id3 := types.BlockID{1}, types.BlockID{2}, types.BlockID{3}
+	bc.add(id1)
+	bc.add(id2)
+	if !bc.contains(id1) || !bc.contains(id2) {
+		t.Fatal("cache is missing ids")
+	}
+
+	// id1 was used more recently than id2, so adding id3 should evict id2.
+	bc.contains(id1)
+	bc.add(id3)
+	if !bc.contains(id1) || !bc.contains(id3) {
+		t.Error("cache evicted a recently used id")
+	}
+	if bc.contains(id2) {
+		t.Error("cache did not evict the least recently used id")
+	}
+	if len(bc.entries) != 2 || bc.order.Len() != 2 {
+		t.Error("cache grew beyond its size")
+	}
+}
+
+// TestIntegrationRecentBlocks submits the same block many times and checks
+// that only the first submission is validated.
+func TestIntegrationRecentBlocks(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	cst, err := createConsensusSetTester("TestIntegrationRecentBlocks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cst.Close()
+
+	cbv := &countingBlockValidator{blockValidator: cst.cs.blockValidator}
+	cst.cs.mu.Lock()
+	cst.cs.blockValidator = cbv
+	cst.cs.mu.Unlock()
+
+	block, err := cst.miner.FindBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = cst.cs.AcceptBlock(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 25; i++ {
+		err = cst.cs.AcceptBlock(block)
+		if err != modules.ErrBlockKnown {
+			t.Fatal("expected ErrBlockKnown, got", err)
+		}
+	}
+
+	cst.cs.mu.Lock()
+	calls := cbv.calls
+	cached := cst.cs.recentBlocks.contains(block.ID())
+	cst.cs.mu.Unlock()
+	if calls != 1 {
+		t.Error("expected the block to be validated once, got", calls)
+	}
+	if !cached {
+		t.Error("block was not added to the cache of recent blocks")
+	}
+}
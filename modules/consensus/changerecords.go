@@ -0,0 +1,77 @@
+package consensus
+
+import (
+	"github.com/NebulousLabs/Sia/build"
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+
+	"github.com/NebulousLabs/bolt"
+)
+
+var (
+	// recentChangesSize is the number of change records that are kept by the
+	// consensus set. Each record requires computing the consensus checksum,
+	// which is expensive, so the log is disabled in standard builds.
+	recentChangesSize = func() int {
+		switch build.Release {
+		case "dev":
+			return 1000
+		case "standard":
+			return 0
+		case "testing":
+			return 100
+		default:
+			panic("unrecognized build.Release")
+		}
+	}()
+)
+
+// A ChangeRecord describes a single block that was applied to or reverted from
+// the consensus set, along with the consensus checksum that resulted from the
+// change. Comparing the records of two nodes helps to find out where and why
+// they ended up on different forks.
+type ChangeRecord struct {
+	BlockID           types.BlockID
+	Height            types.BlockHeight
+	Direction         modules.DiffDirection
+	ConsensusChecksum crypto.Hash
+}
+
+// recordChange adds a pending change record for a block that was just applied
+// or reverted. Pending records are only added to the log of recent changes
+// once the database transaction making the change has been committed.
+func (cs *ConsensusSet) recordChange(tx *bolt.Tx, pb *processedBlock, dir modules.DiffDirection) {
+	// Changes made while checking consistency are undone before the check
+	// completes, and are not recorded.
+	if recentChangesSize == 0 || cs.checkingConsistency {
+		return
+	}
+	cs.pendingChanges = append(cs.pendingChanges, ChangeRecord{
+		BlockID:           pb.Block.ID(),
+		Height:            pb.Height,
+		Direction:         dir,
+		ConsensusChecksum: consensusChecksum(tx),
+	})
+}
+
+// commitChanges moves the pending change records into the log of recent
+// changes, dropping the oldest records if the log is full.
+func (cs *ConsensusSet) commitChanges() {
+	cs.recentChanges = append(cs.recentChanges, cs.pendingChanges...)
+	if len(cs.recentChanges) > recentChangesSize {
+		cs.recentChanges = cs.recentChanges[len(cs.recentChanges)-recentChangesSize:]
+	}
+	cs.pendingChanges = nil
+}
+
+// RecentChanges returns the most recent blocks that were applied to or
+// reverted from the consensus set, oldest first. The log is empty in standard
+// builds.
+func (cs *ConsensusSet) RecentChanges() []ChangeRecord {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+	records := make([]ChangeRecord, len(cs.recentChanges))
+	copy(records, cs.recentChanges)
+	return records
+}
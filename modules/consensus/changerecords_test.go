@@ -0,0 +1,65 @@
+package consensus
+
+import (
+	"testing"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestRecentChangesReorg forks the blockchain and checks that the log of
+// recent changes shows the reverted blocks followed by the applied blocks.
+func TestRecentChangesReorg(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	rs := createReorgSets("TestRecentChangesReorg")
+	defer rs.Close()
+
+	// The main and alt consensus sets share only the genesis block, so the
+	// reorg reverts every block of cstMain.
+	mainHeight := rs.cstMain.cs.dbBlockHeight()
+	var mainPath []types.BlockID
+	for i := types.BlockHeight(1); i <= mainHeight; i++ {
+		id, err := rs.cstMain.cs.dbGetPath(i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		mainPath = append(mainPath, id)
+	}
+	rs.extend()
+	altHeight := rs.cstAlt.cs.dbBlockHeight()
+
+	records := rs.cstMain.cs.RecentChanges()
+	numChanges := int(mainHeight + altHeight)
+	if len(records) < numChanges {
+		t.Fatalf("expected at least %v records, got %v", numChanges, len(records))
+	}
+	records = records[len(records)-numChanges:]
+	for i, record := range records[:mainHeight] {
+		expectedID := mainPath[int(mainHeight)-1-i]
+		if record.Direction != modules.DiffRevert || record.BlockID != expectedID {
+			t.Fatal("incorrect revert record at index", i)
+		}
+	}
+	for i, record := range records[mainHeight:] {
+		expectedID, err := rs.cstAlt.cs.dbGetPath(types.BlockHeight(i + 1))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if record.Direction != modules.DiffApply || record.BlockID != expectedID || record.Height != types.BlockHeight(i+1) {
+			t.Fatal("incorrect apply record at index", i)
+		}
+	}
+	if records[len(records)-1].ConsensusChecksum != rs.cstMain.cs.dbConsensusChecksum() {
+		t.Error("last record does not have the current consensus checksum")
+	}
+
+	// The records of the applied blocks should match the records of the
+	// consensus set that mined them.
+	altRecords := rs.cstAlt.cs.RecentChanges()
+	if altRecords[len(altRecords)-1] != records[len(records)-1] {
+		t.Error("records of the main and alt consensus sets do not match")
+	}
+}
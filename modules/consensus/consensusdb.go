@@ -18,6 +18,7 @@ import (
 var (
 	prefixDSCO = []byte("dsco_")
 	prefixFCEX = []byte("fcex_")
+	prefixFCWS = []byte("fcws_")
 
 	// BlockHeight is a bucket that stores the current block height.
 	//
@@ -60,6 +61,11 @@ var (
 	// SiafundPool is a database bucket storing the current value of the
 	// siafund pool.
 	SiafundPool = []byte("SiafundPool")
+
+	// FileContractEvents is a database bucket that contains the file
+	// contract events of each block, keyed by block id. Blocks without any
+	// events do not have an entry.
+	FileContractEvents = []byte("FileContractEvents")
 )
 
 // createConsensusObjects initialzes the consensus portions of the database.
@@ -104,7 +110,7 @@ func (cs *ConsensusSet) createConsensusDB(tx *bolt.Tx) error {
 	// outputs - unspendable, as the unlock hash is blank.
 	createDSCOBucket(tx, types.MaturityDelay)
 	addDSCO(tx, types.MaturityDelay, cs.blockRoot.Block.MinerPayoutID(0), types.SiacoinOutput{
-		Value:      types.CalculateCoinbase(0),
+		Value:      cs.coinbaseSchedule(0),
 		UnlockHash: types.UnlockHash{},
 	})
 
@@ -339,6 +345,18 @@ func addFileContract(tx *bolt.Tx, id types.FileContractID, fc types.FileContract
 	if build.DEBUG && err != nil {
 		panic(err)
 	}
+
+	// Add an entry for when the storage proof window of the file contract
+	// opens.
+	windowStartBucketID := append(prefixFCWS, encoding.Marshal(fc.WindowStart)...)
+	windowStartBucket, err := tx.CreateBucketIfNotExists(windowStartBucketID)
+	if build.DEBUG && err != nil {
+		panic(err)
+	}
+	err = windowStartBucket.Put(id[:], []byte{})
+	if build.DEBUG && err != nil {
+		panic(err)
+	}
 }
 
 // removeFileContract removes a file contract from the database.
@@ -369,6 +387,58 @@ func removeFileContract(tx *bolt.Tx, id types.FileContractID) {
 	if build.DEBUG && err != nil {
 		panic(err)
 	}
+
+	// Delete the entry for the opening of the file contract's storage proof
+	// window, which uses the window start at bytes 40-48. File contracts
+	// created by older versions do not have an entry. The bucket is deleted
+	// once it is empty.
+	windowStartBucketID := append(prefixFCWS, fcBytes[40:48]...)
+	windowStartBucket := tx.Bucket(windowStartBucketID)
+	if windowStartBucket == nil {
+		return
+	}
+	err = windowStartBucket.Delete(id[:])
+	if build.DEBUG && err != nil {
+		panic(err)
+	}
+	if k, _ := windowStartBucket.Cursor().First(); k == nil {
+		err = tx.DeleteBucket(windowStartBucketID)
+		if build.DEBUG && err != nil {
+			panic(err)
+		}
+	}
+}
+
+// getFileContractEvents returns the file contract events of a block.
+func getFileContractEvents(tx *bolt.Tx, id types.BlockID) (events []modules.FileContractEvent) {
+	bucket := tx.Bucket(FileContractEvents)
+	if bucket == nil {
+		return nil
+	}
+	eventBytes := bucket.Get(id[:])
+	if eventBytes == nil {
+		return nil
+	}
+	err := encoding.Unmarshal(eventBytes, &events)
+	if build.DEBUG && err != nil {
+		panic(err)
+	}
+	return events
+}
+
+// setFileContractEvents stores the file contract events of a block.
+func setFileContractEvents(tx *bolt.Tx, id types.BlockID, events []modules.FileContractEvent) {
+	if len(events) == 0 {
+		return
+	}
+	bucket, err := tx.CreateBucketIfNotExists(FileContractEvents)
+	if build.DEBUG && err != nil {
+		panic(err)
+	}
+	err = bucket.Put(id[:], encoding.Marshal(events))
+	if build.DEBUG && err != nil {
+		panic(err)
+	}
 }
 
 // getSiafundOutput fetches a siafund output from the database. An error is
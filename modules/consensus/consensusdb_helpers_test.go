@@ -118,6 +118,18 @@ func (cs *ConsensusSet) getArbSiacoinOutput() (scoid types.SiacoinOutputID, sco
 	return scoid, sco, nil
 }
 
+// dbAddSiacoinOutput is a convenience function allowing addSiacoinOutput to be
+// called without a bolt.Tx.
+func (cs *ConsensusSet) dbAddSiacoinOutput(id types.SiacoinOutputID, sco types.SiacoinOutput) {
+	dbErr := cs.db.Update(func(tx *bolt.Tx) error {
+		addSiacoinOutput(tx, id, sco)
+		return nil
+	})
+	if dbErr != nil {
+		panic(dbErr)
+	}
+}
+
 // dbGetFileContract is a convenience function allowing getFileContract to be
 // called without a bolt.Tx.
 func (cs *ConsensusSet) dbGetFileContract(id types.FileContractID) (fc types.FileContract, err error) {
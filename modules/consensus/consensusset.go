@@ -8,11 +8,14 @@ package consensus
 
 import (
 	"errors"
+	"sync"
 
+	"github.com/NebulousLabs/Sia/build"
+	"github.com/NebulousLabs/Sia/crypto"
 	"github.com/NebulousLabs/Sia/encoding"
 	"github.com/NebulousLabs/Sia/modules"
 	"github.com/NebulousLabs/Sia/persist"
-	"github.com/NebulousLabs/Sia/sync"
+	siasync "github.com/NebulousLabs/Sia/sync"
 	"github.com/NebulousLabs/Sia/types"
 
 	"github.com/NebulousLabs/bolt"
@@ -20,7 +23,13 @@ import (
 )
 
 var (
-	errNilGateway = errors.New("cannot have a nil gateway as input")
+	errBlockSizeLimitMainnet   = errors.New("the block size limit cannot be changed on the main network")
+	errCoinbaseMainnet         = errors.New("the coinbase schedule cannot be changed on the main network")
+	errEasyTargetMainnet       = errors.New("the easy target cannot be used on the main network")
+	errFutureThresholdsOrder   = errors.New("the extreme future threshold must not be less than the future threshold")
+	errFutureThresholdsMainnet = errors.New("the future thresholds cannot be changed on the main network")
+	errNilGateway              = errors.New("cannot have a nil gateway as input")
+	errRootTargetMainnet       = errors.New("the root target cannot be changed on the main network")
 )
 
 // The ConsensusSet is the object responsible for tracking the current status
@@ -58,6 +67,34 @@ type ConsensusSet struct {
 	// the genesis block, meaning the PoW is not very expensive.
 	dosBlocks map[types.BlockID]struct{}
 
+	// futureBlocks are blocks that were too far in the future to be accepted
+	// when they were received. They are kept in a queue ordered by timestamp
+	// and reprocessed once they are no longer too far in the future.
+	// futureBlockIDs tracks which blocks are in the queue.
+	futureBlocks   futureBlockHeap
+	futureBlockIDs map[types.BlockID]struct{}
+
+	// recentBlocks is a bounded cache of the ids of blocks that have recently
+	// been added to the block map, allowing repeated relays of the same block
+	// to be rejected cheaply.
+	recentBlocks *blockCache
+
+	// recentChanges is a bounded log of the blocks that have been applied and
+	// reverted, kept to help debug unexpected reorgs. pendingChanges are the
+	// changes of the block that is currently being added, which are moved to
+	// recentChanges once the change has been committed to the database.
+	recentChanges  []ChangeRecord
+	pendingChanges []ChangeRecord
+
+	// stateRoot is the most recently computed state root, and stateRootBlock
+	// is the block at which it was computed. The root is recomputed when the
+	// current block changes. Computing the root only reads the database, so
+	// the cached root is protected by stateRootMu instead of mu, allowing it
+	// to be recomputed under a read lock.
+	stateRoot      crypto.Hash
+	stateRootBlock types.BlockID
+	stateRootMu    sync.Mutex
+
 	// checkingConsistency is a bool indicating whether or not a consistency
 	// check is in progress. The consistency check logic call itself, resulting
 	// in infinite loops. This bool prevents that while still allowing for full
@@ -70,7 +107,32 @@ type ConsensusSet struct {
 	// whether the consensus set is synced with the network.
 	synced bool
 
+	// easyTarget indicates that every block has the easy target, see
+	// GenesisParams.TestingEasyTarget.
+	easyTarget bool
+
+	// blockSizeLimit is the maximum size of a block in bytes, see
+	// GenesisParams.BlockSizeLimit. It does not change after the consensus
+	// set is created.
+	blockSizeLimit uint64
+
+	// futureThreshold and extremeFutureThreshold limit how far in the future
+	// a block timestamp may be, see GenesisParams.FutureThreshold. They do
+	// not change after the consensus set is created.
+	futureThreshold        types.Timestamp
+	extremeFutureThreshold types.Timestamp
+
+	// coinbaseSchedule returns the coinbase of a block at a given height, see
+	// GenesisParams.CoinbaseSchedule. It is never nil, and does not change
+	// after the consensus set is created.
+	coinbaseSchedule func(types.BlockHeight) types.Currency
+
+	// customCoinbase is set if coinbaseSchedule was provided by
+	// GenesisParams rather than defaulting to types.CalculateCoinbase.
+	customCoinbase bool
+
 	// Interfaces to abstract the dependencies of the ConsensusSet.
+	clock           types.Clock
 	marshaler       encoding.GenericMarshaler
 	blockRuleHelper blockRuleHelper
 	blockValidator  blockValidator
@@ -80,42 +142,156 @@ type ConsensusSet struct {
 	log        *persist.Logger
 	mu         demotemutex.DemoteMutex
 	persistDir string
-	tg         sync.ThreadGroup
+	tg         siasync.ThreadGroup
+}
+
+// GenesisParams are the parameters that determine the genesis block of a
+// consensus set. Consensus sets with different parameters are on entirely
+// separate blockchains, which makes it possible to run private test networks
+// that do not collide with the main network.
+type GenesisParams struct {
+	// Timestamp is the timestamp of the genesis block.
+	Timestamp types.Timestamp
+
+	// SiafundAllocation is the initial distribution of siafunds.
+	SiafundAllocation []types.SiafundOutput
+
+	// RootTarget is the target of the first block after the genesis block. It
+	// cannot be changed for the main network.
+	RootTarget types.Target
+
+	// TestingEasyTarget replaces the target of every block with a target so
+	// easy that blocks can be solved in a handful of attempts. All other
+	// validation still applies. It cannot be enabled for the main network.
+	TestingEasyTarget bool
+
+	// BlockSizeLimit is the maximum size of a block in bytes. If zero,
+	// types.BlockSizeLimit is used. It cannot be changed for the main
+	// network.
+	BlockSizeLimit uint64
+
+	// FutureThreshold and ExtremeFutureThreshold limit how far in the future
+	// the timestamp of a block may be. Blocks beyond the future threshold are
+	// held until their timestamp is acceptable, and blocks beyond the extreme
+	// future threshold are discarded. If zero, the values from the types
+	// package are used. They cannot be changed for the main network.
+	FutureThreshold        types.Timestamp
+	ExtremeFutureThreshold types.Timestamp
+
+	// CoinbaseSchedule returns the coinbase of a block at a given height,
+	// allowing test networks to use a different block reward than the main
+	// network. If nil, types.CalculateCoinbase is used. It cannot be changed
+	// for the main network.
+	CoinbaseSchedule func(types.BlockHeight) types.Currency
 }
 
 // New returns a new ConsensusSet, containing at least the genesis block. If
 // there is an existing block database present in the persist directory, it
 // will be loaded.
 func New(gateway modules.Gateway, bootstrap bool, persistDir string) (*ConsensusSet, error) {
+	return NewCustomConsensusSet(gateway, bootstrap, persistDir, GenesisParams{
+		Timestamp:         types.GenesisTimestamp,
+		SiafundAllocation: types.GenesisSiafundAllocation,
+		RootTarget:        types.RootTarget,
+	})
+}
+
+// isMainnet returns true if the genesis block is the genesis block of the main
+// network and the binary is a release build. The parameters of the main
+// network cannot be customized.
+func isMainnet(genesisBlock types.Block) bool {
+	return build.Release == "standard" && genesisBlock.ID() == types.GenesisID
+}
+
+// NewCustomConsensusSet returns a new ConsensusSet whose genesis block is
+// built from the provided parameters instead of the hardcoded genesis block.
+// The persist directory must not contain a database belonging to a
+// blockchain with a different genesis block.
+func NewCustomConsensusSet(gateway modules.Gateway, bootstrap bool, persistDir string, params GenesisParams) (*ConsensusSet, error) {
 	// Check for nil dependencies.
 	if gateway == nil {
 		return nil, errNilGateway
 	}
 
+	// Create the genesis block.
+	genesisBlock := types.Block{
+		Timestamp: params.Timestamp,
+		Transactions: []types.Transaction{
+			{SiafundOutputs: params.SiafundAllocation},
+		},
+	}
+	mainnet := isMainnet(genesisBlock)
+	if params.RootTarget != types.RootTarget && mainnet {
+		return nil, errRootTargetMainnet
+	}
+	if params.TestingEasyTarget {
+		if mainnet {
+			return nil, errEasyTargetMainnet
+		}
+		params.RootTarget = easyTarget
+	}
+	if params.BlockSizeLimit == 0 {
+		params.BlockSizeLimit = types.BlockSizeLimit
+	}
+	if params.BlockSizeLimit != types.BlockSizeLimit && mainnet {
+		return nil, errBlockSizeLimitMainnet
+	}
+	params.FutureThreshold, params.ExtremeFutureThreshold = futureThresholds(params.FutureThreshold, params.ExtremeFutureThreshold)
+	if params.ExtremeFutureThreshold < params.FutureThreshold {
+		return nil, errFutureThresholdsOrder
+	}
+	if (params.FutureThreshold != types.FutureThreshold || params.ExtremeFutureThreshold != types.ExtremeFutureThreshold) && mainnet {
+		return nil, errFutureThresholdsMainnet
+	}
+	customCoinbase := params.CoinbaseSchedule != nil
+	if params.CoinbaseSchedule == nil {
+		params.CoinbaseSchedule = types.CalculateCoinbase
+	} else if mainnet {
+		return nil, errCoinbaseMainnet
+	}
+
 	// Create the ConsensusSet object.
 	cs := &ConsensusSet{
 		gateway: gateway,
 
 		blockRoot: processedBlock{
-			Block:       types.GenesisBlock,
-			ChildTarget: types.RootTarget,
+			Block:       genesisBlock,
+			ChildTarget: params.RootTarget,
 			Depth:       types.RootDepth,
 
 			DiffsGenerated: true,
 		},
 
-		dosBlocks: make(map[types.BlockID]struct{}),
+		dosBlocks:      make(map[types.BlockID]struct{}),
+		futureBlockIDs: make(map[types.BlockID]struct{}),
+		recentBlocks:   newBlockCache(recentBlocksSize),
+		easyTarget:     params.TestingEasyTarget,
+		blockSizeLimit: params.BlockSizeLimit,
+
+		futureThreshold:        params.FutureThreshold,
+		extremeFutureThreshold: params.ExtremeFutureThreshold,
+		coinbaseSchedule:       params.CoinbaseSchedule,
+		customCoinbase:         customCoinbase,
 
+		clock:           networkClock{gateway},
 		marshaler:       encoding.StdGenericMarshaler{},
 		blockRuleHelper: stdBlockRuleHelper{},
-		blockValidator:  NewBlockValidator(),
+		blockValidator: stdBlockValidator{
+			clock:     networkClock{gateway},
+			marshaler: encoding.StdGenericMarshaler{},
+			sizeLimit: params.BlockSizeLimit,
+
+			futureThreshold:        params.FutureThreshold,
+			extremeFutureThreshold: params.ExtremeFutureThreshold,
+			coinbaseSchedule:       params.CoinbaseSchedule,
+		},
 
 		persistDir: persistDir,
 	}
 
 	// Create the diffs for the genesis siafund outputs.
-	for i, siafundOutput := range types.GenesisBlock.Transactions[0].SiafundOutputs {
-		sfid := types.GenesisBlock.Transactions[0].SiafundOutputID(uint64(i))
+	for i, siafundOutput := range genesisBlock.Transactions[0].SiafundOutputs {
+		sfid := genesisBlock.Transactions[0].SiafundOutputID(uint64(i))
 		sfod := modules.SiafundOutputDiff{
 			Direction:     modules.DiffApply,
 			ID:            sfid,
@@ -129,6 +305,8 @@ func New(gateway modules.Gateway, bootstrap bool, persistDir string) (*Consensus
 	if err != nil {
 		return nil, err
 	}
+	gateway.SetBlockHeight(cs.Height())
+	go cs.threadedProcessFutureBlocks()
 
 	go func() {
 		// Sync with the network. Don't sync if we are testing because
@@ -170,6 +348,9 @@ func New(gateway modules.Gateway, bootstrap bool, persistDir string) (*Consensus
 		cs.mu.Lock()
 		cs.synced = true
 		cs.mu.Unlock()
+
+		// Keep up with peers that report a greater height.
+		go cs.threadedSyncWithPeers()
 	}()
 
 	return cs, nil
@@ -214,9 +395,43 @@ func (cs *ConsensusSet) ChildTarget(id types.BlockID) (target types.Target, exis
 	return target, exists
 }
 
-// Close safely closes the block database.
+// TargetAtHeight returns the child target of the block at the given height in
+// the current path, which is the target that the block at the next height had
+// to meet. False is returned if there is no block at that height.
+func (cs *ConsensusSet) TargetAtHeight(height types.BlockHeight) (target types.Target, exists bool) {
+	// A call to a closed database can cause undefined behavior.
+	err := cs.tg.Add()
+	if err != nil {
+		return types.Target{}, false
+	}
+	defer cs.tg.Done()
+
+	_ = cs.db.View(func(tx *bolt.Tx) error {
+		id, err := getPath(tx, height)
+		if err != nil {
+			return err
+		}
+		pb, err := getBlockMap(tx, id)
+		if err != nil {
+			return err
+		}
+		target = pb.ChildTarget
+		exists = true
+		return nil
+	})
+	return target, exists
+}
+
+// Close stops all background threads of the consensus set, including any
+// threads waiting on future blocks, and then flushes and closes the block
+// database. After Close has been called, operations on the consensus set will
+// return modules.ErrClosed.
 func (cs *ConsensusSet) Close() error {
-	return cs.tg.Stop()
+	err := cs.tg.Stop()
+	if err == siasync.ErrStopped {
+		return modules.ErrClosed
+	}
+	return err
 }
 
 // managedCurrentBlock returns the latest block in the heaviest known blockchain.
@@ -251,13 +466,36 @@ func (cs *ConsensusSet) CurrentBlock() (block types.Block) {
 	return block
 }
 
+// CurrentBlockAndHeight returns the latest block in the heaviest known
+// blockchain together with its height. Both are read under a single lock
+// acquisition, so the pair is consistent even if a reorg is in progress.
+func (cs *ConsensusSet) CurrentBlockAndHeight() (block types.Block, height types.BlockHeight) {
+	// A call to a closed database can cause undefined behavior.
+	err := cs.tg.Add()
+	if err != nil {
+		return types.Block{}, 0
+	}
+	defer cs.tg.Done()
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+
+	_ = cs.db.View(func(tx *bolt.Tx) error {
+		pb := currentProcessedBlock(tx)
+		block = pb.Block
+		height = pb.Height
+		return nil
+	})
+	return block, height
+}
+
 // Flush will block until the consensus set has finished all in-progress
 // routines.
 func (cs *ConsensusSet) Flush() error {
 	return cs.tg.Flush()
 }
 
-// Height returns the height of the consensus set.
+// Height returns the height of the consensus set. Callers that also need the
+// current block should use CurrentBlockAndHeight, which reads both at once.
 func (cs *ConsensusSet) Height() (height types.BlockHeight) {
 	// A call to a closed database can cause undefined behavior.
 	err := cs.tg.Add()
@@ -323,13 +561,62 @@ func (cs *ConsensusSet) MinimumValidChildTimestamp(id types.BlockID) (timestamp
 	return timestamp, exists
 }
 
+// MaximumValidChildTimestamp returns the latest timestamp that the next block
+// can have in order to be accepted immediately, according to the
+// network-adjusted time and the future threshold of the consensus set. Blocks
+// with later timestamps are held until their timestamp is no longer in the
+// future.
+func (cs *ConsensusSet) MaximumValidChildTimestamp() types.Timestamp {
+	futureThreshold, _ := futureThresholds(cs.futureThreshold, cs.extremeFutureThreshold)
+	return cs.clock.Now() + futureThreshold
+}
+
+// SiafundClaimValue returns the number of siacoins that would be claimed by
+// spending the siafund output with the given id in the next block. An error is
+// returned if the siafund output is not in the consensus set.
+func (cs *ConsensusSet) SiafundClaimValue(id types.SiafundOutputID) (claim types.Currency, err error) {
+	err = cs.tg.Add()
+	if err != nil {
+		return types.Currency{}, modules.ErrClosed
+	}
+	defer cs.tg.Done()
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+
+	err = cs.db.View(func(tx *bolt.Tx) error {
+		sfo, err := getSiafundOutput(tx, id)
+		if err == errNilItem {
+			return errMissingSiafundOutput
+		} else if err != nil {
+			return err
+		}
+		claim = siafundClaim(tx, sfo)
+		return nil
+	})
+	return claim, err
+}
+
+// BlockSizeLimit returns the maximum size of a block in bytes.
+func (cs *ConsensusSet) BlockSizeLimit() uint64 {
+	// blockSizeLimit never changes, so no lock is needed.
+	return cs.blockSizeLimit
+}
+
+// Coinbase returns the coinbase of a block at the given height, not including
+// any miner fees.
+func (cs *ConsensusSet) Coinbase(height types.BlockHeight) types.Currency {
+	// coinbaseSchedule never changes, so no lock is needed.
+	return cs.coinbaseSchedule(height)
+}
+
 // StorageProofSegment returns the segment to be used in the storage proof for
-// a given file contract.
+// a given file contract. The segment is chosen using the ID of the block at
+// height WindowStart-1, and is unavailable until that block has been mined.
 func (cs *ConsensusSet) StorageProofSegment(fcid types.FileContractID) (index uint64, err error) {
 	// A call to a closed database can cause undefined behavior.
 	err = cs.tg.Add()
 	if err != nil {
-		return 0, err
+		return 0, modules.ErrClosed
 	}
 	defer cs.tg.Done()
 
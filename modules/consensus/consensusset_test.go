@@ -3,16 +3,21 @@ package consensus
 import (
 	"crypto/rand"
 	"path/filepath"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/NebulousLabs/Sia/build"
 	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/encoding"
 	"github.com/NebulousLabs/Sia/modules"
 	"github.com/NebulousLabs/Sia/modules/gateway"
 	"github.com/NebulousLabs/Sia/modules/miner"
 	"github.com/NebulousLabs/Sia/modules/transactionpool"
 	"github.com/NebulousLabs/Sia/modules/wallet"
 	"github.com/NebulousLabs/Sia/types"
+
+	"github.com/NebulousLabs/bolt"
 )
 
 // A consensusSetTester is the helper object for consensus set testing,
@@ -206,3 +211,669 @@ func TestDatabaseClosing(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+// TestClosedConsensusSet checks that a closed consensus set rejects further
+// operations, and that closing does not wait on pending future blocks.
+func TestClosedConsensusSet(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	cst, err := createConsensusSetTester("TestClosedConsensusSet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cst.miner.Close()
+	defer cst.gateway.Close()
+
+	// Submit a block from the near future so that the consensus set spawns a
+	// thread to wait on it.
+	block, target, err := cst.miner.BlockForWork()
+	if err != nil {
+		t.Fatal(err)
+	}
+	block.Timestamp = types.CurrentTimestamp() + 2 + types.FutureThreshold
+	solvedBlock, _ := cst.miner.SolveBlock(block, target)
+	err = cst.cs.AcceptBlock(solvedBlock)
+	if err != errFutureTimestamp {
+		t.Fatalf("expected %v, got %v", errFutureTimestamp, err)
+	}
+
+	// Close should not wait for the future block to become valid. If the
+	// waiting thread were leaked, Close would block until it finished.
+	start := time.Now()
+	err = cst.cs.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if time.Since(start) > time.Second {
+		t.Error("Close waited on the future block thread")
+	}
+
+	// Further operations should fail cleanly.
+	err = cst.cs.AcceptBlock(solvedBlock)
+	if err != modules.ErrClosed {
+		t.Error("expected ErrClosed, got", err)
+	}
+	_, err = cst.cs.TryTransactionSet(nil)
+	if err != modules.ErrClosed {
+		t.Error("expected ErrClosed, got", err)
+	}
+	err = cst.cs.CheckConsistency()
+	if err != modules.ErrClosed {
+		t.Error("expected ErrClosed, got", err)
+	}
+	err = cst.cs.Close()
+	if err != modules.ErrClosed {
+		t.Error("expected ErrClosed, got", err)
+	}
+}
+
+// TestSiafundClaimValue checks that the claim value of a siafund output grows
+// when a file contract adds to the siafund pool.
+func TestSiafundClaimValue(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	cst, err := createConsensusSetTester("TestSiafundClaimValue")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cst.Close()
+
+	// Unknown siafund outputs have no claim.
+	_, err = cst.cs.SiafundClaimValue(types.SiafundOutputID{})
+	if err != errMissingSiafundOutput {
+		t.Fatal("expected errMissingSiafundOutput, got", err)
+	}
+
+	// Find the largest siafund output in the consensus set.
+	var sfoid types.SiafundOutputID
+	var sfo types.SiafundOutput
+	err = cst.cs.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(SiafundOutputs).ForEach(func(k, v []byte) error {
+			var candidate types.SiafundOutput
+			err := encoding.Unmarshal(v, &candidate)
+			if err != nil {
+				return err
+			}
+			if candidate.Value.Cmp(sfo.Value) > 0 {
+				copy(sfoid[:], k)
+				sfo = candidate
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldClaim, err := cst.cs.SiafundClaimValue(sfoid)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Create a file contract, which adds its tax to the siafund pool.
+	oldPool := cst.cs.dbGetSiafundPool()
+	payout := types.NewCurrency64(400e6)
+	fc := types.FileContract{
+		WindowStart: cst.cs.dbBlockHeight() + 5,
+		WindowEnd:   cst.cs.dbBlockHeight() + 10,
+		Payout:      payout,
+		ValidProofOutputs: []types.SiacoinOutput{{
+			Value: types.PostTax(cst.cs.dbBlockHeight(), payout),
+		}},
+		MissedProofOutputs: []types.SiacoinOutput{{
+			Value: types.PostTax(cst.cs.dbBlockHeight(), payout),
+		}},
+	}
+	txnBuilder := cst.wallet.StartTransaction()
+	err = txnBuilder.FundSiacoins(payout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	txnBuilder.AddFileContract(fc)
+	txnSet, err := txnBuilder.Sign(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = cst.tpool.AcceptTransactionSet(txnSet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = cst.miner.AddBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The claim should have grown by the output's share of the tax.
+	poolGrowth := cst.cs.dbGetSiafundPool().Sub(oldPool)
+	if poolGrowth.IsZero() {
+		t.Fatal("siafund pool did not grow")
+	}
+	claim, err := cst.cs.SiafundClaimValue(sfoid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claim.Cmp(oldClaim) <= 0 {
+		t.Fatal("claim value did not grow")
+	}
+	expected := cst.cs.dbGetSiafundPool().Sub(sfo.ClaimStart).Div(types.SiafundCount).Mul(sfo.Value)
+	if claim.Cmp(expected) != 0 {
+		t.Errorf("expected claim of %v, got %v", expected, claim)
+	}
+}
+
+// TestNewCustomConsensusSet boots two consensus sets with different genesis
+// parameters and checks that they are on different blockchains.
+func TestNewCustomConsensusSet(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	testdir := build.TempDir(modules.ConsensusDir, "TestNewCustomConsensusSet")
+
+	newCustom := func(name string, params GenesisParams) *ConsensusSet {
+		g, err := gateway.New("localhost:0", false, filepath.Join(testdir, name, modules.GatewayDir))
+		if err != nil {
+			t.Fatal(err)
+		}
+		cs, err := NewCustomConsensusSet(g, false, filepath.Join(testdir, name, modules.ConsensusDir), params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return cs
+	}
+	params1 := GenesisParams{
+		Timestamp:         types.GenesisTimestamp + 1,
+		SiafundAllocation: []types.SiafundOutput{{Value: types.NewCurrency64(10e3), UnlockHash: randAddress()}},
+		RootTarget:        types.RootTarget,
+	}
+	params2 := GenesisParams{
+		Timestamp:         types.GenesisTimestamp + 2,
+		SiafundAllocation: []types.SiafundOutput{{Value: types.NewCurrency64(10e3), UnlockHash: randAddress()}},
+		RootTarget:        types.Target{1},
+	}
+	cs1 := newCustom("cs1", params1)
+	defer cs1.Close()
+	cs2 := newCustom("cs2", params2)
+	defer cs2.Close()
+
+	genesis1 := cs1.CurrentBlock()
+	genesis2 := cs2.CurrentBlock()
+	if genesis1.ID() == genesis2.ID() {
+		t.Fatal("consensus sets with different genesis params have the same genesis block")
+	}
+	if genesis1.ID() == types.GenesisID || genesis2.ID() == types.GenesisID {
+		t.Fatal("custom consensus set uses the default genesis block")
+	}
+	if genesis1.Timestamp != params1.Timestamp {
+		t.Error("genesis block has the wrong timestamp")
+	}
+	target, exists := cs2.ChildTarget(genesis2.ID())
+	if !exists || target != params2.RootTarget {
+		t.Error("genesis block has the wrong child target")
+	}
+
+	// The siafund allocation should be spendable.
+	sfid := genesis1.Transactions[0].SiafundOutputID(0)
+	err := cs1.db.View(func(tx *bolt.Tx) error {
+		sfo, err := getSiafundOutput(tx, sfid)
+		if err != nil {
+			return err
+		}
+		if sfo.UnlockHash != params1.SiafundAllocation[0].UnlockHash {
+			t.Error("genesis siafund output has the wrong unlock hash")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestCurrentBlockAndHeightDuringReorgs repeatedly reorgs a consensus set
+// between two competing chains while other goroutines read the current block
+// and height, checking that every pair read is consistent.
+func TestCurrentBlockAndHeightDuringReorgs(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	cstMain, err := createConsensusSetTester("TestCurrentBlockAndHeightDuringReorgs - main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cstMain.Close()
+	cstAlt1, err := createConsensusSetTester("TestCurrentBlockAndHeightDuringReorgs - alt1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cstAlt1.Close()
+	cstAlt2, err := createConsensusSetTester("TestCurrentBlockAndHeightDuringReorgs - alt2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cstAlt2.Close()
+
+	// Spin up readers that check each (block, height) pair against the
+	// height recorded for the block, which never changes.
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				block, height := cstMain.cs.CurrentBlockAndHeight()
+				pb, err := cstMain.cs.dbGetBlockMap(block.ID())
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if pb.Height != height {
+					t.Errorf("torn read: block at height %v reported with height %v", pb.Height, height)
+					return
+				}
+			}
+		}()
+	}
+
+	// Alternate between the two alternate chains, extending each until it is
+	// longer than the other and then feeding it to the main consensus set.
+	alts := []*consensusSetTester{cstAlt1, cstAlt2}
+	for i := 0; i < 10; i++ {
+		alt, other := alts[i%2], alts[(i+1)%2]
+		for alt.cs.dbBlockHeight() <= other.cs.dbBlockHeight() {
+			_, err := alt.miner.AddBlock()
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		for h := types.BlockHeight(1); h <= alt.cs.dbBlockHeight(); h++ {
+			block, _ := alt.cs.BlockAtHeight(h)
+			// err is not checked - the main set may already have the block.
+			_ = cstMain.cs.AcceptBlock(block)
+		}
+		if cstMain.cs.CurrentBlock().ID() != alt.cs.CurrentBlock().ID() {
+			t.Fatal("main consensus set did not reorg to the longer chain")
+		}
+	}
+	close(stop)
+	wg.Wait()
+}
+
+// TestTestingEasyTarget checks that a consensus set in easy target mode
+// accepts blocks that were solved in a few attempts, while still rejecting
+// blocks that are invalid for other reasons.
+func TestTestingEasyTarget(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	testdir := build.TempDir(modules.ConsensusDir, "TestTestingEasyTarget")
+	g, err := gateway.New("localhost:0", false, filepath.Join(testdir, modules.GatewayDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cs, err := NewCustomConsensusSet(g, false, filepath.Join(testdir, modules.ConsensusDir), GenesisParams{
+		Timestamp:         types.GenesisTimestamp,
+		SiafundAllocation: types.GenesisSiafundAllocation,
+		RootTarget:        types.RootTarget,
+		TestingEasyTarget: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cs.Close()
+
+	// solve finds a nonce for the block, failing if it takes more than a
+	// few attempts.
+	solve := func(b types.Block, target types.Target) types.Block {
+		for i := 0; i < 100; i++ {
+			if checkTarget(b, target) {
+				return b
+			}
+			b.Nonce[0]++
+		}
+		t.Fatal("could not solve block for the easy target")
+		return b
+	}
+
+	// Add blocks on top of each other.
+	for i := 0; i < 5; i++ {
+		parent, height := cs.CurrentBlockAndHeight()
+		target, _ := cs.ChildTarget(parent.ID())
+		if target != easyTarget {
+			t.Fatal("child target is not the easy target:", target)
+		}
+		b := solve(types.Block{
+			ParentID:     parent.ID(),
+			Timestamp:    types.CurrentTimestamp(),
+			MinerPayouts: []types.SiacoinOutput{{Value: types.CalculateCoinbase(height + 1)}},
+		}, target)
+		err = cs.AcceptBlock(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A block with an invalid miner payout is still rejected.
+	parent := cs.CurrentBlock()
+	b := solve(types.Block{
+		ParentID:     parent.ID(),
+		Timestamp:    types.CurrentTimestamp(),
+		MinerPayouts: []types.SiacoinOutput{{Value: types.CalculateCoinbase(0).Mul(types.NewCurrency64(2))}},
+	}, easyTarget)
+	err = cs.AcceptBlock(b)
+	if err != errBadMinerPayouts {
+		t.Fatal("expected errBadMinerPayouts, got", err)
+	}
+}
+
+// TestCustomBlockSizeLimit checks that a consensus set with a custom block
+// size limit accepts a block at the limit and rejects a block just over it.
+func TestCustomBlockSizeLimit(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	testdir := build.TempDir(modules.ConsensusDir, "TestCustomBlockSizeLimit")
+	g, err := gateway.New("localhost:0", false, filepath.Join(testdir, modules.GatewayDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sizeLimit := uint64(2e3)
+	cs, err := NewCustomConsensusSet(g, false, filepath.Join(testdir, modules.ConsensusDir), GenesisParams{
+		Timestamp:         types.GenesisTimestamp,
+		SiafundAllocation: types.GenesisSiafundAllocation,
+		RootTarget:        types.RootTarget,
+		TestingEasyTarget: true,
+		BlockSizeLimit:    sizeLimit,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cs.Close()
+	if cs.BlockSizeLimit() != sizeLimit {
+		t.Fatal("consensus set reports the wrong block size limit:", cs.BlockSizeLimit())
+	}
+
+	// paddedBlock creates a solved child of the current block whose encoded
+	// size is exactly 'size' bytes.
+	paddedBlock := func(size uint64) types.Block {
+		parent, height := cs.CurrentBlockAndHeight()
+		b := types.Block{
+			ParentID:     parent.ID(),
+			Timestamp:    types.CurrentTimestamp(),
+			MinerPayouts: []types.SiacoinOutput{{Value: types.CalculateCoinbase(height + 1)}},
+			Transactions: []types.Transaction{{ArbitraryData: [][]byte{{}}}},
+		}
+		b.Transactions[0].ArbitraryData[0] = make([]byte, size-uint64(len(encoding.Marshal(b))))
+		if uint64(len(encoding.Marshal(b))) != size {
+			t.Fatal("block was not padded to the requested size")
+		}
+		for !checkTarget(b, easyTarget) {
+			b.Nonce[0]++
+		}
+		return b
+	}
+
+	err = cs.AcceptBlock(paddedBlock(sizeLimit + 1))
+	if err != errLargeBlock {
+		t.Fatalf("expected %v, got %v", errLargeBlock, err)
+	}
+	err = cs.AcceptBlock(paddedBlock(sizeLimit))
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestTargetAtHeight checks that TargetAtHeight reports the child target of
+// each block in the current path, and that the target only changes at the
+// adjustment heights.
+func TestTargetAtHeight(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	cst, err := createConsensusSetTester("TestTargetAtHeight")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cst.Close()
+
+	genesisTarget, exists := cst.cs.TargetAtHeight(0)
+	if !exists {
+		t.Fatal("no target at the genesis height")
+	}
+	if genesisTarget != types.RootTarget {
+		t.Fatal("genesis child target is not the root target")
+	}
+
+	// Mine past the first adjustment height.
+	adjustmentInterval := types.TargetWindow / 2
+	for cst.cs.Height() <= adjustmentInterval {
+		_, err := cst.miner.AddBlock()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	height := cst.cs.Height()
+	for h := types.BlockHeight(0); h <= height; h++ {
+		target, exists := cst.cs.TargetAtHeight(h)
+		if !exists {
+			t.Fatal("no target at height", h)
+		}
+		b, _ := cst.cs.BlockAtHeight(h)
+		childTarget, _ := cst.cs.ChildTarget(b.ID())
+		if target != childTarget {
+			t.Fatal("target at height", h, "does not match the child target of the block")
+		}
+		if h < adjustmentInterval && target != genesisTarget {
+			t.Fatal("target changed before the first adjustment height:", h)
+		}
+	}
+
+	// The genesis block of the testing build is far in the past, so the
+	// first adjustment should have made the target easier.
+	adjusted, _ := cst.cs.TargetAtHeight(adjustmentInterval)
+	if adjusted.Int().Cmp(genesisTarget.Int()) <= 0 {
+		t.Error("target did not become easier at the first adjustment")
+	}
+	next, _ := cst.cs.TargetAtHeight(adjustmentInterval + 1)
+	if next != adjusted {
+		t.Error("target changed between adjustment heights")
+	}
+
+	// There is no target above the current height.
+	if _, exists := cst.cs.TargetAtHeight(height + 1); exists {
+		t.Error("target reported for a height above the current height")
+	}
+}
+
+// TestCustomFutureThresholds checks that a consensus set with custom future
+// thresholds holds and discards future blocks according to those thresholds.
+func TestCustomFutureThresholds(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	testdir := build.TempDir(modules.ConsensusDir, "TestCustomFutureThresholds")
+	g, err := gateway.New("localhost:0", false, filepath.Join(testdir, modules.GatewayDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer g.Close()
+	params := GenesisParams{
+		Timestamp:              types.GenesisTimestamp,
+		SiafundAllocation:      types.GenesisSiafundAllocation,
+		RootTarget:             types.RootTarget,
+		TestingEasyTarget:      true,
+		FutureThreshold:        1,
+		ExtremeFutureThreshold: 2,
+	}
+
+	// The extreme future threshold cannot be below the future threshold.
+	badParams := params
+	badParams.ExtremeFutureThreshold = 0
+	badParams.FutureThreshold = types.ExtremeFutureThreshold + 1
+	_, err = NewCustomConsensusSet(g, false, filepath.Join(testdir, "bad"), badParams)
+	if err != errFutureThresholdsOrder {
+		t.Fatal("expected errFutureThresholdsOrder, got", err)
+	}
+
+	cs, err := NewCustomConsensusSet(g, false, filepath.Join(testdir, modules.ConsensusDir), params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cs.Close()
+	cs.mu.Lock()
+	now := types.CurrentTimestamp()
+	cs.clock = mockClock{now: now}
+	cs.blockValidator = stdBlockValidator{
+		clock:     mockClock{now: now},
+		marshaler: encoding.StdGenericMarshaler{},
+
+		futureThreshold:        params.FutureThreshold,
+		extremeFutureThreshold: params.ExtremeFutureThreshold,
+	}
+	cs.mu.Unlock()
+
+	// newBlock returns a solved child of the current block with the given
+	// timestamp.
+	newBlock := func(timestamp types.Timestamp) types.Block {
+		parent, height := cs.CurrentBlockAndHeight()
+		b := types.Block{
+			ParentID:     parent.ID(),
+			Timestamp:    timestamp,
+			MinerPayouts: []types.SiacoinOutput{{Value: types.CalculateCoinbase(height + 1)}},
+		}
+		for !checkTarget(b, easyTarget) {
+			b.Nonce[0]++
+		}
+		return b
+	}
+
+	// A block beyond the custom extreme future threshold, but within the
+	// default one, is discarded.
+	err = cs.AcceptBlock(newBlock(now + 3))
+	if err != errExtremeFutureTimestamp {
+		t.Fatal("expected errExtremeFutureTimestamp, got", err)
+	}
+	cs.mu.RLock()
+	queued := cs.futureBlocks.Len()
+	cs.mu.RUnlock()
+	if queued != 0 {
+		t.Fatal("extreme future block was queued")
+	}
+
+	// A block beyond the custom future threshold is held, and accepted once
+	// its timestamp is within the threshold.
+	future := newBlock(now + 2)
+	err = cs.AcceptBlock(future)
+	if err != errFutureTimestamp {
+		t.Fatal("expected errFutureTimestamp, got", err)
+	}
+	cs.processFutureBlocks(now)
+	if cs.CurrentBlock().ID() == future.ID() {
+		t.Fatal("future block was accepted too early")
+	}
+	cs.mu.Lock()
+	cs.clock = mockClock{now: now + 1}
+	cs.blockValidator = stdBlockValidator{
+		clock:     mockClock{now: now + 1},
+		marshaler: encoding.StdGenericMarshaler{},
+
+		futureThreshold:        params.FutureThreshold,
+		extremeFutureThreshold: params.ExtremeFutureThreshold,
+	}
+	cs.mu.Unlock()
+	cs.processFutureBlocks(now + 1)
+	if cs.CurrentBlock().ID() != future.ID() {
+		t.Fatal("future block was not accepted after its timestamp became acceptable")
+	}
+}
+
+// TestCustomCoinbaseSchedule checks that a consensus set with a custom
+// coinbase schedule requires blocks to pay out the custom coinbase.
+func TestCustomCoinbaseSchedule(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	testdir := build.TempDir(modules.ConsensusDir, "TestCustomCoinbaseSchedule")
+	g, err := gateway.New("localhost:0", false, filepath.Join(testdir, modules.GatewayDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer g.Close()
+
+	// The coinbase starts at 1000 SC and halves every block, down to a
+	// minimum of 1 SC.
+	schedule := func(height types.BlockHeight) types.Currency {
+		if height >= 10 {
+			return types.SiacoinPrecision
+		}
+		return types.SiacoinPrecision.Mul64(1000 >> height)
+	}
+	cs, err := NewCustomConsensusSet(g, false, filepath.Join(testdir, modules.ConsensusDir), GenesisParams{
+		Timestamp:         types.GenesisTimestamp,
+		SiafundAllocation: types.GenesisSiafundAllocation,
+		RootTarget:        types.RootTarget,
+		TestingEasyTarget: true,
+		CoinbaseSchedule:  schedule,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cs.Close()
+
+	// newBlock returns a solved child of the current block that pays out the
+	// given coinbase.
+	newBlock := func(coinbase types.Currency) types.Block {
+		b := types.Block{
+			ParentID:     cs.CurrentBlock().ID(),
+			Timestamp:    types.CurrentTimestamp(),
+			MinerPayouts: []types.SiacoinOutput{{Value: coinbase}},
+		}
+		for !checkTarget(b, easyTarget) {
+			b.Nonce[0]++
+		}
+		return b
+	}
+
+	for height := types.BlockHeight(1); height <= 12; height++ {
+		if cs.Coinbase(height).Cmp(schedule(height)) != 0 {
+			t.Fatal("Coinbase does not match the schedule at height", height)
+		}
+
+		// A block paying out the default coinbase is rejected.
+		err = cs.AcceptBlock(newBlock(types.CalculateCoinbase(height)))
+		if err != errBadMinerPayouts {
+			t.Fatal("expected errBadMinerPayouts, got", err)
+		}
+		err = cs.AcceptBlock(newBlock(schedule(height)))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The supply should account for the custom schedule.
+	err = cs.CheckConsistency()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := types.ZeroCurrency
+	for height := types.BlockHeight(0); height <= cs.Height(); height++ {
+		expected = expected.Add(schedule(height))
+	}
+	if cs.CurrentSupply().Cmp(expected) != 0 {
+		t.Error("supply does not match the coinbase schedule")
+	}
+}
@@ -0,0 +1,221 @@
+// Package consensustest provides a consensus set tester that can be shared by
+// the tests of other modules. The tester wires a consensus set together with
+// a gateway, transaction pool, wallet, and miner, and relies on the testing
+// genesis block, so it can only be used in the testing build.
+//
+// consensustest is a testing helper and should not be imported by production
+// code.
+package consensustest
+
+import (
+	"errors"
+	"path/filepath"
+	"time"
+
+	"github.com/NebulousLabs/Sia/build"
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/modules/consensus"
+	"github.com/NebulousLabs/Sia/modules/gateway"
+	"github.com/NebulousLabs/Sia/modules/miner"
+	"github.com/NebulousLabs/Sia/modules/transactionpool"
+	"github.com/NebulousLabs/Sia/modules/wallet"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+var (
+	errHeightTimeout = errors.New("timed out waiting for the consensus set to reach the height")
+	errNotTesting    = errors.New("the consensus set tester can only be used in the testing build")
+	errNoSiafunds    = errors.New("wallet did not receive the testing siafunds")
+)
+
+// A Tester is a consensus set with the helper modules needed to create and
+// submit blocks and transactions.
+type Tester struct {
+	gateway   modules.Gateway
+	cs        *consensus.ConsensusSet
+	tpool     modules.TransactionPool
+	wallet    modules.Wallet
+	walletKey crypto.TwofishKey
+	miner     modules.TestMiner
+
+	persistDir string
+}
+
+// NewBlankTester creates a Tester that has only the genesis block, and whose
+// wallet is unlocked but empty.
+func NewBlankTester(name string) (*Tester, error) {
+	if build.Release != "testing" {
+		return nil, errNotTesting
+	}
+	testdir := build.TempDir(modules.ConsensusDir, name)
+
+	// Create the modules.
+	g, err := gateway.New("localhost:0", false, filepath.Join(testdir, modules.GatewayDir))
+	if err != nil {
+		return nil, err
+	}
+	cs, err := consensus.New(g, false, filepath.Join(testdir, modules.ConsensusDir))
+	if err != nil {
+		return nil, err
+	}
+	tp, err := transactionpool.New(cs, g, filepath.Join(testdir, modules.TransactionPoolDir))
+	if err != nil {
+		return nil, err
+	}
+	w, err := wallet.New(cs, tp, filepath.Join(testdir, modules.WalletDir))
+	if err != nil {
+		return nil, err
+	}
+	key, err := crypto.GenerateTwofishKey()
+	if err != nil {
+		return nil, err
+	}
+	_, err = w.Encrypt(key)
+	if err != nil {
+		return nil, err
+	}
+	err = w.Unlock(key)
+	if err != nil {
+		return nil, err
+	}
+	m, err := miner.New(cs, tp, w, filepath.Join(testdir, modules.MinerDir))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Tester{
+		gateway:   g,
+		cs:        cs,
+		tpool:     tp,
+		wallet:    w,
+		walletKey: key,
+		miner:     m,
+
+		persistDir: testdir,
+	}, nil
+}
+
+// NewTester creates a Tester whose wallet holds spendable siacoins and
+// siafunds.
+func NewTester(name string) (*Tester, error) {
+	t, err := NewBlankTester(name)
+	if err != nil {
+		return nil, err
+	}
+	err = t.addSiafunds()
+	if err != nil {
+		return nil, err
+	}
+	for i := types.BlockHeight(0); i <= types.MaturityDelay; i++ {
+		_, err = t.MineBlock()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+// addSiafunds moves the anyone-can-spend siafunds of the testing genesis block
+// into the wallet.
+func (t *Tester) addSiafunds() error {
+	uc, err := t.wallet.NextAddress()
+	if err != nil {
+		return err
+	}
+	genesis, exists := t.cs.BlockAtHeight(0)
+	if !exists {
+		return errors.New("genesis block not found")
+	}
+	txn := types.Transaction{
+		SiafundInputs: []types.SiafundInput{{
+			ParentID:         genesis.Transactions[0].SiafundOutputID(2),
+			UnlockConditions: types.UnlockConditions{},
+		}},
+		SiafundOutputs: []types.SiafundOutput{{
+			Value:      types.NewCurrency64(1e3),
+			UnlockHash: uc.UnlockHash(),
+		}},
+	}
+	err = t.tpool.AcceptTransactionSet([]types.Transaction{txn})
+	if err != nil {
+		return err
+	}
+	_, err = t.MineBlock()
+	if err != nil {
+		return err
+	}
+	_, siafundBalance, _ := t.wallet.ConfirmedBalance()
+	if siafundBalance.Cmp(types.NewCurrency64(1e3)) != 0 {
+		return errNoSiafunds
+	}
+	return nil
+}
+
+// ConsensusSet returns the consensus set of the tester.
+func (t *Tester) ConsensusSet() *consensus.ConsensusSet { return t.cs }
+
+// Gateway returns the gateway of the tester.
+func (t *Tester) Gateway() modules.Gateway { return t.gateway }
+
+// Miner returns the miner of the tester.
+func (t *Tester) Miner() modules.TestMiner { return t.miner }
+
+// PersistDir returns the directory that holds the persist directories of the
+// tester's modules.
+func (t *Tester) PersistDir() string { return t.persistDir }
+
+// TransactionPool returns the transaction pool of the tester.
+func (t *Tester) TransactionPool() modules.TransactionPool { return t.tpool }
+
+// Wallet returns the wallet of the tester.
+func (t *Tester) Wallet() modules.Wallet { return t.wallet }
+
+// WalletKey returns the key that the tester's wallet was encrypted with.
+func (t *Tester) WalletKey() crypto.TwofishKey { return t.walletKey }
+
+// FindBlock returns a solved block that builds on the current block and
+// contains the transactions in the transaction pool. The block is not
+// submitted.
+func (t *Tester) FindBlock() (types.Block, error) {
+	return t.miner.FindBlock()
+}
+
+// AcceptBlock submits a block to the consensus set.
+func (t *Tester) AcceptBlock(b types.Block) error {
+	return t.cs.AcceptBlock(b)
+}
+
+// MineBlock finds a block and submits it to the consensus set.
+func (t *Tester) MineBlock() (types.Block, error) {
+	b, err := t.FindBlock()
+	if err != nil {
+		return types.Block{}, err
+	}
+	return b, t.AcceptBlock(b)
+}
+
+// WaitForHeight blocks until the consensus set has reached the provided
+// height and the subscribers have processed the change, or until the timeout
+// expires.
+func (t *Tester) WaitForHeight(height types.BlockHeight, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for t.cs.Height() < height {
+		if time.Now().After(deadline) {
+			return errHeightTimeout
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.cs.SyncSubscribers()
+	return nil
+}
+
+// Close shuts down the modules of the tester.
+func (t *Tester) Close() error {
+	errs := []error{
+		t.miner.Close(),
+		t.cs.Close(),
+		t.gateway.Close(),
+	}
+	return build.JoinErrors(errs, "; ")
+}
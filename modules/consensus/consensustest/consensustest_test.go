@@ -0,0 +1,59 @@
+package consensustest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestNewTester checks that a new tester has a funded wallet and can mine
+// blocks.
+func TestNewTester(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	cst, err := NewTester("TestNewTester")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cst.Close()
+
+	siacoins, siafunds, _ := cst.Wallet().ConfirmedBalance()
+	if siacoins.IsZero() {
+		t.Error("wallet has no siacoins")
+	}
+	if siafunds.IsZero() {
+		t.Error("wallet has no siafunds")
+	}
+
+	// Spend some coins and mine the transaction into a block.
+	txns, err := cst.Wallet().SendSiacoins(types.SiacoinPrecision, types.UnlockHash{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	height := cst.ConsensusSet().Height()
+	b, err := cst.MineBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = cst.WaitForHeight(height+1, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cst.ConsensusSet().CurrentBlock().ID() != b.ID() {
+		t.Error("mined block is not the current block")
+	}
+	included := false
+	for _, txn := range b.Transactions {
+		if txn.ID() == txns[len(txns)-1].ID() {
+			included = true
+		}
+	}
+	if !included {
+		t.Error("transaction was not mined into the block")
+	}
+	if len(cst.TransactionPool().TransactionList()) != 0 {
+		t.Error("transaction pool was not cleared")
+	}
+}
@@ -8,11 +8,18 @@ import (
 	"github.com/NebulousLabs/Sia/build"
 	"github.com/NebulousLabs/Sia/crypto"
 	"github.com/NebulousLabs/Sia/encoding"
+	"github.com/NebulousLabs/Sia/modules"
 	"github.com/NebulousLabs/Sia/types"
 
 	"github.com/NebulousLabs/bolt"
 )
 
+var (
+	errChecksumMismatch = errors.New("consensus checksum does not match the checksum recorded for the current block")
+	errSiacoinMiscount  = errors.New("consensus set has the wrong number of siacoins")
+	errSiafundMiscount  = errors.New("consensus set has the wrong number of siafunds")
+)
+
 // manageErr handles an error detected by the consistency checks.
 func manageErr(tx *bolt.Tx, err error) {
 	markInconsistency(tx)
@@ -77,7 +84,7 @@ func consensusChecksum(tx *bolt.Tx) crypto.Hash {
 
 // checkSiacoinCount checks that the number of siacoins countable within the
 // consensus set equal the expected number of siacoins for the block height.
-func checkSiacoinCount(tx *bolt.Tx) {
+func (cs *ConsensusSet) checkSiacoinCount(tx *bolt.Tx) error {
 	// Iterate through all the buckets looking for the delayed siacoin output
 	// buckets, and check that they are for the correct heights.
 	var dscoSiacoins types.Currency
@@ -88,22 +95,18 @@ func checkSiacoinCount(tx *bolt.Tx) {
 		}
 
 		// Sum up the delayed outputs in this bucket.
-		err := b.ForEach(func(_, delayedOutput []byte) error {
+		return b.ForEach(func(_, delayedOutput []byte) error {
 			var sco types.SiacoinOutput
 			err := encoding.Unmarshal(delayedOutput, &sco)
 			if err != nil {
-				manageErr(tx, err)
+				return err
 			}
 			dscoSiacoins = dscoSiacoins.Add(sco.Value)
 			return nil
 		})
-		if err != nil {
-			return err
-		}
-		return nil
 	})
 	if err != nil {
-		manageErr(tx, err)
+		return err
 	}
 
 	// Add all of the siacoin outputs.
@@ -112,13 +115,13 @@ func checkSiacoinCount(tx *bolt.Tx) {
 		var sco types.SiacoinOutput
 		err := encoding.Unmarshal(scoBytes, &sco)
 		if err != nil {
-			manageErr(tx, err)
+			return err
 		}
 		scoSiacoins = scoSiacoins.Add(sco.Value)
 		return nil
 	})
 	if err != nil {
-		manageErr(tx, err)
+		return err
 	}
 
 	// Add all of the payouts from file contracts.
@@ -127,7 +130,7 @@ func checkSiacoinCount(tx *bolt.Tx) {
 		var fc types.FileContract
 		err := encoding.Unmarshal(fcBytes, &fc)
 		if err != nil {
-			manageErr(tx, err)
+			return err
 		}
 		var fcCoins types.Currency
 		for _, output := range fc.ValidProofOutputs {
@@ -137,7 +140,7 @@ func checkSiacoinCount(tx *bolt.Tx) {
 		return nil
 	})
 	if err != nil {
-		manageErr(tx, err)
+		return err
 	}
 
 	// Add all of the siafund claims.
@@ -146,7 +149,7 @@ func checkSiacoinCount(tx *bolt.Tx) {
 		var sfo types.SiafundOutput
 		err := encoding.Unmarshal(sfoBytes, &sfo)
 		if err != nil {
-			manageErr(tx, err)
+			return err
 		}
 
 		coinsPerFund := getSiafundPool(tx).Sub(sfo.ClaimStart)
@@ -155,46 +158,48 @@ func checkSiacoinCount(tx *bolt.Tx) {
 		return nil
 	})
 	if err != nil {
-		manageErr(tx, err)
+		return err
 	}
 
-	expectedSiacoins := types.CalculateNumSiacoins(blockHeight(tx))
+	expectedSiacoins := cs.siacoinSupply(blockHeight(tx))
 	totalSiacoins := dscoSiacoins.Add(scoSiacoins).Add(fcSiacoins).Add(claimSiacoins)
 	if totalSiacoins.Cmp(expectedSiacoins) != 0 {
-		diagnostics := fmt.Sprintf("Wrong number of siacoins\nDsco: %v\nSco: %v\nFc: %v\nClaim: %v\n", dscoSiacoins, scoSiacoins, fcSiacoins, claimSiacoins)
+		diagnostics := fmt.Sprintf("%v\nDsco: %v\nSco: %v\nFc: %v\nClaim: %v\n", errSiacoinMiscount, dscoSiacoins, scoSiacoins, fcSiacoins, claimSiacoins)
 		if totalSiacoins.Cmp(expectedSiacoins) < 0 {
 			diagnostics += fmt.Sprintf("total: %v\nexpected: %v\n expected is bigger: %v", totalSiacoins, expectedSiacoins, expectedSiacoins.Sub(totalSiacoins))
 		} else {
-			diagnostics += fmt.Sprintf("total: %v\nexpected: %v\n expected is bigger: %v", totalSiacoins, expectedSiacoins, totalSiacoins.Sub(expectedSiacoins))
+			diagnostics += fmt.Sprintf("total: %v\nexpected: %v\n total is bigger: %v", totalSiacoins, expectedSiacoins, totalSiacoins.Sub(expectedSiacoins))
 		}
-		manageErr(tx, errors.New(diagnostics))
+		return errors.New(diagnostics)
 	}
+	return nil
 }
 
 // checkSiafundCount checks that the number of siafunds countable within the
 // consensus set equal the expected number of siafunds for the block height.
-func checkSiafundCount(tx *bolt.Tx) {
+func checkSiafundCount(tx *bolt.Tx) error {
 	var total types.Currency
 	err := tx.Bucket(SiafundOutputs).ForEach(func(_, siafundOutputBytes []byte) error {
 		var sfo types.SiafundOutput
 		err := encoding.Unmarshal(siafundOutputBytes, &sfo)
 		if err != nil {
-			manageErr(tx, err)
+			return err
 		}
 		total = total.Add(sfo.Value)
 		return nil
 	})
 	if err != nil {
-		manageErr(tx, err)
+		return err
 	}
 	if total.Cmp(types.SiafundCount) != 0 {
-		manageErr(tx, errors.New("wrong number if siafunds in the consensus set"))
+		return fmt.Errorf("%v: found %v, expected %v", errSiafundMiscount, total, types.SiafundCount)
 	}
+	return nil
 }
 
 // checkDSCOs scans the sets of delayed siacoin outputs and checks for
 // consistency.
-func checkDSCOs(tx *bolt.Tx) {
+func (cs *ConsensusSet) checkDSCOs(tx *bolt.Tx) {
 	// Create a map to track which delayed siacoin output maps exist, and
 	// another map to track which ids have appeared in the dsco set.
 	dscoTracker := make(map[types.BlockHeight]struct{})
@@ -247,7 +252,7 @@ func checkDSCOs(tx *bolt.Tx) {
 
 		// Check that the minimum value has been achieved - the coinbase from
 		// an earlier block is guaranteed to be in the bucket.
-		minimumValue := types.CalculateCoinbase(height - types.MaturityDelay)
+		minimumValue := cs.coinbaseSchedule(height - types.MaturityDelay)
 		if total.Cmp(minimumValue) < 0 {
 			return errors.New("total number of coins in the delayed output bucket is incorrect")
 		}
@@ -316,9 +321,15 @@ func (cs *ConsensusSet) checkConsistency(tx *bolt.Tx) {
 		return
 	}
 	cs.checkingConsistency = true
-	checkDSCOs(tx)
-	checkSiacoinCount(tx)
-	checkSiafundCount(tx)
+	cs.checkDSCOs(tx)
+	err := cs.checkSiacoinCount(tx)
+	if err != nil {
+		manageErr(tx, err)
+	}
+	err = checkSiafundCount(tx)
+	if err != nil {
+		manageErr(tx, err)
+	}
 	if build.DEBUG {
 		cs.checkRevertApply(tx)
 	}
@@ -339,5 +350,39 @@ func (cs *ConsensusSet) maybeCheckConsistency(tx *bolt.Tx) {
 	}
 }
 
+// CheckConsistency verifies that the siacoins and siafunds in the consensus set
+// add up to the expected totals and that the consensus checksum matches the
+// checksum recorded for the current block. Unlike the internal consistency
+// checks, a failure is returned as an error instead of causing a panic, and the
+// consensus set is not marked as inconsistent.
+func (cs *ConsensusSet) CheckConsistency() error {
+	err := cs.tg.Add()
+	if err != nil {
+		return modules.ErrClosed
+	}
+	defer cs.tg.Done()
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+
+	return cs.db.View(func(tx *bolt.Tx) error {
+		err := cs.checkSiacoinCount(tx)
+		if err != nil {
+			return err
+		}
+		err = checkSiafundCount(tx)
+		if err != nil {
+			return err
+		}
+
+		// The checksum of the consensus set must match the checksum recorded
+		// for the current block, which is only available in debug builds.
+		recorded := currentProcessedBlock(tx).ConsensusChecksum
+		if recorded != (crypto.Hash{}) && recorded != consensusChecksum(tx) {
+			return errChecksumMismatch
+		}
+		return nil
+	})
+}
+
 // TODO: Check that every file contract has an expiration too, and that the
 // number of file contracts + the number of expirations is equal.
@@ -0,0 +1,46 @@
+package consensus
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestCheckConsistency corrupts the consensus set and checks that
+// CheckConsistency reports the corruption instead of panicking.
+func TestCheckConsistency(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	cst, err := createConsensusSetTester("TestCheckConsistency")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cst.Close()
+
+	// A healthy consensus set should pass the check.
+	err = cst.cs.CheckConsistency()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A zero value output does not change the number of siacoins, but it does
+	// change the checksum of the consensus set, which no longer matches the
+	// checksum recorded for the current block.
+	cst.cs.dbAddSiacoinOutput(types.SiacoinOutputID{2}, types.SiacoinOutput{})
+	err = cst.cs.CheckConsistency()
+	if err != errChecksumMismatch {
+		t.Fatal("expected errChecksumMismatch, got", err)
+	}
+
+	// Corrupt the consensus set by adding a siacoin output from nowhere.
+	cst.cs.dbAddSiacoinOutput(types.SiacoinOutputID{1}, types.SiacoinOutput{
+		Value: types.NewCurrency64(1),
+	})
+	err = cst.cs.CheckConsistency()
+	if err == nil || !strings.Contains(err.Error(), errSiacoinMiscount.Error()) {
+		t.Fatal("expected a siacoin miscount, got", err)
+	}
+}
@@ -230,6 +230,10 @@ func generateAndApplyDiff(tx *bolt.Tx, pb *processedBlock) error {
 	// the miner payouts to the list of delayed outputs.
 	applyMaintenance(tx, pb)
 
+	// Record the file contract events of the block, which can only be
+	// determined while the block is being applied.
+	setFileContractEvents(tx, pb.Block.ID(), fileContractEvents(tx, pb))
+
 	// DiffsGenerated are only set to true after the block has been fully
 	// validated and integrated. This is required to prevent later blocks from
 	// being accepted on top of an invalid block - if the consensus set ever
@@ -0,0 +1,58 @@
+package consensus
+
+import (
+	"github.com/NebulousLabs/Sia/build"
+	"github.com/NebulousLabs/Sia/encoding"
+	"github.com/NebulousLabs/Sia/types"
+
+	"github.com/NebulousLabs/bolt"
+)
+
+// fileContracts returns a copy of every open file contract for which filter
+// returns true.
+func (cs *ConsensusSet) fileContracts(filter func(types.FileContract) bool) map[types.FileContractID]types.FileContract {
+	err := cs.tg.Add()
+	if err != nil {
+		return nil
+	}
+	defer cs.tg.Done()
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+
+	fcs := make(map[types.FileContractID]types.FileContract)
+	err = cs.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(FileContracts).ForEach(func(k, v []byte) error {
+			var id types.FileContractID
+			var fc types.FileContract
+			copy(id[:], k)
+			err := encoding.Unmarshal(v, &fc)
+			if err != nil {
+				return err
+			}
+			if filter(fc) {
+				fcs[id] = fc
+			}
+			return nil
+		})
+	})
+	if build.DEBUG && err != nil {
+		panic(err)
+	}
+	return fcs
+}
+
+// ActiveFileContracts returns a copy of the file contracts that are open at
+// the current block, including contracts whose proof window has not started.
+func (cs *ConsensusSet) ActiveFileContracts() map[types.FileContractID]types.FileContract {
+	return cs.fileContracts(func(types.FileContract) bool {
+		return true
+	})
+}
+
+// FileContractsExpiringBefore returns a copy of the open file contracts whose
+// proof window ends before the given height.
+func (cs *ConsensusSet) FileContractsExpiringBefore(h types.BlockHeight) map[types.FileContractID]types.FileContract {
+	return cs.fileContracts(func(fc types.FileContract) bool {
+		return fc.WindowEnd < h
+	})
+}
@@ -0,0 +1,125 @@
+package consensus
+
+import (
+	"testing"
+
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestFileContractListing creates file contracts with different proof windows
+// and checks the contracts returned by ActiveFileContracts and
+// FileContractsExpiringBefore.
+func TestFileContractListing(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	cst, err := createConsensusSetTester("TestFileContractListing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cst.Close()
+
+	if len(cst.cs.ActiveFileContracts()) != 0 {
+		t.Fatal("consensus set tester should start without file contracts")
+	}
+
+	// Create three file contracts in a single block, each with a different
+	// proof window.
+	height := cst.cs.dbBlockHeight()
+	windowEnds := []types.BlockHeight{height + 10, height + 20, height + 30}
+	payout := types.NewCurrency64(400e6)
+	txnBuilder := cst.wallet.StartTransaction()
+	err = txnBuilder.FundSiacoins(payout.Mul64(uint64(len(windowEnds))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fcIndexes []uint64
+	for _, windowEnd := range windowEnds {
+		fc := types.FileContract{
+			WindowStart: windowEnd - 5,
+			WindowEnd:   windowEnd,
+			Payout:      payout,
+			ValidProofOutputs: []types.SiacoinOutput{{
+				Value: types.PostTax(height, payout),
+			}},
+			MissedProofOutputs: []types.SiacoinOutput{{
+				Value: types.PostTax(height, payout),
+			}},
+		}
+		fcIndexes = append(fcIndexes, txnBuilder.AddFileContract(fc))
+	}
+	txnSet, err := txnBuilder.Sign(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = cst.tpool.AcceptTransactionSet(txnSet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = cst.miner.AddBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fcids []types.FileContractID
+	for _, fcIndex := range fcIndexes {
+		fcids = append(fcids, txnSet[len(txnSet)-1].FileContractID(fcIndex))
+	}
+
+	// All three contracts should be active.
+	active := cst.cs.ActiveFileContracts()
+	if len(active) != len(fcids) {
+		t.Fatalf("expected %v active file contracts, got %v", len(fcids), len(active))
+	}
+	for i, fcid := range fcids {
+		fc, exists := active[fcid]
+		if !exists {
+			t.Fatal("file contract missing from active file contracts")
+		}
+		if fc.WindowEnd != windowEnds[i] {
+			t.Error("active file contract has the wrong window end")
+		}
+	}
+
+	// Check the filtered listing at heights around each window end.
+	tests := []struct {
+		height types.BlockHeight
+		fcids  []types.FileContractID
+	}{
+		{windowEnds[0], nil},
+		{windowEnds[0] + 1, fcids[:1]},
+		{windowEnds[1], fcids[:1]},
+		{windowEnds[1] + 1, fcids[:2]},
+		{windowEnds[2] + 1, fcids},
+	}
+	for _, test := range tests {
+		expiring := cst.cs.FileContractsExpiringBefore(test.height)
+		if len(expiring) != len(test.fcids) {
+			t.Fatalf("expected %v file contracts expiring before %v, got %v", len(test.fcids), test.height, len(expiring))
+		}
+		for _, fcid := range test.fcids {
+			if _, exists := expiring[fcid]; !exists {
+				t.Error("file contract missing from the expiring file contracts at height", test.height)
+			}
+		}
+	}
+
+	// Mine past the first window end; the first contract should no longer be
+	// listed.
+	for cst.cs.dbBlockHeight() < windowEnds[0] {
+		_, err = cst.miner.AddBlock()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	active = cst.cs.ActiveFileContracts()
+	if len(active) != len(fcids)-1 {
+		t.Fatalf("expected %v active file contracts, got %v", len(fcids)-1, len(active))
+	}
+	if _, exists := active[fcids[0]]; exists {
+		t.Error("expired file contract is still listed as active")
+	}
+	if len(cst.cs.FileContractsExpiringBefore(windowEnds[1])) != 0 {
+		t.Error("no remaining file contract should expire before", windowEnds[1])
+	}
+}
@@ -58,6 +58,7 @@ func (cs *ConsensusSet) revertToBlock(tx *bolt.Tx, pb *processedBlock) (reverted
 		block := currentProcessedBlock(tx)
 		commitDiffSet(tx, block, modules.DiffRevert)
 		revertedBlocks = append(revertedBlocks, block)
+		cs.recordChange(tx, block, modules.DiffRevert)
 
 		// Sanity check - after removing a block, check that the consensus set
 		// has maintained consistency.
@@ -89,6 +90,7 @@ func (cs *ConsensusSet) applyUntilBlock(tx *bolt.Tx, pb *processedBlock) (applie
 			}
 		}
 		appliedBlocks = append(appliedBlocks, block)
+		cs.recordChange(tx, block, modules.DiffApply)
 
 		// Sanity check - after applying a block, check that the consensus set
 		// has maintained consistency.
@@ -0,0 +1,108 @@
+package consensus
+
+import (
+	"container/heap"
+	"time"
+
+	"github.com/NebulousLabs/Sia/build"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+var (
+	// futureBlockCheckInterval is the amount of time between checks for
+	// future blocks that have become acceptable.
+	futureBlockCheckInterval = func() time.Duration {
+		switch build.Release {
+		case "dev":
+			return time.Second
+		case "standard":
+			return 10 * time.Second
+		case "testing":
+			return 250 * time.Millisecond
+		default:
+			panic("unrecognized build.Release")
+		}
+	}()
+)
+
+// futureBlockHeap is a priority queue of blocks that were received while
+// their timestamps were too far in the future to be accepted. The block with
+// the earliest timestamp is at the front of the queue.
+type futureBlockHeap []types.Block
+
+func (fbh futureBlockHeap) Len() int           { return len(fbh) }
+func (fbh futureBlockHeap) Less(i, j int) bool { return fbh[i].Timestamp < fbh[j].Timestamp }
+func (fbh futureBlockHeap) Swap(i, j int)      { fbh[i], fbh[j] = fbh[j], fbh[i] }
+
+// Push adds a block to the heap. Push should only be called through the heap
+// package.
+func (fbh *futureBlockHeap) Push(x interface{}) {
+	*fbh = append(*fbh, x.(types.Block))
+}
+
+// Pop removes the last block of the heap. Pop should only be called through
+// the heap package.
+func (fbh *futureBlockHeap) Pop() interface{} {
+	old := *fbh
+	b := old[len(old)-1]
+	*fbh = old[:len(old)-1]
+	return b
+}
+
+// queueFutureBlock adds a block to the queue of future blocks. A block that is
+// already queued is ignored, so that a peer relaying the same future block
+// many times does not grow the queue. A lock must be held on the consensus set
+// while calling queueFutureBlock.
+func (cs *ConsensusSet) queueFutureBlock(b types.Block) {
+	id := b.ID()
+	if _, exists := cs.futureBlockIDs[id]; exists {
+		return
+	}
+	cs.futureBlockIDs[id] = struct{}{}
+	heap.Push(&cs.futureBlocks, b)
+}
+
+// processFutureBlocks removes every queued block that is no longer too far in
+// the future at time 'now' and tries to add it to the consensus set. Blocks
+// are processed in timestamp order, so that a parent is processed before a
+// child that was queued at the same time.
+func (cs *ConsensusSet) processFutureBlocks(now types.Timestamp) {
+	cs.mu.Lock()
+	futureThreshold, _ := futureThresholds(cs.futureThreshold, cs.extremeFutureThreshold)
+	var ready []types.Block
+	for cs.futureBlocks.Len() > 0 && cs.futureBlocks[0].Timestamp <= now+futureThreshold {
+		b := heap.Pop(&cs.futureBlocks).(types.Block)
+		delete(cs.futureBlockIDs, b.ID())
+		ready = append(ready, b)
+	}
+	cs.mu.Unlock()
+
+	for _, b := range ready {
+		err := cs.managedAcceptBlock(b)
+		if err != nil {
+			cs.log.Debugln("WARN: failed to accept a future block:", err)
+			continue
+		}
+		cs.managedBroadcastBlock(b)
+	}
+}
+
+// threadedProcessFutureBlocks periodically processes the queue of future
+// blocks until the consensus set is closed.
+func (cs *ConsensusSet) threadedProcessFutureBlocks() {
+	for {
+		select {
+		case <-cs.tg.StopChan():
+			return
+		case <-time.After(futureBlockCheckInterval):
+		}
+
+		// A thread group 'Add' is only held while processing, so that Flush
+		// does not wait on the timer.
+		if cs.tg.Add() != nil {
+			return
+		}
+		cs.processFutureBlocks(cs.clock.Now())
+		cs.tg.Done()
+	}
+}
@@ -0,0 +1,77 @@
+package consensus
+
+import (
+	"testing"
+
+	"github.com/NebulousLabs/Sia/encoding"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestProcessFutureBlocks queues a future block and drives its processing by
+// advancing a fake clock.
+func TestProcessFutureBlocks(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	cst, err := createConsensusSetTester("TestProcessFutureBlocks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cst.Close()
+
+	// Replace the clocks of the consensus set with a fake clock.
+	setClock := func(now types.Timestamp) {
+		cst.cs.mu.Lock()
+		cst.cs.clock = mockClock{now: now}
+		cst.cs.blockValidator = stdBlockValidator{
+			clock:     mockClock{now: now},
+			marshaler: encoding.StdGenericMarshaler{},
+		}
+		cst.cs.mu.Unlock()
+	}
+	now := types.CurrentTimestamp()
+	setClock(now)
+
+	// Submit a block from the near future, twice.
+	block, target, err := cst.miner.BlockForWork()
+	if err != nil {
+		t.Fatal(err)
+	}
+	block.Timestamp = now + types.FutureThreshold + 2
+	solvedBlock, _ := cst.miner.SolveBlock(block, target)
+	for i := 0; i < 2; i++ {
+		err = cst.cs.AcceptBlock(solvedBlock)
+		if err != errFutureTimestamp {
+			t.Fatalf("expected %v, got %v", errFutureTimestamp, err)
+		}
+	}
+	cst.cs.mu.RLock()
+	queued := cst.cs.futureBlocks.Len()
+	cst.cs.mu.RUnlock()
+	if queued != 1 {
+		t.Fatal("expected one queued future block, got", queued)
+	}
+
+	// Processing before the block is acceptable should leave it queued.
+	cst.cs.processFutureBlocks(now + 1)
+	if _, err := cst.cs.dbGetBlockMap(solvedBlock.ID()); err == nil {
+		t.Fatal("future block was accepted too early")
+	}
+
+	// Advance the clock until the block is acceptable.
+	setClock(now + 2)
+	cst.cs.processFutureBlocks(now + 2)
+	if _, err := cst.cs.dbGetBlockMap(solvedBlock.ID()); err != nil {
+		t.Fatal("future block was not accepted:", err)
+	}
+	if cst.cs.dbCurrentBlockID() != solvedBlock.ID() {
+		t.Error("future block did not become the current block")
+	}
+	cst.cs.mu.RLock()
+	queued = cst.cs.futureBlocks.Len()
+	cst.cs.mu.RUnlock()
+	if queued != 0 {
+		t.Error("future block queue was not emptied, has", queued)
+	}
+}
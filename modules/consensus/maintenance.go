@@ -191,3 +191,54 @@ func applyMaintenance(tx *bolt.Tx, pb *processedBlock) {
 	applyMaturedSiacoinOutputs(tx, pb)
 	applyFileContractMaintenance(tx, pb)
 }
+
+// fileContractEvents returns the file contract events of a block that has
+// just been applied: the file contracts whose storage proof window opens at
+// the height of the block, and the file contracts that were resolved by the
+// block.
+func fileContractEvents(tx *bolt.Tx, pb *processedBlock) []modules.FileContractEvent {
+	var events []modules.FileContractEvent
+	addEvent := func(id types.FileContractID, eventType modules.FileContractEventType) {
+		events = append(events, modules.FileContractEvent{
+			Direction: modules.DiffApply,
+			ID:        id,
+			Type:      eventType,
+			Height:    pb.Height,
+		})
+	}
+
+	// File contracts that are still open and have a window start at the
+	// current height.
+	windowStartBucket := tx.Bucket(append(prefixFCWS, encoding.Marshal(pb.Height)...))
+	if windowStartBucket != nil {
+		err := windowStartBucket.ForEach(func(keyBytes, _ []byte) error {
+			var id types.FileContractID
+			copy(id[:], keyBytes)
+			addEvent(id, modules.FileContractWindowOpened)
+			return nil
+		})
+		if build.DEBUG && err != nil {
+			panic(err)
+		}
+	}
+
+	// File contracts with a storage proof in the block.
+	proven := make(map[types.FileContractID]struct{})
+	for _, txn := range pb.Block.Transactions {
+		for _, sp := range txn.StorageProofs {
+			proven[sp.ParentID] = struct{}{}
+			addEvent(sp.ParentID, modules.FileContractProofValid)
+		}
+	}
+
+	// File contracts that were removed by file contract maintenance.
+	for _, fcd := range pb.FileContractDiffs {
+		if fcd.Direction != modules.DiffRevert || fcd.FileContract.WindowEnd != pb.Height {
+			continue
+		}
+		if _, exists := proven[fcd.ID]; !exists {
+			addEvent(fcd.ID, modules.FileContractProofMissed)
+		}
+	}
+	return events
+}
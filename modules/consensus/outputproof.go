@@ -0,0 +1,131 @@
+package consensus
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/encoding"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+
+	"github.com/NebulousLabs/bolt"
+	"github.com/NebulousLabs/merkletree"
+)
+
+var (
+	errUnknownSiacoinOutput = errors.New("siacoin output is not in the consensus set")
+)
+
+// A SiacoinOutputProof proves that a siacoin output is in the set of unspent
+// siacoin outputs at a given block. The set of unspent siacoin outputs is
+// committed to by a Merkle tree whose leaves are the encoded (id, output)
+// pairs, in the byte order of the ids.
+type SiacoinOutputProof struct {
+	BlockID types.BlockID
+	Height  types.BlockHeight
+
+	ID     types.SiacoinOutputID
+	Output types.SiacoinOutput
+
+	Index     uint64
+	NumLeaves uint64
+	HashSet   []crypto.Hash
+}
+
+// siacoinOutputLeaf returns the leaf of the siacoin output tree for an
+// output.
+func siacoinOutputLeaf(id types.SiacoinOutputID, sco types.SiacoinOutput) []byte {
+	return encoding.MarshalAll(id, sco)
+}
+
+// bucketTree builds a Merkle tree whose leaves are the concatenated keys and
+// values of a bucket, set to prove the leaf at 'proofIndex'. The keys of a
+// bucket are sorted in byte order, therefore the tree is deterministic. For
+// the siacoin output bucket, the stored value is the encoded output, so each
+// leaf is the encoded (id, output) pair.
+func bucketTree(tx *bolt.Tx, bucket []byte, proofIndex uint64) *crypto.MerkleTree {
+	t := crypto.NewTree()
+	t.SetIndex(proofIndex)
+	_ = tx.Bucket(bucket).ForEach(func(k, v []byte) error {
+		t.Push(append(append([]byte(nil), k...), v...))
+		return nil
+	})
+	return t
+}
+
+// Verify returns true if the proof shows that the output is part of the
+// siacoin output set with the provided root.
+func (sop SiacoinOutputProof) Verify(root crypto.Hash) bool {
+	proofSet := make([][]byte, len(sop.HashSet)+1)
+	proofSet[0] = siacoinOutputLeaf(sop.ID, sop.Output)
+	for i := range sop.HashSet {
+		proofSet[i+1] = sop.HashSet[i][:]
+	}
+	return merkletree.VerifyProof(crypto.NewHash(), root[:], proofSet, sop.Index, sop.NumLeaves)
+}
+
+// SiacoinOutputRoot returns the Merkle root of the set of unspent siacoin
+// outputs at the current block. Computing the root requires reading every
+// unspent output.
+func (cs *ConsensusSet) SiacoinOutputRoot() (root crypto.Hash, err error) {
+	err = cs.tg.Add()
+	if err != nil {
+		return crypto.Hash{}, modules.ErrClosed
+	}
+	defer cs.tg.Done()
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+
+	err = cs.db.View(func(tx *bolt.Tx) error {
+		root = bucketTree(tx, SiacoinOutputs, 0).Root()
+		return nil
+	})
+	return root, err
+}
+
+// SiacoinOutputProof returns a proof that the siacoin output with the
+// provided id is unspent at the current block. The proof can be verified
+// against the root returned by SiacoinOutputRoot. Building the proof requires
+// reading every unspent output.
+func (cs *ConsensusSet) SiacoinOutputProof(id types.SiacoinOutputID) (sop SiacoinOutputProof, err error) {
+	err = cs.tg.Add()
+	if err != nil {
+		return SiacoinOutputProof{}, modules.ErrClosed
+	}
+	defer cs.tg.Done()
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+
+	err = cs.db.View(func(tx *bolt.Tx) error {
+		sco, err := getSiacoinOutput(tx, id)
+		if err == errNilItem {
+			return errUnknownSiacoinOutput
+		} else if err != nil {
+			return err
+		}
+
+		// Find the index of the output in the tree.
+		var index uint64
+		c := tx.Bucket(SiacoinOutputs).Cursor()
+		for k, _ := c.First(); k != nil && !bytes.Equal(k, id[:]); k, _ = c.Next() {
+			index++
+		}
+
+		_, proof, _, numLeaves := bucketTree(tx, SiacoinOutputs, index).Prove()
+		sop = SiacoinOutputProof{
+			BlockID:   currentBlockID(tx),
+			Height:    blockHeight(tx),
+			ID:        id,
+			Output:    sco,
+			Index:     index,
+			NumLeaves: numLeaves,
+			HashSet:   make([]crypto.Hash, len(proof)-1),
+		}
+		for i, p := range proof[1:] {
+			copy(sop.HashSet[i][:], p)
+		}
+		return nil
+	})
+	return sop, err
+}
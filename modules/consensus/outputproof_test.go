@@ -0,0 +1,97 @@
+package consensus
+
+import (
+	"testing"
+
+	"github.com/NebulousLabs/Sia/types"
+
+	"github.com/NebulousLabs/bolt"
+)
+
+// TestSiacoinOutputProof checks that siacoin output proofs verify against the
+// siacoin output root, and that forged proofs are rejected.
+func TestSiacoinOutputProof(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	cst, err := createConsensusSetTester("TestSiacoinOutputProof")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cst.Close()
+
+	// Collect the ids of the unspent siacoin outputs.
+	var ids []types.SiacoinOutputID
+	err = cst.cs.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(SiacoinOutputs).ForEach(func(k, _ []byte) error {
+			var id types.SiacoinOutputID
+			copy(id[:], k)
+			ids = append(ids, id)
+			return nil
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) < 2 {
+		t.Fatal("test requires at least two siacoin outputs")
+	}
+
+	root, err := cst.cs.SiacoinOutputRoot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range ids {
+		sop, err := cst.cs.SiacoinOutputProof(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !sop.Verify(root) {
+			t.Fatal("valid siacoin output proof was rejected")
+		}
+		if sop.BlockID != cst.cs.CurrentBlock().ID() {
+			t.Error("proof has the wrong block id")
+		}
+	}
+
+	// Forge proofs by altering the output, the id, and the index.
+	sop, err := cst.cs.SiacoinOutputProof(ids[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	forged := sop
+	forged.Output.Value = forged.Output.Value.Add(types.NewCurrency64(1))
+	if forged.Verify(root) {
+		t.Error("proof with an altered value was accepted")
+	}
+	forged = sop
+	forged.ID = ids[1]
+	if forged.Verify(root) {
+		t.Error("proof with an altered id was accepted")
+	}
+	forged = sop
+	forged.Index++
+	if forged.Verify(root) {
+		t.Error("proof with an altered index was accepted")
+	}
+
+	// The root changes once the output set changes.
+	_, err = cst.miner.AddBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	newRoot, err := cst.cs.SiacoinOutputRoot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if newRoot == root {
+		t.Error("siacoin output root did not change after a payout matured")
+	}
+
+	// Proofs cannot be made for unknown outputs.
+	_, err = cst.cs.SiacoinOutputProof(types.SiacoinOutputID{})
+	if err != errUnknownSiacoinOutput {
+		t.Error("expected", errUnknownSiacoinOutput, "got", err)
+	}
+}
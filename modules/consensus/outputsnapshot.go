@@ -0,0 +1,133 @@
+package consensus
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/NebulousLabs/Sia/build"
+	"github.com/NebulousLabs/Sia/encoding"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+
+	"github.com/NebulousLabs/bolt"
+)
+
+var (
+	errInvalidChunkSize = errors.New("chunk size must be greater than zero")
+	errOutputSetChanged = errors.New("the siacoin output set changed while it was being walked")
+)
+
+// SiacoinOutputSnapshot returns a copy of the set of unspent siacoin outputs
+// at the current block. The copy is taken in a single read transaction under
+// the consensus lock, so it never mixes outputs from different blocks. For
+// very large output sets, SiacoinOutputChunks avoids holding the whole set in
+// memory.
+func (cs *ConsensusSet) SiacoinOutputSnapshot() map[types.SiacoinOutputID]types.SiacoinOutput {
+	err := cs.tg.Add()
+	if err != nil {
+		return nil
+	}
+	defer cs.tg.Done()
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+
+	outputs := make(map[types.SiacoinOutputID]types.SiacoinOutput)
+	err = cs.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(SiacoinOutputs).ForEach(func(k, v []byte) error {
+			var id types.SiacoinOutputID
+			var sco types.SiacoinOutput
+			copy(id[:], k)
+			err := encoding.Unmarshal(v, &sco)
+			if err != nil {
+				return err
+			}
+			outputs[id] = sco
+			return nil
+		})
+	})
+	if build.DEBUG && err != nil {
+		panic(err)
+	}
+	return outputs
+}
+
+// siacoinOutputChunk returns at most chunkSize siacoin outputs, in the byte
+// order of their ids, starting with the first output whose id follows 'after'.
+// If 'first' is set, the chunk starts at the first output instead. The id of
+// the current block is returned along with the chunk.
+func (cs *ConsensusSet) siacoinOutputChunk(first bool, after types.SiacoinOutputID, chunkSize int) (chunk map[types.SiacoinOutputID]types.SiacoinOutput, last types.SiacoinOutputID, blockID types.BlockID, err error) {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+
+	chunk = make(map[types.SiacoinOutputID]types.SiacoinOutput)
+	err = cs.db.View(func(tx *bolt.Tx) error {
+		blockID = currentBlockID(tx)
+		c := tx.Bucket(SiacoinOutputs).Cursor()
+		var k, v []byte
+		if first {
+			k, v = c.First()
+		} else {
+			k, v = c.Seek(after[:])
+			if k != nil && bytes.Equal(k, after[:]) {
+				k, v = c.Next()
+			}
+		}
+		for ; k != nil && len(chunk) < chunkSize; k, v = c.Next() {
+			var id types.SiacoinOutputID
+			var sco types.SiacoinOutput
+			copy(id[:], k)
+			err := encoding.Unmarshal(v, &sco)
+			if err != nil {
+				return err
+			}
+			chunk[id] = sco
+			last = id
+		}
+		return nil
+	})
+	return chunk, last, blockID, err
+}
+
+// SiacoinOutputChunks walks the set of unspent siacoin outputs at the current
+// block, calling fn with chunks of at most chunkSize outputs. The chunks are
+// provided in the byte order of the output ids. Each chunk is read in its own
+// read transaction, and the consensus set is not locked while fn is called,
+// so fn may call the consensus set and blocks can be applied during the walk.
+// Every chunk is read from the same block, which is returned; if the current
+// block changes during the walk, the walk stops and errOutputSetChanged is
+// returned. If fn returns an error, the walk stops and the error is returned.
+func (cs *ConsensusSet) SiacoinOutputChunks(chunkSize int, fn func(map[types.SiacoinOutputID]types.SiacoinOutput) error) (types.BlockID, error) {
+	if chunkSize <= 0 {
+		return types.BlockID{}, errInvalidChunkSize
+	}
+	err := cs.tg.Add()
+	if err != nil {
+		return types.BlockID{}, modules.ErrClosed
+	}
+	defer cs.tg.Done()
+
+	var walkID types.BlockID
+	var last types.SiacoinOutputID
+	for first := true; ; first = false {
+		chunk, chunkLast, blockID, err := cs.siacoinOutputChunk(first, last, chunkSize)
+		if err != nil {
+			return walkID, err
+		}
+		if first {
+			walkID = blockID
+		} else if blockID != walkID {
+			return walkID, errOutputSetChanged
+		}
+		if len(chunk) == 0 {
+			return walkID, nil
+		}
+		err = fn(chunk)
+		if err != nil {
+			return walkID, err
+		}
+		if len(chunk) < chunkSize {
+			return walkID, nil
+		}
+		last = chunkLast
+	}
+}
@@ -0,0 +1,189 @@
+package consensus
+
+import (
+	"bytes"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// sortedOutputIDs sorts siacoin output ids in byte order.
+type sortedOutputIDs []types.SiacoinOutputID
+
+func (s sortedOutputIDs) Len() int           { return len(s) }
+func (s sortedOutputIDs) Less(i, j int) bool { return bytes.Compare(s[i][:], s[j][:]) < 0 }
+func (s sortedOutputIDs) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+// snapshotRoot returns the siacoin output root of a snapshot of the siacoin
+// output set, matching the root returned by SiacoinOutputRoot.
+func snapshotRoot(outputs map[types.SiacoinOutputID]types.SiacoinOutput) crypto.Hash {
+	ids := make(sortedOutputIDs, 0, len(outputs))
+	for id := range outputs {
+		ids = append(ids, id)
+	}
+	sort.Sort(ids)
+	t := crypto.NewTree()
+	for _, id := range ids {
+		t.Push(siacoinOutputLeaf(id, outputs[id]))
+	}
+	return t.Root()
+}
+
+// TestSiacoinOutputSnapshot takes snapshots of the siacoin output set while
+// blocks are being applied, and checks that every snapshot matches the output
+// set of some block.
+func TestSiacoinOutputSnapshot(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	cst, err := createConsensusSetTester("TestSiacoinOutputSnapshot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cst.Close()
+
+	// Record the output root of every block as it is applied. Only this
+	// goroutine applies blocks, so each root matches the state of a block.
+	root, err := cst.cs.SiacoinOutputRoot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var mu sync.Mutex
+	blockRoots := map[crypto.Hash]struct{}{root: {}}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 20; i++ {
+			// Spend some outputs so that the set changes in every block.
+			_, err := cst.wallet.SendSiacoins(types.SiacoinPrecision, types.UnlockHash{})
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			_, err = cst.miner.AddBlock()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			root, err := cst.cs.SiacoinOutputRoot()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			mu.Lock()
+			blockRoots[root] = struct{}{}
+			mu.Unlock()
+		}
+	}()
+
+	var snapshots []crypto.Hash
+	var chunkRoots []crypto.Hash
+	for running := true; running; {
+		select {
+		case <-done:
+			running = false
+		default:
+		}
+		snapshots = append(snapshots, snapshotRoot(cst.cs.SiacoinOutputSnapshot()))
+
+		// Reassemble the output set from chunks.
+		outputs := make(map[types.SiacoinOutputID]types.SiacoinOutput)
+		_, err := cst.cs.SiacoinOutputChunks(3, func(chunk map[types.SiacoinOutputID]types.SiacoinOutput) error {
+			if len(chunk) > 3 {
+				t.Error("chunk is too large:", len(chunk))
+			}
+			for id, sco := range chunk {
+				if _, exists := outputs[id]; exists {
+					t.Error("output appeared in two chunks")
+				}
+				outputs[id] = sco
+			}
+			return nil
+		})
+		if err == errOutputSetChanged {
+			// A block was applied during the walk.
+			continue
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		chunkRoots = append(chunkRoots, snapshotRoot(outputs))
+	}
+
+	for _, root := range snapshots {
+		if _, exists := blockRoots[root]; !exists {
+			t.Fatal("snapshot does not match the output set of any block")
+		}
+	}
+	for _, root := range chunkRoots {
+		if _, exists := blockRoots[root]; !exists {
+			t.Fatal("chunks do not match the output set of any block")
+		}
+	}
+
+	// A snapshot taken while no blocks are being applied matches the current
+	// output set.
+	root, err = cst.cs.SiacoinOutputRoot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snapshotRoot(cst.cs.SiacoinOutputSnapshot()) != root {
+		t.Error("snapshot does not match the current output set")
+	}
+	_, err = cst.cs.SiacoinOutputChunks(0, nil)
+	if err != errInvalidChunkSize {
+		t.Error("expected errInvalidChunkSize, got", err)
+	}
+}
+
+// TestSiacoinOutputChunksUnlocked checks that the consensus set is not locked
+// while the chunks of the siacoin output set are handed to the caller, and
+// that a walk during which a block is applied fails.
+func TestSiacoinOutputChunksUnlocked(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	cst, err := createConsensusSetTester("TestSiacoinOutputChunksUnlocked")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cst.Close()
+
+	// The consensus set can be read from inside the walk.
+	root, err := cst.cs.SiacoinOutputRoot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	outputs := make(map[types.SiacoinOutputID]types.SiacoinOutput)
+	walkID, err := cst.cs.SiacoinOutputChunks(2, func(chunk map[types.SiacoinOutputID]types.SiacoinOutput) error {
+		if cst.cs.CurrentBlock().ID() != cst.cs.dbCurrentProcessedBlock().Block.ID() {
+			t.Error("current block is inconsistent during the walk")
+		}
+		for id, sco := range chunk {
+			outputs[id] = sco
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if walkID != cst.cs.CurrentBlock().ID() {
+		t.Error("walk did not report the current block")
+	}
+	if snapshotRoot(outputs) != root {
+		t.Error("chunks do not match the current output set")
+	}
+
+	// Applying a block during the walk causes the walk to fail.
+	_, err = cst.cs.SiacoinOutputChunks(2, func(map[types.SiacoinOutputID]types.SiacoinOutput) error {
+		_, err := cst.miner.AddBlock()
+		return err
+	})
+	if err != errOutputSetChanged {
+		t.Fatal("expected errOutputSetChanged, got", err)
+	}
+}
@@ -85,7 +85,11 @@ func (cs *ConsensusSet) initPersist() error {
 	}
 	// Set up the closing of the database.
 	cs.tg.AfterStop(func() {
-		err := cs.db.Close()
+		err := cs.db.Sync()
+		if err != nil {
+			cs.log.Println("ERROR: Unable to sync consensus set database at shutdown:", err)
+		}
+		err = cs.db.Close()
 		if err != nil {
 			cs.log.Println("ERROR: Unable to close consensus set database at shutdown:", err)
 		}
@@ -1,16 +1,19 @@
 package consensus
 
 import (
+	"errors"
 	"path/filepath"
 	"testing"
 
 	"github.com/NebulousLabs/Sia/build"
 	"github.com/NebulousLabs/Sia/modules"
 	"github.com/NebulousLabs/Sia/modules/gateway"
+
+	"github.com/NebulousLabs/bolt"
 )
 
 // TestSaveLoad populates a blockchain, saves it, loads it, and checks
-// the consensus set hash before and after
+// the consensus set hash, height, and current block before and after
 func TestSaveLoad(t *testing.T) {
 	if testing.Short() {
 		t.SkipNow()
@@ -23,6 +26,7 @@ func TestSaveLoad(t *testing.T) {
 	defer cst.Close()
 	cst.testBlockSuite()
 	oldHash := cst.cs.dbConsensusChecksum()
+	oldBlock, oldHeight := cst.cs.CurrentBlockAndHeight()
 	cst.cs.Close()
 
 	// Reassigning this will lose subscribers and such, but we
@@ -40,4 +44,99 @@ func TestSaveLoad(t *testing.T) {
 	if oldHash != newHash {
 		t.Fatal("consensus set hash changed after load")
 	}
+	newBlock, newHeight := cst.cs.CurrentBlockAndHeight()
+	if newHeight != oldHeight {
+		t.Fatalf("height changed after load: was %v, is %v", oldHeight, newHeight)
+	}
+	if newBlock.ID() != oldBlock.ID() {
+		t.Fatal("current block changed after load")
+	}
+}
+
+// TestCrashDuringBlockApplication simulates a crash that happens after the
+// diffs of a block have been written, but before the database transaction
+// commits. Block application is atomic because it happens inside a single
+// bolt transaction, so the partially applied block must leave no trace, both
+// in the running consensus set and after a restart.
+func TestCrashDuringBlockApplication(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	cst, err := createConsensusSetTester("TestCrashDuringBlockApplication")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cst.Close()
+
+	oldHash := cst.cs.dbConsensusChecksum()
+	oldBlock, oldHeight := cst.cs.CurrentBlockAndHeight()
+
+	// Apply the diffs of a new block, then abort the transaction as though
+	// the process had died before the commit.
+	b, _ := cst.miner.FindBlock()
+	errCrash := errors.New("simulated crash before commit")
+	cst.cs.mu.Lock()
+	err = cst.cs.db.Update(func(tx *bolt.Tx) error {
+		pb, err := getBlockMap(tx, b.ParentID)
+		if err != nil {
+			return err
+		}
+		newNode := cst.cs.newChild(tx, pb, b)
+		_, _, err = cst.cs.forkBlockchain(tx, newNode)
+		if err != nil {
+			return err
+		}
+		if consensusChecksum(tx) == oldHash {
+			t.Error("diffs of the block were not applied before the crash")
+		}
+		return errCrash
+	})
+	cst.cs.pendingChanges = nil
+	cst.cs.mu.Unlock()
+	if err != errCrash {
+		t.Fatal("expected the simulated crash, got", err)
+	}
+
+	// The consensus set should be exactly as it was before the block.
+	checkUnchanged := func() {
+		if cst.cs.dbConsensusChecksum() != oldHash {
+			t.Fatal("consensus set hash changed after an aborted block application")
+		}
+		currentBlock, height := cst.cs.CurrentBlockAndHeight()
+		if height != oldHeight || currentBlock.ID() != oldBlock.ID() {
+			t.Fatal("current block changed after an aborted block application")
+		}
+		err := cst.cs.CheckConsistency()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	checkUnchanged()
+
+	// Restart the consensus set from the same directory, and check again.
+	err = cst.cs.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := gateway.New("localhost:0", false, build.TempDir(modules.ConsensusDir, "TestCrashDuringBlockApplication", "gateway2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer g.Close()
+	d := filepath.Join(build.SiaTestingDir, modules.ConsensusDir, "TestCrashDuringBlockApplication", modules.ConsensusDir)
+	cst.cs, err = New(g, false, d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkUnchanged()
+
+	// The block can still be accepted normally.
+	err = cst.cs.AcceptBlock(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cst.cs.Height() != oldHeight+1 {
+		t.Fatal("block was not accepted after recovery")
+	}
 }
@@ -21,6 +21,12 @@ import (
 // timestamp to produce a sufficiently heavier block.
 var SurpassThreshold = big.NewRat(20, 100)
 
+// easyTarget is the target of every block in easy target mode. It is the
+// easiest target for which a block that surpasses the current block by the
+// SurpassThreshold can still be represented, so that the heaviest fork can be
+// determined as usual. About one in five block IDs meets the target.
+var easyTarget = types.RatToTarget(new(big.Rat).Mul(types.RootDepth.Rat(), SurpassThreshold))
+
 // processedBlock is a copy/rename of blockNode, with the pointers to
 // other blockNodes replaced with block ID's, and all the fields
 // exported, so that a block node can be marshalled
@@ -44,6 +50,10 @@ type processedBlock struct {
 // 'cmp'. 'cmp' is expected to be the current block node. "Sufficient" means
 // that the weight of 'bn' exceeds the weight of 'cmp' by:
 //		(the target of 'cmp' * 'Surpass Threshold')
+//
+// Because a competing chain must be heavier by a margin, a chain of equal work
+// never replaces the current chain: ties are broken in favor of the chain that
+// was seen first, and are resolved once either chain is extended.
 func (pb *processedBlock) heavierThan(cmp *processedBlock) bool {
 	requirement := cmp.Depth.AddDifficulties(cmp.ChildTarget.MulDifficulty(SurpassThreshold))
 	return requirement.Cmp(pb.Depth) > 0 // Inversed, because the smaller target is actually heavier.
@@ -51,7 +61,9 @@ func (pb *processedBlock) heavierThan(cmp *processedBlock) bool {
 
 // childDepth returns the depth of a blockNode's child nodes. The depth is the
 // "sum" of the current depth and current difficulty. See target.Add for more
-// detailed information.
+// detailed information. The depth is therefore the cumulative work of the
+// chain ending at the node, and it is the depth, not the height, that decides
+// which fork is heaviest.
 func (pb *processedBlock) childDepth() types.Target {
 	return pb.Depth.AddDifficulties(pb.ChildTarget)
 }
@@ -110,6 +122,12 @@ func (cs *ConsensusSet) setChildTarget(blockMap *bolt.Bucket, pb *processedBlock
 		panic(err)
 	}
 
+	// In easy target mode, every block has the easiest possible target.
+	if cs.easyTarget {
+		pb.ChildTarget = easyTarget
+		return
+	}
+
 	if pb.Height%(types.TargetWindow/2) != 0 {
 		pb.ChildTarget = parent.ChildTarget
 		return
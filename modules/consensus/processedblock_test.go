@@ -144,6 +144,48 @@ func TestUnitHeavierThan(t *testing.T) {
 	}
 }
 
+// TestUnitHeavierChainWorkBeatsLength checks that a short chain of difficult
+// blocks is heavier than a longer chain of easy blocks from the same parent.
+func TestUnitHeavierChainWorkBeatsLength(t *testing.T) {
+	// extend returns the tip of a chain of n blocks on top of parent, where
+	// every block in the chain has the given target.
+	extend := func(parent *processedBlock, target types.Target, n int) *processedBlock {
+		tip := new(processedBlock)
+		*tip = *parent
+		tip.ChildTarget = target
+		for i := 0; i < n; i++ {
+			child := new(processedBlock)
+			child.Height = tip.Height + 1
+			child.Depth = tip.childDepth()
+			child.ChildTarget = target
+			tip = child
+		}
+		return tip
+	}
+
+	parent := new(processedBlock)
+	parent.Height = 100
+	parent.Depth[0] = 1
+
+	// The easy blocks have a difficulty of 2 and the hard blocks have a
+	// difficulty of 8, so four easy blocks carry half the work of two hard
+	// blocks.
+	var lowDifficulty, highDifficulty types.Target
+	lowDifficulty[0] = 128
+	highDifficulty[0] = 32
+	long := extend(parent, lowDifficulty, 4)
+	short := extend(parent, highDifficulty, 2)
+	if short.Height >= long.Height {
+		t.Fatal("short chain is not shorter than the long chain")
+	}
+	if !short.heavierThan(long) {
+		t.Error("short chain with more work is not heavier than the long chain")
+	}
+	if long.heavierThan(short) {
+		t.Error("long chain with less work is heavier than the short chain")
+	}
+}
+
 // TestChildDepth probes the childDeath method of the blockNode type.
 func TestChildDepth(t *testing.T) {
 	// Try adding to equal weight nodes, result should be half.
@@ -0,0 +1,46 @@
+package consensus
+
+import (
+	"github.com/NebulousLabs/Sia/crypto"
+
+	"github.com/NebulousLabs/bolt"
+)
+
+// stateRoot returns the Merkle root of the consensus state. The leaves of the
+// tree are the roots of the siacoin output, file contract, and siafund output
+// sets, in that order. A SiacoinOutputProof can be extended to the state root
+// with a proof of the first leaf.
+func stateRoot(tx *bolt.Tx) crypto.Hash {
+	t := crypto.NewTree()
+	for _, bucket := range [][]byte{SiacoinOutputs, FileContracts, SiafundOutputs} {
+		root := bucketTree(tx, bucket, 0).Root()
+		t.Push(root[:])
+	}
+	return t.Root()
+}
+
+// StateRoot returns the Merkle root of the consensus state at the current
+// block. Because the state is fully determined by the current block, the
+// root is only recomputed when the current block changes, and reverting and
+// reapplying blocks always produces the same root for the same block.
+func (cs *ConsensusSet) StateRoot() crypto.Hash {
+	err := cs.tg.Add()
+	if err != nil {
+		return crypto.Hash{}
+	}
+	defer cs.tg.Done()
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+	cs.stateRootMu.Lock()
+	defer cs.stateRootMu.Unlock()
+
+	_ = cs.db.View(func(tx *bolt.Tx) error {
+		id := currentBlockID(tx)
+		if cs.stateRootBlock != id {
+			cs.stateRoot = stateRoot(tx)
+			cs.stateRootBlock = id
+		}
+		return nil
+	})
+	return cs.stateRoot
+}
@@ -0,0 +1,113 @@
+package consensus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/types"
+
+	"github.com/NebulousLabs/bolt"
+)
+
+// TestStateRootReorgParity mines blocks, reverts them, and reapplies them,
+// checking that the state root returns to the same value at each block.
+func TestStateRootReorgParity(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	cst, err := createConsensusSetTester("TestStateRootReorgParity")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cst.Close()
+
+	// Mine blocks, recording the state root at each one.
+	startPB := cst.cs.dbCurrentProcessedBlock()
+	roots := make(map[types.BlockID]crypto.Hash)
+	roots[startPB.Block.ID()] = cst.cs.StateRoot()
+	for i := 0; i < 4; i++ {
+		b, err := cst.miner.AddBlock()
+		if err != nil {
+			t.Fatal(err)
+		}
+		root := cst.cs.StateRoot()
+		if root != stateRootOf(cst.cs) {
+			t.Fatal("cached state root does not match the state")
+		}
+		for _, prev := range roots {
+			if prev == root {
+				t.Fatal("state root did not change after mining a block")
+			}
+		}
+		roots[b.ID()] = root
+	}
+	endPB := cst.cs.dbCurrentProcessedBlock()
+
+	// Revert the mined blocks one at a time, checking the root along the way.
+	pb := endPB
+	for pb.Block.ID() != startPB.Block.ID() {
+		pb, err = cst.cs.dbGetBlockMap(pb.Block.ParentID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _, err = cst.cs.dbForkBlockchain(pb)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if root := cst.cs.StateRoot(); root != roots[pb.Block.ID()] {
+			t.Fatal("state root changed after reverting blocks")
+		}
+	}
+
+	// Reapply the blocks.
+	_, _, err = cst.cs.dbForkBlockchain(endPB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if root := cst.cs.StateRoot(); root != roots[endPB.Block.ID()] {
+		t.Fatal("state root changed after reapplying blocks")
+	}
+}
+
+// TestStateRootReadLock checks that the state root can be computed while
+// another caller holds a read lock on the consensus set.
+func TestStateRootReadLock(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	cst, err := createConsensusSetTester("TestStateRootReadLock")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cst.Close()
+
+	cst.cs.mu.RLock()
+	defer cst.cs.mu.RUnlock()
+	rootChan := make(chan crypto.Hash)
+	go func() {
+		rootChan <- cst.cs.StateRoot()
+	}()
+	select {
+	case root := <-rootChan:
+		if root == (crypto.Hash{}) {
+			t.Fatal("state root is empty")
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("StateRoot blocked on a read lock of the consensus set")
+	}
+}
+
+// stateRootOf computes the state root of a consensus set without using the
+// cache.
+func stateRootOf(cs *ConsensusSet) (root crypto.Hash) {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+	_ = cs.db.View(func(tx *bolt.Tx) error {
+		root = stateRoot(tx)
+		return nil
+	})
+	return root
+}
@@ -0,0 +1,153 @@
+package consensus
+
+import (
+	"errors"
+
+	"github.com/NebulousLabs/Sia/encoding"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+
+	"github.com/NebulousLabs/bolt"
+)
+
+var (
+	errBlockNeverApplied = errors.New("block has not been applied to the consensus set")
+	errUnknownBlock      = errors.New("block is not known to the consensus set")
+)
+
+// ConsensusStats is a snapshot of the state of the consensus set, intended to
+// give operators a quick overview of its health.
+type ConsensusStats struct {
+	Height         types.BlockHeight
+	CurrentBlock   types.BlockID
+	SiacoinSupply  types.Currency
+	FileContracts  int
+	SiacoinOutputs int
+	SiafundPool    types.Currency
+}
+
+// Stats returns a snapshot of the current state of the consensus set. All
+// fields are read within a single database transaction, so the snapshot is
+// consistent even if a block is being processed concurrently.
+func (cs *ConsensusSet) Stats() (stats ConsensusStats) {
+	// A call to a closed database can cause undefined behavior.
+	err := cs.tg.Add()
+	if err != nil {
+		return ConsensusStats{}
+	}
+	defer cs.tg.Done()
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+
+	_ = cs.db.View(func(tx *bolt.Tx) error {
+		stats.Height = blockHeight(tx)
+		stats.CurrentBlock = currentBlockID(tx)
+		stats.SiacoinSupply = cs.siacoinSupply(stats.Height)
+		stats.FileContracts = tx.Bucket(FileContracts).Stats().KeyN
+		stats.SiacoinOutputs = tx.Bucket(SiacoinOutputs).Stats().KeyN
+		stats.SiafundPool = getSiafundPool(tx)
+		return nil
+	})
+	return stats
+}
+
+// siacoinSupply returns the number of siacoins that have been created by the
+// blockchain up to and including 'height': the siacoin outputs of the genesis
+// block plus the block reward of every block.
+func (cs *ConsensusSet) siacoinSupply(height types.BlockHeight) types.Currency {
+	supply := types.ZeroCurrency
+	for _, txn := range cs.blockRoot.Block.Transactions {
+		for _, sco := range txn.SiacoinOutputs {
+			supply = supply.Add(sco.Value)
+		}
+	}
+	// The default schedule has a closed form, only a custom schedule needs
+	// to be summed block by block.
+	if !cs.customCoinbase {
+		return supply.Add(types.CalculateNumSiacoins(height))
+	}
+	for i := types.BlockHeight(0); i <= height; i++ {
+		supply = supply.Add(cs.coinbaseSchedule(i))
+	}
+	return supply
+}
+
+// CurrentSupply returns the total number of siacoins that have been created
+// as of the current block.
+func (cs *ConsensusSet) CurrentSupply() types.Currency {
+	// A call to a closed database can cause undefined behavior.
+	err := cs.tg.Add()
+	if err != nil {
+		return types.ZeroCurrency
+	}
+	defer cs.tg.Done()
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+
+	var height types.BlockHeight
+	_ = cs.db.View(func(tx *bolt.Tx) error {
+		height = blockHeight(tx)
+		return nil
+	})
+	return cs.siacoinSupply(height)
+}
+
+// BlockStats returns the number of transactions in a block, the encoded size
+// of the block, and the total fees paid by its transactions. The fees are
+// derived from the value of the siacoin inputs minus the value of the siacoin
+// outputs and file contract payouts, which requires that the block has been
+// applied to the consensus set at some point.
+func (cs *ConsensusSet) BlockStats(id types.BlockID) (txnCount int, sizeBytes uint64, totalFees types.Currency, err error) {
+	err = cs.tg.Add()
+	if err != nil {
+		return 0, 0, types.Currency{}, modules.ErrClosed
+	}
+	defer cs.tg.Done()
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+
+	var pb *processedBlock
+	err = cs.db.View(func(tx *bolt.Tx) error {
+		var err error
+		pb, err = getBlockMap(tx, id)
+		return err
+	})
+	if err == errNilItem {
+		return 0, 0, types.Currency{}, errUnknownBlock
+	} else if err != nil {
+		return 0, 0, types.Currency{}, err
+	}
+	if !pb.DiffsGenerated {
+		return 0, 0, types.Currency{}, errBlockNeverApplied
+	}
+	txnCount = len(pb.Block.Transactions)
+	sizeBytes = uint64(len(encoding.Marshal(pb.Block)))
+
+	// The transactions of the genesis block create siacoins from nothing and
+	// pay no fees.
+	if id == cs.blockRoot.Block.ID() {
+		return txnCount, sizeBytes, types.ZeroCurrency, nil
+	}
+
+	// The values of the spent siacoin outputs are recorded in the diffs of
+	// the block.
+	spent := make(map[types.SiacoinOutputID]types.Currency)
+	for _, scod := range pb.SiacoinOutputDiffs {
+		if scod.Direction == modules.DiffRevert {
+			spent[scod.ID] = scod.SiacoinOutput.Value
+		}
+	}
+	var inputs, outputs types.Currency
+	for _, txn := range pb.Block.Transactions {
+		for _, sci := range txn.SiacoinInputs {
+			inputs = inputs.Add(spent[sci.ParentID])
+		}
+		for _, sco := range txn.SiacoinOutputs {
+			outputs = outputs.Add(sco.Value)
+		}
+		for _, fc := range txn.FileContracts {
+			outputs = outputs.Add(fc.Payout)
+		}
+	}
+	return txnCount, sizeBytes, inputs.Sub(outputs), nil
+}
@@ -0,0 +1,216 @@
+package consensus
+
+import (
+	"testing"
+
+	"github.com/NebulousLabs/Sia/encoding"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestStats mines a few blocks containing file contracts and checks that the
+// stats of the consensus set reflect them.
+func TestStats(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	cst, err := createConsensusSetTester("TestStats")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cst.Close()
+
+	stats := cst.cs.Stats()
+	if stats.Height != cst.cs.Height() || stats.CurrentBlock != cst.cs.CurrentBlock().ID() {
+		t.Error("stats report the wrong tip:", stats.Height, cst.cs.Height())
+	}
+	if stats.FileContracts != 0 {
+		t.Error("stats report file contracts before any were created:", stats.FileContracts)
+	}
+	if stats.SiacoinOutputs == 0 {
+		t.Error("stats report no siacoin outputs after mining")
+	}
+	if stats.SiacoinSupply.Cmp(types.CalculateNumSiacoins(stats.Height)) != 0 {
+		t.Error("stats report the wrong siacoin supply:", stats.SiacoinSupply)
+	}
+
+	// Mine a few blocks that each contain a file contract.
+	numContracts := 3
+	payout := types.NewCurrency64(400e6)
+	for i := 0; i < numContracts; i++ {
+		fc := types.FileContract{
+			WindowStart:        cst.cs.dbBlockHeight() + 10,
+			WindowEnd:          cst.cs.dbBlockHeight() + 20,
+			Payout:             payout,
+			ValidProofOutputs:  []types.SiacoinOutput{{Value: types.PostTax(cst.cs.dbBlockHeight(), payout)}},
+			MissedProofOutputs: []types.SiacoinOutput{{Value: types.PostTax(cst.cs.dbBlockHeight(), payout)}},
+		}
+		txnBuilder := cst.wallet.StartTransaction()
+		err = txnBuilder.FundSiacoins(payout)
+		if err != nil {
+			t.Fatal(err)
+		}
+		txnBuilder.AddFileContract(fc)
+		txnSet, err := txnBuilder.Sign(true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = cst.tpool.AcceptTransactionSet(txnSet)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = cst.miner.AddBlock()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	stats = cst.cs.Stats()
+	if stats.Height != cst.cs.Height() || stats.CurrentBlock != cst.cs.CurrentBlock().ID() {
+		t.Error("stats report the wrong tip:", stats.Height, cst.cs.Height())
+	}
+	if stats.FileContracts != numContracts {
+		t.Errorf("expected %v file contracts, got %v", numContracts, stats.FileContracts)
+	}
+	if stats.SiafundPool.IsZero() {
+		t.Error("siafund pool was not reported after creating file contracts")
+	}
+}
+
+// TestCurrentSupply checks that the current supply is the sum of the block
+// rewards of every block in a short chain.
+func TestCurrentSupply(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	cst, err := createConsensusSetTester("TestCurrentSupply")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cst.Close()
+
+	for i := 0; i < 3; i++ {
+		expected := types.ZeroCurrency
+		for h := types.BlockHeight(0); h <= cst.cs.Height(); h++ {
+			expected = expected.Add(types.CalculateCoinbase(h))
+		}
+		supply := cst.cs.CurrentSupply()
+		if supply.Cmp(expected) != 0 {
+			t.Fatalf("expected supply %v at height %v, got %v", expected, cst.cs.Height(), supply)
+		}
+		if supply.Cmp(types.CalculateNumSiacoins(cst.cs.Height())) != 0 {
+			t.Fatal("supply does not match CalculateNumSiacoins:", supply)
+		}
+		_, err = cst.miner.AddBlock()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The closed form used for the default coinbase schedule should match
+	// the sum of the block rewards on both sides of the end of deflation.
+	deflationBlocks := types.BlockHeight(types.InitialCoinbase - types.MinimumCoinbase)
+	checkpoints := map[types.BlockHeight]struct{}{
+		deflationBlocks - 1:    {},
+		deflationBlocks:        {},
+		deflationBlocks + 1:    {},
+		deflationBlocks + 1000: {},
+	}
+	expected := types.ZeroCurrency
+	for h := types.BlockHeight(0); h <= deflationBlocks+1000; h++ {
+		expected = expected.Add(types.CalculateCoinbase(h))
+		if _, exists := checkpoints[h]; !exists {
+			continue
+		}
+		if supply := cst.cs.siacoinSupply(h); supply.Cmp(expected) != 0 {
+			t.Errorf("expected supply %v at height %v, got %v", expected, h, supply)
+		}
+	}
+}
+
+// TestBlockStats mines a block with known transactions and checks the
+// reported transaction count, size, and fees.
+func TestBlockStats(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	cst, err := createConsensusSetTester("TestBlockStats")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cst.Close()
+
+	_, _, _, err = cst.cs.BlockStats(types.BlockID{})
+	if err != errUnknownBlock {
+		t.Fatal("expected errUnknownBlock, got", err)
+	}
+
+	// Submit a transaction paying a fee and a file contract paying a fee.
+	fee1 := types.SiacoinPrecision.Mul64(3)
+	fee2 := types.SiacoinPrecision.Mul64(5)
+	txnBuilder := cst.wallet.StartTransaction()
+	err = txnBuilder.FundSiacoins(types.SiacoinPrecision.Add(fee1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	txnBuilder.AddSiacoinOutput(types.SiacoinOutput{Value: types.SiacoinPrecision})
+	txnBuilder.AddMinerFee(fee1)
+	txnSet, err := txnBuilder.Sign(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = cst.tpool.AcceptTransactionSet(txnSet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	payout := types.NewCurrency64(400e6)
+	fc := types.FileContract{
+		WindowStart:        cst.cs.dbBlockHeight() + 10,
+		WindowEnd:          cst.cs.dbBlockHeight() + 20,
+		Payout:             payout,
+		ValidProofOutputs:  []types.SiacoinOutput{{Value: types.PostTax(cst.cs.dbBlockHeight(), payout)}},
+		MissedProofOutputs: []types.SiacoinOutput{{Value: types.PostTax(cst.cs.dbBlockHeight(), payout)}},
+	}
+	txnBuilder = cst.wallet.StartTransaction()
+	err = txnBuilder.FundSiacoins(payout.Add(fee2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	txnBuilder.AddFileContract(fc)
+	txnBuilder.AddMinerFee(fee2)
+	txnSet, err = txnBuilder.Sign(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = cst.tpool.AcceptTransactionSet(txnSet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, err := cst.miner.AddBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	txnCount, size, fees, err := cst.cs.BlockStats(block.ID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if txnCount != len(block.Transactions) || txnCount < 2 {
+		t.Errorf("expected %v transactions, got %v", len(block.Transactions), txnCount)
+	}
+	if size != uint64(len(encoding.Marshal(block))) {
+		t.Errorf("expected a size of %v, got %v", len(encoding.Marshal(block)), size)
+	}
+	if fees.Cmp(fee1.Add(fee2)) != 0 {
+		t.Errorf("expected fees of %v, got %v", fee1.Add(fee2), fees)
+	}
+
+	// The genesis block pays no fees.
+	_, _, fees, err = cst.cs.BlockStats(cst.cs.blockRoot.Block.ID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !fees.IsZero() {
+		t.Error("genesis block reported fees:", fees)
+	}
+}
@@ -22,6 +22,9 @@ func (cs *ConsensusSet) computeConsensusChange(tx *bolt.Tx, ce changeEntry) (mod
 		// Because the direction is 'revert', the order of the diffs needs to
 		// be flipped and the direction of the diffs also needs to be flipped.
 		cc.RevertedBlocks = append(cc.RevertedBlocks, revertedBlock.Block)
+		for i := len(revertedBlock.Block.Transactions) - 1; i >= 0; i-- {
+			cc.RevertedTransactionIDs = append(cc.RevertedTransactionIDs, revertedBlock.Block.Transactions[i].ID())
+		}
 		for i := len(revertedBlock.SiacoinOutputDiffs) - 1; i >= 0; i-- {
 			scod := revertedBlock.SiacoinOutputDiffs[i]
 			scod.Direction = !scod.Direction
@@ -47,6 +50,12 @@ func (cs *ConsensusSet) computeConsensusChange(tx *bolt.Tx, ce changeEntry) (mod
 			sfpd.Direction = modules.DiffRevert
 			cc.SiafundPoolDiffs = append(cc.SiafundPoolDiffs, sfpd)
 		}
+		events := getFileContractEvents(tx, revertedBlockID)
+		for i := len(events) - 1; i >= 0; i-- {
+			event := events[i]
+			event.Direction = modules.DiffRevert
+			cc.FileContractEvents = append(cc.FileContractEvents, event)
+		}
 	}
 	for _, appliedBlockID := range ce.AppliedBlocks {
 		appliedBlock, err := getBlockMap(tx, appliedBlockID)
@@ -56,6 +65,9 @@ func (cs *ConsensusSet) computeConsensusChange(tx *bolt.Tx, ce changeEntry) (mod
 		}
 
 		cc.AppliedBlocks = append(cc.AppliedBlocks, appliedBlock.Block)
+		for _, txn := range appliedBlock.Block.Transactions {
+			cc.AppliedTransactionIDs = append(cc.AppliedTransactionIDs, txn.ID())
+		}
 		for _, scod := range appliedBlock.SiacoinOutputDiffs {
 			cc.SiacoinOutputDiffs = append(cc.SiacoinOutputDiffs, scod)
 		}
@@ -71,6 +83,7 @@ func (cs *ConsensusSet) computeConsensusChange(tx *bolt.Tx, ce changeEntry) (mod
 		for _, sfpd := range appliedBlock.SiafundPoolDiffs {
 			cc.SiafundPoolDiffs = append(cc.SiafundPoolDiffs, sfpd)
 		}
+		cc.FileContractEvents = append(cc.FileContractEvents, getFileContractEvents(tx, appliedBlockID)...)
 	}
 
 	// Grab the child target and the minimum valid child timestamp.
@@ -174,7 +187,7 @@ func (cs *ConsensusSet) initializeSubscribe(subscriber modules.ConsensusSetSubsc
 func (cs *ConsensusSet) ConsensusSetSubscribe(subscriber modules.ConsensusSetSubscriber, start modules.ConsensusChangeID) error {
 	err := cs.tg.Add()
 	if err != nil {
-		return err
+		return modules.ErrClosed
 	}
 	defer cs.tg.Done()
 	cs.mu.Lock()
@@ -192,6 +205,25 @@ func (cs *ConsensusSet) ConsensusSetSubscribe(subscriber modules.ConsensusSetSub
 	return nil
 }
 
+// SyncSubscribers blocks until every subscriber has finished processing the
+// most recent consensus change. Blocks accepted through AcceptBlock have been
+// sent to the subscribers by the time AcceptBlock returns, but blocks from
+// peers and from the future block queue are accepted in the background, and
+// the subscribers may still be processing them. Work that a subscriber hands
+// off to its own goroutines is not waited for.
+func (cs *ConsensusSet) SyncSubscribers() {
+	if cs.tg.Add() != nil {
+		return
+	}
+	defer cs.tg.Done()
+
+	// Subscribers are updated while the write lock is held, or after it has
+	// been demoted, so acquiring the write lock waits for any update that is
+	// in progress.
+	cs.mu.Lock()
+	cs.mu.Unlock()
+}
+
 // Unsubscribe removes a subscriber from the list of subscribers, allowing for
 // garbage collection and rescanning. If the subscriber is not found in the
 // subscriber database, no action is taken.
@@ -1,9 +1,14 @@
 package consensus
 
 import (
+	"crypto/rand"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/NebulousLabs/Sia/crypto"
 	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
 )
 
 // mockSubscriber receives and holds changes to the consensus set, remembering
@@ -148,3 +153,255 @@ func TestUnsubscribe(t *testing.T) {
 		t.Error("mock subscriber was not correctly unsubscribed")
 	}
 }
+
+// TestFileContractEvents creates file contracts, mines through their storage
+// proof windows, and checks that a subscriber is notified of the window
+// opening and of the resolution of each contract at the right heights.
+func TestFileContractEvents(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	cst, err := createConsensusSetTester("TestFileContractEvents")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cst.Close()
+	ms := newMockSubscriber()
+	err = cst.cs.ConsensusSetSubscribe(&ms, modules.ConsensusChangeRecent)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Create one file contract that will miss its storage proof and one
+	// that will be proven. The proven contract is for a single segment, so
+	// the segment to prove is known in advance.
+	h := cst.cs.dbBlockHeight()
+	payout := types.NewCurrency64(400e6)
+	missedFC := types.FileContract{
+		WindowStart:        h + 3,
+		WindowEnd:          h + 5,
+		Payout:             payout,
+		ValidProofOutputs:  []types.SiacoinOutput{{Value: types.PostTax(h, payout)}},
+		MissedProofOutputs: []types.SiacoinOutput{{Value: types.PostTax(h, payout)}},
+	}
+	provenFC := missedFC
+	provenFC.WindowEnd = h + 8
+	file := make([]byte, crypto.SegmentSize)
+	_, err = rand.Read(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	provenFC.FileSize = uint64(len(file))
+	provenFC.FileMerkleRoot = crypto.MerkleRoot(file)
+	txnBuilder := cst.wallet.StartTransaction()
+	err = txnBuilder.FundSiacoins(payout.Mul(types.NewCurrency64(2)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	missedIndex := txnBuilder.AddFileContract(missedFC)
+	provenIndex := txnBuilder.AddFileContract(provenFC)
+	txnSet, err := txnBuilder.Sign(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = cst.tpool.AcceptTransactionSet(txnSet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	missedID := txnSet[len(txnSet)-1].FileContractID(missedIndex)
+	provenID := txnSet[len(txnSet)-1].FileContractID(provenIndex)
+
+	// Mine until the window opens, then submit a storage proof for the
+	// second contract and mine until the first contract expires.
+	for cst.cs.dbBlockHeight() < h+3 {
+		_, err = cst.miner.AddBlock()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	segment, hashSet := crypto.MerkleProof(file, 0)
+	sp := types.StorageProof{
+		ParentID: provenID,
+		HashSet:  hashSet,
+	}
+	copy(sp.Segment[:], segment)
+	err = cst.tpool.AcceptTransactionSet([]types.Transaction{{
+		StorageProofs: []types.StorageProof{sp},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for cst.cs.dbBlockHeight() < h+5 {
+		_, err = cst.miner.AddBlock()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Collect the events received by the subscriber.
+	var events []modules.FileContractEvent
+	for _, cc := range ms.updates {
+		events = append(events, cc.FileContractEvents...)
+	}
+	expected := map[modules.FileContractEvent]bool{
+		{Direction: modules.DiffApply, ID: missedID, Type: modules.FileContractWindowOpened, Height: h + 3}: false,
+		{Direction: modules.DiffApply, ID: provenID, Type: modules.FileContractWindowOpened, Height: h + 3}: false,
+		{Direction: modules.DiffApply, ID: provenID, Type: modules.FileContractProofValid, Height: h + 4}:   false,
+		{Direction: modules.DiffApply, ID: missedID, Type: modules.FileContractProofMissed, Height: h + 5}:  false,
+	}
+	if len(events) != len(expected) {
+		t.Fatalf("expected %v events, got %v: %v", len(expected), len(events), events)
+	}
+	for _, event := range events {
+		seen, ok := expected[event]
+		if !ok || seen {
+			t.Fatal("unexpected event:", event)
+		}
+		expected[event] = true
+	}
+}
+
+// TestReorgTransactionIDs mines a transaction, reorgs it out of the
+// blockchain, and checks that subscribers are told which transactions were
+// reverted.
+func TestReorgTransactionIDs(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	rs := createReorgSets("TestReorgTransactionIDs")
+	defer rs.Close()
+	ms := newMockSubscriber()
+	err := rs.cstMain.cs.ConsensusSetSubscribe(&ms, modules.ConsensusChangeRecent)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Mine a transaction into cstMain.
+	txns, err := rs.cstMain.wallet.SendSiacoins(types.SiacoinPrecision, types.UnlockHash{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	txid := txns[len(txns)-1].ID()
+	_, err = rs.cstMain.miner.AddBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	applied := ms.updates[len(ms.updates)-1]
+	found := false
+	for _, id := range applied.AppliedTransactionIDs {
+		found = found || id == txid
+	}
+	if !found {
+		t.Fatal("applied transaction id was not reported")
+	}
+
+	// Reorg the transaction out of cstMain. Depending on the tie-break, the
+	// reorg may happen before the final block of cstAlt is added.
+	rs.save()
+	updatesBefore := len(ms.updates)
+	rs.extend()
+	var reorg modules.ConsensusChange
+	for _, cc := range ms.updates[updatesBefore:] {
+		if len(cc.RevertedBlocks) > 0 {
+			reorg = cc
+		}
+	}
+	if len(reorg.RevertedBlocks) == 0 {
+		t.Fatal("extending cstMain did not cause a reorg")
+	}
+	found = false
+	for _, id := range reorg.RevertedTransactionIDs {
+		found = found || id == txid
+	}
+	if !found {
+		t.Error("reverted transaction id was not reported")
+	}
+	for _, id := range reorg.AppliedTransactionIDs {
+		if id == txid {
+			t.Error("reverted transaction was reported as applied")
+		}
+	}
+
+	// The reverted ids should match the reverted blocks, in reverse order.
+	var expected []types.TransactionID
+	for _, b := range reorg.RevertedBlocks {
+		for i := len(b.Transactions) - 1; i >= 0; i-- {
+			expected = append(expected, b.Transactions[i].ID())
+		}
+	}
+	if len(expected) != len(reorg.RevertedTransactionIDs) {
+		t.Fatal("wrong number of reverted transaction ids")
+	}
+	for i := range expected {
+		if expected[i] != reorg.RevertedTransactionIDs[i] {
+			t.Fatal("reverted transaction ids are out of order")
+		}
+	}
+}
+
+// slowSubscriber is a subscriber that takes a while to process each consensus
+// change.
+type slowSubscriber struct {
+	mu      sync.Mutex
+	updates []modules.ConsensusChange
+}
+
+// ProcessConsensusChange sleeps, and then adds the consensus change to the
+// subscriber.
+func (ss *slowSubscriber) ProcessConsensusChange(cc modules.ConsensusChange) {
+	time.Sleep(100 * time.Millisecond)
+	ss.mu.Lock()
+	ss.updates = append(ss.updates, cc)
+	ss.mu.Unlock()
+}
+
+// TestSyncSubscribers accepts a block in the background and checks that
+// SyncSubscribers waits for the subscribers to process it.
+func TestSyncSubscribers(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	cst, err := createConsensusSetTester("TestSyncSubscribers")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cst.Close()
+
+	ss := new(slowSubscriber)
+	err = cst.cs.ConsensusSetSubscribe(ss, modules.ConsensusChangeRecent)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Accept a block in another goroutine, and wait for the block to become
+	// the current block. The subscriber is still sleeping at this point.
+	b, err := cst.miner.FindBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		err := cst.cs.AcceptBlock(b)
+		if err != nil {
+			t.Error(err)
+		}
+	}()
+	for i := 0; i < 100 && cst.cs.CurrentBlock().ID() != b.ID(); i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if cst.cs.CurrentBlock().ID() != b.ID() {
+		t.Fatal("block was not accepted")
+	}
+
+	cst.cs.SyncSubscribers()
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	if len(ss.updates) != 1 {
+		t.Fatal("subscriber has not processed the change, updates:", len(ss.updates))
+	}
+	if len(ss.updates[0].AppliedBlocks) != 1 || ss.updates[0].AppliedBlocks[0].ID() != b.ID() {
+		t.Error("subscriber received the wrong change")
+	}
+}
@@ -82,6 +82,34 @@ var (
 			panic("unrecognized build.Release")
 		}
 	}()
+	// peerSyncDelay is the time that threadedSyncWithPeers waits between
+	// checks of whether a peer has reported a greater height.
+	peerSyncDelay = func() time.Duration {
+		switch build.Release {
+		case "dev":
+			return 5 * time.Second
+		case "standard":
+			return 30 * time.Second
+		case "testing":
+			return 200 * time.Millisecond
+		default:
+			panic("unrecognized build.Release")
+		}
+	}()
+	// peerSyncBackoff is the time that threadedSyncWithPeers waits before
+	// requesting blocks again from a peer that sent invalid blocks.
+	peerSyncBackoff = func() time.Duration {
+		switch build.Release {
+		case "dev":
+			return 1 * time.Minute
+		case "standard":
+			return 30 * time.Minute
+		case "testing":
+			return 3 * time.Second
+		default:
+			panic("unrecognized build.Release")
+		}
+	}()
 
 	errEarlyStop         = errors.New("initial blockchain download did not complete by the time shutdown was issued")
 	errSendBlocksStalled = errors.New("SendBlocks RPC timed and never received any blocks")
@@ -206,7 +234,7 @@ func (cs *ConsensusSet) managedReceiveBlocks(conn modules.PeerConn) (returnErr e
 	for moreAvailable {
 		// Read a slice of blocks from the wire.
 		var newBlocks []types.Block
-		if err := encoding.ReadObject(conn, &newBlocks, uint64(MaxCatchUpBlocks)*types.BlockSizeLimit); err != nil {
+		if err := encoding.ReadObject(conn, &newBlocks, uint64(MaxCatchUpBlocks)*cs.blockSizeLimit); err != nil {
 			return err
 		}
 		if err := encoding.ReadObject(conn, &moreAvailable, 1); err != nil {
@@ -365,7 +393,7 @@ func (cs *ConsensusSet) rpcRelayBlock(conn modules.PeerConn) error {
 
 	// Decode the block from the connection.
 	var b types.Block
-	err = encoding.ReadObject(conn, &b, types.BlockSizeLimit)
+	err = encoding.ReadObject(conn, &b, cs.blockSizeLimit)
 	if err != nil {
 		return err
 	}
@@ -511,7 +539,7 @@ func (cs *ConsensusSet) managedReceiveBlock(id types.BlockID) modules.RPCFunc {
 			return err
 		}
 		var block types.Block
-		if err := encoding.ReadObject(conn, &block, types.BlockSizeLimit); err != nil {
+		if err := encoding.ReadObject(conn, &block, cs.blockSizeLimit); err != nil {
 			return err
 		}
 		if err := cs.managedAcceptBlock(block); err != nil {
@@ -618,6 +646,80 @@ func (cs *ConsensusSet) threadedInitialBlockchainDownload() error {
 	return nil
 }
 
+// threadedSyncWithPeers keeps the consensus set synchronized after the
+// initial blockchain download. Whenever a peer reports a greater height than
+// the local height, blocks are requested from that peer using the SendBlocks
+// RPC. SendBlocks starts from the most recent common ancestor of the two
+// chains, so blocks arrive in order and ancestors are always requested before
+// their children. Reported heights are not trusted: a peer that sends invalid
+// blocks, or that does not advance the local height despite claiming a
+// greater one, is not asked for blocks again until 'peerSyncBackoff' has
+// passed.
+func (cs *ConsensusSet) threadedSyncWithPeers() {
+	backoff := make(map[modules.NetAddress]time.Time)
+	for {
+		select {
+		case <-time.After(peerSyncDelay):
+		case <-cs.tg.StopChan():
+			return
+		}
+
+		// Forget backoffs that have expired.
+		for addr, until := range backoff {
+			if time.Now().After(until) {
+				delete(backoff, addr)
+			}
+		}
+
+		for _, p := range cs.gateway.Peers() {
+			// Each request is placed in the thread group individually, as the
+			// loop itself lives for the lifetime of the consensus set and
+			// would otherwise block calls to Flush.
+			stopped := func() bool {
+				if cs.tg.Add() != nil {
+					return true
+				}
+				defer cs.tg.Done()
+
+				reported, exists := cs.gateway.PeerHeight(p.NetAddress)
+				height := cs.managedHeight()
+				if !exists || reported <= height {
+					return false
+				}
+				if time.Now().Before(backoff[p.NetAddress]) {
+					return false
+				}
+				err := cs.gateway.RPC(p.NetAddress, "SendBlocks", cs.managedReceiveBlocks)
+				// TODO: Timeout errors returned by muxado do not conform to
+				// the net.Error interface, see
+				// threadedInitialBlockchainDownload.
+				if err != nil && err.Error() != "Read timeout" && err.Error() != "Write timeout" {
+					cs.log.Debugf("WARN: synchronizing with peer %v failed: %v", p.NetAddress, err)
+					backoff[p.NetAddress] = time.Now().Add(peerSyncBackoff)
+				} else if cs.managedHeight() <= height {
+					cs.log.Debugf("WARN: peer %v reported height %v but did not provide any blocks", p.NetAddress, reported)
+					backoff[p.NetAddress] = time.Now().Add(peerSyncBackoff)
+				}
+				return false
+			}()
+			if stopped {
+				return
+			}
+		}
+	}
+}
+
+// managedHeight returns the height of the current block.
+func (cs *ConsensusSet) managedHeight() (height types.BlockHeight) {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+	_ = cs.db.View(func(tx *bolt.Tx) error {
+		height = blockHeight(tx)
+		return nil
+	})
+	return height
+}
+
 // Synced returns true if the consensus set is synced with the network.
 func (cs *ConsensusSet) Synced() bool {
 	err := cs.tg.Add()
@@ -12,8 +12,10 @@ import (
 	"time"
 
 	"github.com/NebulousLabs/Sia/build"
+	"github.com/NebulousLabs/Sia/encoding"
 	"github.com/NebulousLabs/Sia/modules"
 	"github.com/NebulousLabs/Sia/modules/gateway"
+	"github.com/NebulousLabs/Sia/types"
 )
 
 // TestSimpleInitialBlockchainDownload tests that
@@ -485,3 +487,120 @@ func TestGenesisBlockSync(t *testing.T) {
 		t.Error("disconnection occured!")
 	}
 }
+
+// TestIntegrationSyncWithPeers checks that a node catches up automatically
+// with a connected peer that has mined ahead without broadcasting its
+// blocks, using only the heights gossiped by the gateway.
+func TestIntegrationSyncWithPeers(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	ahead, err := blankConsensusSetTester("TestIntegrationSyncWithPeers - ahead")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ahead.Close()
+	fresh, err := blankConsensusSetTester("TestIntegrationSyncWithPeers - fresh")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fresh.Close()
+	err = fresh.gateway.Connect(ahead.gateway.Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Mine blocks on the peer using managedAcceptBlock, which does not relay
+	// the blocks. More blocks are mined than fit in a single SendBlocks batch.
+	for i := types.BlockHeight(0); i < 2*MaxCatchUpBlocks+1; i++ {
+		b, err := ahead.miner.FindBlock()
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = ahead.cs.managedAcceptBlock(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The fresh node should learn the height of the peer and catch up.
+	for i := 0; i < 100; i++ {
+		if fresh.cs.CurrentBlock().ID() == ahead.cs.CurrentBlock().ID() {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	if fresh.cs.CurrentBlock().ID() != ahead.cs.CurrentBlock().ID() {
+		t.Fatal("fresh node did not catch up with its peer:", fresh.cs.Height(), ahead.cs.Height())
+	}
+}
+
+// TestIntegrationSyncWithLyingPeer checks that a peer reporting a height that
+// it cannot back up with blocks is not asked for blocks again until its
+// backoff has passed.
+func TestIntegrationSyncWithLyingPeer(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	fresh, err := blankConsensusSetTester("TestIntegrationSyncWithLyingPeer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fresh.Close()
+
+	// Create a peer that claims a large height but never sends any blocks.
+	testdir := build.TempDir(modules.ConsensusDir, "TestIntegrationSyncWithLyingPeer - liar")
+	liar, err := gateway.New("localhost:0", false, filepath.Join(testdir, "liar", modules.GatewayDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer liar.Close()
+	liar.SetBlockHeight(1e6)
+	var mu sync.Mutex
+	var requests int
+	liar.RegisterRPC("SendBlocks", func(conn modules.PeerConn) error {
+		mu.Lock()
+		requests++
+		mu.Unlock()
+		var history [32]types.BlockID
+		if err := encoding.ReadObject(conn, &history, 32*32+8); err != nil {
+			return err
+		}
+		if err := encoding.WriteObject(conn, []types.Block{}); err != nil {
+			return err
+		}
+		return encoding.WriteObject(conn, false)
+	})
+	err = fresh.gateway.Connect(liar.Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Wait for the sync loop to learn the claimed height and request blocks.
+	// The liar is also asked for blocks once when the connection is made.
+	for i := 0; i < 100; i++ {
+		mu.Lock()
+		n := requests
+		mu.Unlock()
+		if n >= 2 {
+			break
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	time.Sleep(2 * peerSyncDelay)
+	mu.Lock()
+	asked := requests
+	mu.Unlock()
+	if asked == 0 || asked > 2 {
+		t.Fatal("unexpected number of requests to the lying peer:", asked)
+	}
+
+	// The liar should not be asked again within the backoff, even though
+	// many sync rounds pass in the meantime.
+	time.Sleep(peerSyncBackoff / 2)
+	mu.Lock()
+	defer mu.Unlock()
+	if requests != asked {
+		t.Fatalf("lying peer was asked for blocks %v more times within the backoff", requests-asked)
+	}
+}
@@ -1501,3 +1501,72 @@ func TestIntegrationSendBlocksStalls(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+// TestIntegrationRelayOnlyExtendingBlocks checks that a block which extends
+// the current path is relayed to peers, while a valid block that does not
+// extend the current path is kept locally and not rebroadcast.
+func TestIntegrationRelayOnlyExtendingBlocks(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	cst1, err := blankConsensusSetTester("TestIntegrationRelayOnlyExtendingBlocks1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cst1.Close()
+	cst2, err := blankConsensusSetTester("TestIntegrationRelayOnlyExtendingBlocks2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cst2.Close()
+	err = cst1.gateway.Connect(cst2.gateway.Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Create two competing blocks on top of the current block.
+	solve := func() types.Block {
+		b, target, err := cst1.miner.BlockForWork()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for {
+			solved, ok := cst1.miner.SolveBlock(b, target)
+			if ok {
+				return solved
+			}
+		}
+	}
+	extending := solve()
+	competing := solve()
+
+	// The first block extends the current path and should reach cst2.
+	err = cst1.cs.AcceptBlock(extending)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 100; i++ {
+		if cst2.cs.CurrentBlock().ID() == extending.ID() {
+			break
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	if cst2.cs.CurrentBlock().ID() != extending.ID() {
+		t.Fatal("extending block was not relayed")
+	}
+
+	// The competing block does not extend the current path and should not be
+	// relayed.
+	err = cst1.cs.AcceptBlock(competing)
+	if err != modules.ErrNonExtendingBlock {
+		t.Fatal("expected ErrNonExtendingBlock, got", err)
+	}
+	time.Sleep(500 * time.Millisecond)
+	err = cst2.cs.db.View(func(tx *bolt.Tx) error {
+		_, err := getBlockMap(tx, competing.ID())
+		return err
+	})
+	if err != errNilItem {
+		t.Fatal("non-extending block was relayed to a peer:", err)
+	}
+}
@@ -18,7 +18,6 @@ var (
 	errInvalidStorageProof        = errors.New("provided storage proof is invalid")
 	errLateRevision               = errors.New("file contract revision submitted after deadline")
 	errLowRevisionNumber          = errors.New("transaction has a file contract with an outdated revision number")
-	errMissingSiacoinOutput       = errors.New("transaction spends a nonexisting siacoin output")
 	errMissingSiafundOutput       = errors.New("transaction spends a nonexisting siafund output")
 	errSiacoinInputOutputMismatch = errors.New("siacoin inputs do not equal siacoin outputs for transaction")
 	errSiafundInputOutputMismatch = errors.New("siafund inputs do not equal siafund outputs for transaction")
@@ -36,7 +35,7 @@ func validSiacoins(tx *bolt.Tx, t types.Transaction) error {
 		// Check that the input spends an existing output.
 		scoBytes := scoBucket.Get(sci.ParentID[:])
 		if scoBytes == nil {
-			return errMissingSiacoinOutput
+			return modules.ErrMissingSiacoinOutput
 		}
 
 		// Check that the unlock conditions match the required unlock hash.
@@ -58,7 +57,12 @@ func validSiacoins(tx *bolt.Tx, t types.Transaction) error {
 }
 
 // storageProofSegment returns the index of the segment that needs to be proven
-// exists in a file contract.
+// exists in a file contract. The index is derived from the ID of the trigger
+// block, which is the block at height WindowStart-1, the last block before
+// the proof window opens. The trigger block is not known until it has been
+// mined, so a host cannot predict which segment it will need to prove and
+// must store the whole file. The segment depends on the fork that the
+// trigger block is in, and will change if the trigger block is reorged out.
 func storageProofSegment(tx *bolt.Tx, fcid types.FileContractID) (uint64, error) {
 	// Check that the parent file contract exists.
 	fcBucket := tx.Bucket(FileContracts)
@@ -313,6 +317,23 @@ func validTransaction(tx *bolt.Tx, t types.Transaction) error {
 	return nil
 }
 
+// ValidTransaction checks that a transaction is valid in the current
+// consensus set without accepting it. The same errors are returned as when
+// the transaction is validated as part of a block.
+func (cs *ConsensusSet) ValidTransaction(txn types.Transaction) error {
+	err := cs.tg.Add()
+	if err != nil {
+		return modules.ErrClosed
+	}
+	defer cs.tg.Done()
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+
+	return cs.db.View(func(tx *bolt.Tx) error {
+		return validTransaction(tx, txn)
+	})
+}
+
 // TryTransactionSet applies the input transactions to the consensus set to
 // determine if they are valid. An error is returned IFF they are not a valid
 // set in the current consensus set. The size of the transactions and the set
@@ -321,7 +342,7 @@ func validTransaction(tx *bolt.Tx, t types.Transaction) error {
 func (cs *ConsensusSet) TryTransactionSet(txns []types.Transaction) (modules.ConsensusChange, error) {
 	err := cs.tg.Add()
 	if err != nil {
-		return modules.ConsensusChange{}, err
+		return modules.ConsensusChange{}, modules.ErrClosed
 	}
 	defer cs.tg.Done()
 	cs.mu.RLock()
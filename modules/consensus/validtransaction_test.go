@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/modules"
 	"github.com/NebulousLabs/Sia/types"
 
 	"github.com/NebulousLabs/bolt"
@@ -343,7 +344,7 @@ func TestValidSiacoins(t *testing.T) {
 	}
 	err = cst.cs.db.View(func(tx *bolt.Tx) error {
 		err := validSiacoins(tx, txn)
-		if err != errMissingSiacoinOutput {
+		if err != modules.ErrMissingSiacoinOutput {
 			t.Fatal(err)
 		}
 		return nil
@@ -421,6 +422,134 @@ func TestStorageProofSegment(t *testing.T) {
 	}
 }
 
+// TestStorageProofSegmentForks checks that the storage proof segment of a
+// file contract is stable on a given fork, and changes when the trigger block
+// of the contract is reorged out.
+func TestStorageProofSegmentForks(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	cst1, err := createConsensusSetTester("TestStorageProofSegmentForks - 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cst1.Close()
+	cst2, err := createConsensusSetTester("TestStorageProofSegmentForks - 2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cst2.Close()
+
+	// copyBlocks submits the current path of one consensus set to another.
+	copyBlocks := func(from, to *consensusSetTester) {
+		for i := types.BlockHeight(1); i <= from.cs.dbBlockHeight(); i++ {
+			id, err := from.cs.dbGetPath(i)
+			if err != nil {
+				t.Fatal(err)
+			}
+			pb, err := from.cs.dbGetBlockMap(id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// err is not checked - the block may already be known.
+			_ = to.cs.AcceptBlock(pb.Block)
+		}
+		if from.cs.dbCurrentBlockID() != to.cs.dbCurrentBlockID() {
+			t.Fatal("consensus sets did not synchronize")
+		}
+	}
+
+	// Create a file contract in cst1 and share it with cst2. A large file size
+	// makes it very unlikely that two trigger blocks pick the same segment.
+	payout := types.NewCurrency64(400e6)
+	fc := types.FileContract{
+		FileSize:    1 << 40,
+		WindowStart: cst1.cs.dbBlockHeight() + 4,
+		WindowEnd:   cst1.cs.dbBlockHeight() + 10,
+		Payout:      payout,
+		ValidProofOutputs: []types.SiacoinOutput{{
+			Value: types.PostTax(cst1.cs.dbBlockHeight(), payout),
+		}},
+		MissedProofOutputs: []types.SiacoinOutput{{
+			Value: types.PostTax(cst1.cs.dbBlockHeight(), payout),
+		}},
+	}
+	txnBuilder := cst1.wallet.StartTransaction()
+	err = txnBuilder.FundSiacoins(payout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fcIndex := txnBuilder.AddFileContract(fc)
+	txnSet, err := txnBuilder.Sign(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = cst1.tpool.AcceptTransactionSet(txnSet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = cst1.miner.AddBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fcid := txnSet[len(txnSet)-1].FileContractID(fcIndex)
+	copyBlocks(cst1, cst2)
+
+	// Mine past the trigger block independently in each consensus set.
+	for cst1.cs.dbBlockHeight() < fc.WindowStart-1 {
+		_, err = cst1.miner.AddBlock()
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = cst2.miner.AddBlock()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	segment1, err := cst1.cs.StorageProofSegment(fcid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	segment2, err := cst2.cs.StorageProofSegment(fcid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if segment1 == segment2 {
+		t.Fatal("different trigger blocks produced the same segment")
+	}
+
+	// The segment should not change as more blocks are added to the fork.
+	_, err = cst1.miner.AddBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	segment, err := cst1.cs.StorageProofSegment(fcid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if segment != segment1 {
+		t.Fatal("segment changed without a reorg")
+	}
+
+	// Reorg cst1 onto the fork of cst2, the segment should follow the new
+	// trigger block.
+	for cst2.cs.dbBlockHeight() <= cst1.cs.dbBlockHeight() {
+		_, err = cst2.miner.AddBlock()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	copyBlocks(cst2, cst1)
+	segment, err = cst1.cs.StorageProofSegment(fcid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if segment != segment2 {
+		t.Fatal("segment did not change after reorging the trigger block")
+	}
+}
+
 // TestValidStorageProofs probes the validStorageProofs method of the consensus
 // set.
 func TestValidStorageProofs(t *testing.T) {
@@ -856,3 +985,96 @@ func TestValidTransaction(t *testing.T) {
 	}
 }
 */
+
+// TestValidTransactionWithoutAccepting checks balanced, unbalanced, and
+// badly signed transactions against ValidTransaction, and that validating a
+// transaction does not change the consensus set.
+func TestValidTransactionWithoutAccepting(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	cst, err := createConsensusSetTester("TestValidTransactionWithoutAccepting")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cst.Close()
+
+	// Fund an address controlled by a fresh key.
+	sk, pk, err := crypto.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	uc := types.UnlockConditions{
+		PublicKeys: []types.SiaPublicKey{{
+			Algorithm: types.SignatureEd25519,
+			Key:       pk[:],
+		}},
+		SignaturesRequired: 1,
+	}
+	funding := types.NewCurrency64(1e6)
+	txns, err := cst.wallet.SendSiacoins(funding, uc.UnlockHash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var parentID types.SiacoinOutputID
+	for _, txn := range txns {
+		for i, sco := range txn.SiacoinOutputs {
+			if sco.UnlockHash == uc.UnlockHash() {
+				parentID = txn.SiacoinOutputID(uint64(i))
+			}
+		}
+	}
+	_, err = cst.miner.AddBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// createTxn creates a signed transaction spending the funded output and
+	// creating an output of the given value.
+	createTxn := func(value types.Currency) types.Transaction {
+		txn := types.Transaction{
+			SiacoinInputs: []types.SiacoinInput{{
+				ParentID:         parentID,
+				UnlockConditions: uc,
+			}},
+			SiacoinOutputs: []types.SiacoinOutput{{Value: value}},
+			TransactionSignatures: []types.TransactionSignature{{
+				ParentID:       crypto.Hash(parentID),
+				CoveredFields:  types.CoveredFields{WholeTransaction: true},
+				PublicKeyIndex: 0,
+			}},
+		}
+		sig, err := crypto.SignHash(txn.SigHash(0), sk)
+		if err != nil {
+			t.Fatal(err)
+		}
+		txn.TransactionSignatures[0].Signature = sig[:]
+		return txn
+	}
+	initialHash := cst.cs.dbConsensusChecksum()
+
+	// A balanced transaction is valid.
+	err = cst.cs.ValidTransaction(createTxn(funding))
+	if err != nil {
+		t.Error("balanced transaction was rejected:", err)
+	}
+
+	// An unbalanced transaction is rejected.
+	err = cst.cs.ValidTransaction(createTxn(funding.Add(types.NewCurrency64(1))))
+	if err != errSiacoinInputOutputMismatch {
+		t.Error("expected errSiacoinInputOutputMismatch, got", err)
+	}
+
+	// A transaction with a bad signature is rejected.
+	txn := createTxn(funding)
+	txn.TransactionSignatures[0].Signature[0]++
+	err = cst.cs.ValidTransaction(txn)
+	if err != crypto.ErrInvalidSignature {
+		t.Error("expected crypto.ErrInvalidSignature, got", err)
+	}
+
+	if cst.cs.dbConsensusChecksum() != initialHash {
+		t.Error("ValidTransaction changed the consensus set")
+	}
+}
@@ -71,6 +71,10 @@ type (
 		// provided unlock hash.
 		UnlockHash(types.UnlockHash) []types.TransactionID
 
+		// UnspentOutputs returns the IDs of all of the siacoin outputs that
+		// are currently spendable by the provided unlock hash.
+		UnspentOutputs(types.UnlockHash) []types.SiacoinOutputID
+
 		// SiacoinOutput will return the siacoin output associated with the
 		// input id.
 		SiacoinOutput(types.SiacoinOutputID) (types.SiacoinOutput, bool)
@@ -25,6 +25,7 @@ var (
 	bucketSiafundOutputs        = []byte("SiafundOutputs")
 	bucketTransactionIDs        = []byte("TransactionIDs")
 	bucketUnlockHashes          = []byte("UnlockHashes")
+	bucketUnspentOutputs        = []byte("UnspentOutputs")
 
 	// bucketInternal is used to store values internal to the explorer
 	bucketInternal = []byte("Internal")
@@ -90,6 +91,33 @@ func dbGetTransactionIDSet(bucket []byte, key interface{}, ids *[]types.Transact
 	}
 }
 
+// dbGetUnspentOutputs returns a 'func(*bolt.Tx) error' that decodes the
+// unspent siacoin outputs of an unlock hash into a slice. If the unlock hash
+// has no unspent outputs, dbGetUnspentOutputs returns errNotExist.
+func dbGetUnspentOutputs(uh types.UnlockHash, ids *[]types.SiacoinOutputID) func(*bolt.Tx) error {
+	return func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketUnspentOutputs).Bucket(encoding.Marshal(uh))
+		if b == nil {
+			return errNotExist
+		}
+		var scoids []types.SiacoinOutputID
+		err := b.ForEach(func(scoid, _ []byte) error {
+			var id types.SiacoinOutputID
+			err := encoding.Unmarshal(scoid, &id)
+			if err != nil {
+				return err
+			}
+			scoids = append(scoids, id)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		*ids = scoids
+		return nil
+	}
+}
+
 // dbGetBlockFacts returns a 'func(*bolt.Tx) error' that decodes
 // the block facts for `height` into blockfacts
 func (e *Explorer) dbGetBlockFacts(height types.BlockHeight, bf *blockFacts) func(*bolt.Tx) error {
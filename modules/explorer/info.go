@@ -82,6 +82,18 @@ func (e *Explorer) UnlockHash(uh types.UnlockHash) []types.TransactionID {
 	return ids
 }
 
+// UnspentOutputs returns the IDs of the siacoin outputs that are currently
+// spendable by the unlock hash. An empty set indicates that the unlock hash
+// has no unspent outputs.
+func (e *Explorer) UnspentOutputs(uh types.UnlockHash) []types.SiacoinOutputID {
+	var ids []types.SiacoinOutputID
+	err := e.db.View(dbGetUnspentOutputs(uh, &ids))
+	if err != nil {
+		ids = nil
+	}
+	return ids
+}
+
 // SiacoinOutput returns the siacoin output associated with the specified ID.
 func (e *Explorer) SiacoinOutput(id types.SiacoinOutputID) (types.SiacoinOutput, bool) {
 	var sco types.SiacoinOutput
@@ -1,9 +1,16 @@
 package explorer
 
 import (
+	"path/filepath"
 	"testing"
 
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/encoding"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/persist"
 	"github.com/NebulousLabs/Sia/types"
+
+	"github.com/NebulousLabs/bolt"
 )
 
 // TestImmediateBlockFacts grabs the block facts object from the block explorer
@@ -72,3 +79,238 @@ func TestBlockFacts(t *testing.T) {
 		t.Error("call to 'BlockFacts' has failed")
 	}
 }
+
+// TestTransactionAndUnlockHash mines a transaction and checks that it can be
+// found by its ID and by the unlock hash it sends coins to, and that both
+// lookups are removed when the block is reverted.
+func TestTransactionAndUnlockHash(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	et, err := createExplorerTester("TestTransactionAndUnlockHash")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Send coins to an address that is not used anywhere else and mine the
+	// transaction into a block.
+	uh := types.UnlockHash{1, 2, 3}
+	txns, err := et.wallet.SendSiacoins(types.NewCurrency64(1e6), uh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	txid := txns[len(txns)-1].ID()
+	b, _ := et.miner.FindBlock()
+	err = et.cs.AcceptBlock(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	block, height, exists := et.explorer.Transaction(txid)
+	if !exists {
+		t.Fatal("mined transaction was not found")
+	}
+	if block.ID() != b.ID() || height != et.cs.Height() {
+		t.Error("transaction lookup returned the wrong block:", height, et.cs.Height())
+	}
+	found := false
+	for _, txn := range block.Transactions {
+		if txn.ID() == txid {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("block returned by the transaction lookup does not contain the transaction")
+	}
+	ids := et.explorer.UnlockHash(uh)
+	if len(ids) != 1 || ids[0] != txid {
+		t.Fatal("unlock hash lookup did not return the transaction:", ids)
+	}
+
+	// Reorg to a chain that does not contain the transaction.
+	err = et.reorgToBlank()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, exists := et.explorer.Block(b.ID()); exists {
+		t.Error("reverted block is still indexed")
+	}
+	if _, _, exists := et.explorer.Transaction(txid); exists {
+		t.Error("transaction of a reverted block is still indexed")
+	}
+	if ids := et.explorer.UnlockHash(uh); len(ids) != 0 {
+		t.Error("unlock hash still lists transactions of a reverted block:", ids)
+	}
+}
+
+// TestUnspentOutputs funds an address, spends part of the funds, and checks
+// that the unspent outputs of the address are updated accordingly.
+func TestUnspentOutputs(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	et, err := createExplorerTester("TestUnspentOutputs")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Fund an address controlled by a fresh key.
+	sk, pk, err := crypto.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	uc := types.UnlockConditions{
+		PublicKeys: []types.SiaPublicKey{{
+			Algorithm: types.SignatureEd25519,
+			Key:       pk[:],
+		}},
+		SignaturesRequired: 1,
+	}
+	uh := uc.UnlockHash()
+	if ids := et.explorer.UnspentOutputs(uh); len(ids) != 0 {
+		t.Fatal("unfunded address has unspent outputs:", ids)
+	}
+	funding := types.NewCurrency64(1e6)
+	txns, err := et.wallet.SendSiacoins(funding, uh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fundingID types.SiacoinOutputID
+	for _, txn := range txns {
+		for i, sco := range txn.SiacoinOutputs {
+			if sco.UnlockHash == uh {
+				fundingID = txn.SiacoinOutputID(uint64(i))
+			}
+		}
+	}
+	_, err = et.miner.AddBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := et.explorer.UnspentOutputs(uh)
+	if len(ids) != 1 || ids[0] != fundingID {
+		t.Fatal("funded output is not listed as unspent:", ids)
+	}
+
+	// Spend part of the output, sending the change back to the address.
+	txn := types.Transaction{
+		SiacoinInputs: []types.SiacoinInput{{
+			ParentID:         fundingID,
+			UnlockConditions: uc,
+		}},
+		SiacoinOutputs: []types.SiacoinOutput{
+			{Value: types.NewCurrency64(4e5), UnlockHash: types.UnlockHash{}},
+			{Value: types.NewCurrency64(6e5), UnlockHash: uh},
+		},
+		TransactionSignatures: []types.TransactionSignature{{
+			ParentID:       crypto.Hash(fundingID),
+			CoveredFields:  types.CoveredFields{WholeTransaction: true},
+			PublicKeyIndex: 0,
+		}},
+	}
+	sig, err := crypto.SignHash(txn.SigHash(0), sk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	txn.TransactionSignatures[0].Signature = sig[:]
+	err = et.tpool.AcceptTransactionSet([]types.Transaction{txn})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = et.miner.AddBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids = et.explorer.UnspentOutputs(uh)
+	if len(ids) != 1 || ids[0] != txn.SiacoinOutputID(1) {
+		t.Fatal("unspent outputs were not updated after spending:", ids)
+	}
+	sco, exists := et.explorer.SiacoinOutput(ids[0])
+	if !exists || sco.Value.Cmp(types.NewCurrency64(6e5)) != 0 {
+		t.Error("change output has the wrong value:", sco.Value)
+	}
+}
+
+// TestUnspentOutputsUpgrade opens an explorer database that predates the
+// unspent outputs bucket, and checks that the explorer rebuilds the bucket
+// and that removing unknown outputs does not disrupt updates.
+func TestUnspentOutputsUpgrade(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	et, err := createExplorerTester("TestUnspentOutputsUpgrade")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Fund an address that the wallet does not control.
+	uh := types.UnlockHash{1, 2, 3}
+	txns, err := et.wallet.SendSiacoins(types.NewCurrency64(1e6), uh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fundingID types.SiacoinOutputID
+	for _, txn := range txns {
+		for i, sco := range txn.SiacoinOutputs {
+			if sco.UnlockHash == uh {
+				fundingID = txn.SiacoinOutputID(uint64(i))
+			}
+		}
+	}
+	_, err = et.miner.AddBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Close the explorer and remove the unspent outputs bucket, as if the
+	// database had been created by an older version.
+	et.cs.Unsubscribe(et.explorer)
+	err = et.explorer.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	explorerDir := filepath.Join(et.testdir, modules.ExplorerDir)
+	db, err := persist.OpenDatabase(explorerMetadata, filepath.Join(explorerDir, "explorer.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		return tx.DeleteBucket(bucketUnspentOutputs)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = db.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The reopened explorer should rebuild the unspent outputs.
+	e, err := New(et.cs, explorerDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer e.Close()
+	ids := e.UnspentOutputs(uh)
+	if len(ids) != 1 || ids[0] != fundingID {
+		t.Fatal("unspent outputs were not rebuilt after the upgrade:", ids)
+	}
+	if e.LatestBlockFacts().Height != et.cs.Height() {
+		t.Error("explorer did not catch up with the consensus set after the upgrade")
+	}
+
+	// Removing unknown outputs should be harmless, and removing the last
+	// output of an unlock hash should delete its bucket.
+	err = e.db.Update(func(tx *bolt.Tx) error {
+		dbRemoveUnspentOutput(tx, types.UnlockHash{4, 5, 6}, fundingID)
+		dbRemoveUnspentOutput(tx, uh, types.SiacoinOutputID{})
+		dbRemoveUnspentOutput(tx, uh, fundingID)
+		if tx.Bucket(bucketUnspentOutputs).Bucket(encoding.Marshal(uh)) != nil {
+			t.Error("empty unspent outputs bucket was not deleted")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
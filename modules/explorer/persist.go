@@ -48,7 +48,26 @@ func (e *Explorer) initPersist() error {
 			bucketSiafundOutputs,
 			bucketTransactionIDs,
 			bucketUnlockHashes,
+			bucketUnspentOutputs,
 		}
+
+		// Databases created before the unspent outputs bucket was added
+		// cannot be upgraded in place, because the bucket can only be filled
+		// by processing every block. Such a database is cleared, so that the
+		// explorer subscribes from the beginning of the blockchain and
+		// rebuilds every bucket.
+		if tx.Bucket(bucketInternal) != nil && tx.Bucket(bucketUnspentOutputs) == nil {
+			for _, b := range buckets {
+				if tx.Bucket(b) == nil {
+					continue
+				}
+				err := tx.DeleteBucket(b)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
 		for _, b := range buckets {
 			_, err := tx.CreateBucketIfNotExists(b)
 			if err != nil {
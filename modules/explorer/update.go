@@ -222,6 +222,17 @@ func (e *Explorer) ProcessConsensusChange(cc modules.ConsensusChange) {
 			}
 		}
 
+		// Update the unspent outputs of each unlock hash. The diffs cover
+		// every way an output can be created or spent, including delayed
+		// outputs such as miner payouts and storage proof outputs.
+		for _, diff := range cc.SiacoinOutputDiffs {
+			if diff.Direction == modules.DiffApply {
+				dbAddUnspentOutput(tx, diff.SiacoinOutput.UnlockHash, diff.ID)
+			} else {
+				dbRemoveUnspentOutput(tx, diff.SiacoinOutput.UnlockHash, diff.ID)
+			}
+		}
+
 		// Compute the changes in the active set. Note, because this is calculated
 		// at the end instead of in a loop, the historic facts may contain
 		// inaccuracies about the active set. This should not be a problem except
@@ -416,6 +427,27 @@ func dbRemoveUnlockHash(tx *bolt.Tx, uh types.UnlockHash, txid types.Transaction
 	mustDelete(tx.Bucket(bucketUnlockHashes).Bucket(encoding.Marshal(uh)), txid)
 }
 
+// Add/Remove siacoin output ID from unspent outputs bucket
+func dbAddUnspentOutput(tx *bolt.Tx, uh types.UnlockHash, id types.SiacoinOutputID) {
+	b, err := tx.Bucket(bucketUnspentOutputs).CreateBucketIfNotExists(encoding.Marshal(uh))
+	assertNil(err)
+	mustPutSet(b, id)
+}
+func dbRemoveUnspentOutput(tx *bolt.Tx, uh types.UnlockHash, id types.SiacoinOutputID) {
+	// A missing bucket or output is ignored rather than treated as a
+	// corruption, so that an output that was never recorded cannot stall the
+	// explorer.
+	unspent := tx.Bucket(bucketUnspentOutputs)
+	b := unspent.Bucket(encoding.Marshal(uh))
+	if b == nil {
+		return
+	}
+	mustDelete(b, id)
+	if k, _ := b.Cursor().First(); k == nil {
+		assertNil(unspent.DeleteBucket(encoding.Marshal(uh)))
+	}
+}
+
 func dbCalculateBlockFacts(tx *bolt.Tx, cs modules.ConsensusSet, block types.Block) blockFacts {
 	// get the parent block facts
 	var bf blockFacts
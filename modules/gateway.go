@@ -4,6 +4,7 @@ import (
 	"net"
 
 	"github.com/NebulousLabs/Sia/build"
+	"github.com/NebulousLabs/Sia/types"
 )
 
 const (
@@ -179,6 +180,28 @@ type (
 		// given peers in parallel.
 		Broadcast(name string, obj interface{}, peers []Peer)
 
+		// SetRateLimit limits the combined download and upload throughput of
+		// the gateway's peer connections, in bytes per second. A limit of 0
+		// means unlimited.
+		SetRateLimit(downBytesPerSec, upBytesPerSec int64)
+
+		// SetBlockHeight sets the block height of the local consensus set,
+		// which the gateway shares with its peers.
+		SetBlockHeight(types.BlockHeight)
+
+		// BestPeerHeight returns the highest block height reported by any of
+		// the connected peers.
+		BestPeerHeight() types.BlockHeight
+
+		// PeerHeight returns the block height most recently reported by a
+		// connected peer, and whether the peer has reported a height.
+		PeerHeight(NetAddress) (types.BlockHeight, bool)
+
+		// NetworkTime returns the local time adjusted by the median clock
+		// offset reported by peers. The adjustment is bounded, so that peers
+		// cannot move the clock arbitrarily far.
+		NetworkTime() types.Timestamp
+
 		// Close safely stops the Gateway's listener process.
 		Close() error
 	}
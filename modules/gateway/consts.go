@@ -20,6 +20,10 @@ const (
 	// connect to itself, this number can be reduced.
 	maxLocalOutboundPeers = 3
 
+	// maxTimeSamples is the maximum number of peer time samples that the
+	// gateway keeps to compute the network-adjusted time.
+	maxTimeSamples = 200
+
 	// minAcceptableVersion is the version below which the gateway will refuse to
 	// connect to peers and reject connection attempts.
 	//
@@ -89,6 +93,51 @@ var (
 		}
 	}()
 
+	// heightGossipDelay defines the amount of time that is waited between
+	// each round of requesting the block heights of peers.
+	heightGossipDelay = func() time.Duration {
+		switch build.Release {
+		case "dev":
+			return 10 * time.Second
+		case "standard":
+			return 1 * time.Minute
+		case "testing":
+			return 500 * time.Millisecond
+		default:
+			panic("unrecognized build.Release in heightGossipDelay")
+		}
+	}()
+
+	// maxTimeOffset is the largest adjustment, in seconds, that the
+	// network-adjusted time may apply to the local clock.
+	maxTimeOffset = func() int64 {
+		switch build.Release {
+		case "dev":
+			return 60
+		case "standard":
+			return 70 * 60
+		case "testing":
+			return 2
+		default:
+			panic("unrecognized build.Release in maxTimeOffset")
+		}
+	}()
+
+	// minTimeSamples is the number of peer time samples that the gateway must
+	// have before it adjusts the local clock.
+	minTimeSamples = func() int {
+		switch build.Release {
+		case "dev":
+			return 3
+		case "standard":
+			return 5
+		case "testing":
+			return 3
+		default:
+			panic("unrecognized build.Release in minTimeSamples")
+		}
+	}()
+
 	// pruneNodeListLen defines the number of nodes that the gateway must have
 	// to be pruning nodes from the node list.
 	pruneNodeListLen = func() int {
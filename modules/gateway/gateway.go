@@ -149,6 +149,7 @@ import (
 	"github.com/NebulousLabs/Sia/modules"
 	"github.com/NebulousLabs/Sia/persist"
 	siasync "github.com/NebulousLabs/Sia/sync"
+	"github.com/NebulousLabs/Sia/types"
 )
 
 var (
@@ -187,6 +188,21 @@ type Gateway struct {
 	peers  map[modules.NetAddress]*peer
 	peerTG siasync.ThreadGroup
 
+	// blockHeight is the height of the local consensus set, which is shared
+	// with peers. peerHeights are the most recent heights reported by peers.
+	blockHeight types.BlockHeight
+	peerHeights map[modules.NetAddress]types.BlockHeight
+
+	// peerTimeOffsets are the differences, in seconds, between the times
+	// reported by peers and the local time. They are used to compute the
+	// network-adjusted time.
+	peerTimeOffsets map[modules.NetAddress]int64
+
+	// downLimit and upLimit throttle the combined throughput of all peer
+	// connections.
+	downLimit rateLimiter
+	upLimit   rateLimiter
+
 	// Utilities.
 	log        *persist.Logger
 	mu         sync.RWMutex
@@ -238,6 +254,9 @@ func New(addr string, bootstrap bool, persistDir string) (*Gateway, error) {
 		peers: make(map[modules.NetAddress]*peer),
 		nodes: make(map[modules.NetAddress]struct{}),
 
+		peerHeights:     make(map[modules.NetAddress]types.BlockHeight),
+		peerTimeOffsets: make(map[modules.NetAddress]int64),
+
 		persistDir: persistDir,
 	}
 
@@ -267,10 +286,18 @@ func New(addr string, bootstrap bool, persistDir string) (*Gateway, error) {
 	// Register RPCs.
 	g.RegisterRPC("ShareNodes", g.shareNodes)
 	g.RegisterConnectCall("ShareNodes", g.requestNodes)
+	g.RegisterRPC("ShareHeight", g.shareHeight)
+	g.RegisterConnectCall("ShareHeight", g.requestHeight)
+	g.RegisterRPC("ShareTime", g.shareTime)
+	g.RegisterConnectCall("ShareTime", g.requestTime)
 	// Establish the de-registration of the RPCs.
 	g.threads.OnStop(func() {
 		g.UnregisterRPC("ShareNodes")
 		g.UnregisterConnectCall("ShareNodes")
+		g.UnregisterRPC("ShareHeight")
+		g.UnregisterConnectCall("ShareHeight")
+		g.UnregisterRPC("ShareTime")
+		g.UnregisterConnectCall("ShareTime")
 	})
 
 	// Load the old node list. If it doesn't exist, no problem, but if it does,
@@ -336,6 +363,13 @@ func New(addr string, bootstrap bool, persistDir string) (*Gateway, error) {
 	})
 	go g.permanentNodePurger(nodePurgerClosedChan)
 
+	// Spawn the height gossip and provide tools for ensuring clean shutdown.
+	heightGossipClosedChan := make(chan struct{})
+	g.threads.OnStop(func() {
+		<-heightGossipClosedChan
+	})
+	go g.permanentHeightGossip(heightGossipClosedChan)
+
 	// Spawn threads to take care of port forwarding and hostname discovery.
 	go g.threadedForwardPort(g.port)
 	go g.threadedLearnHostname()
@@ -0,0 +1,114 @@
+package gateway
+
+import (
+	"time"
+
+	"github.com/NebulousLabs/Sia/encoding"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// shareHeight is an RPC that sends the block height of the gateway to the
+// requesting peer.
+func (g *Gateway) shareHeight(conn modules.PeerConn) error {
+	g.mu.RLock()
+	height := g.blockHeight
+	g.mu.RUnlock()
+	return encoding.WriteObject(conn, height)
+}
+
+// requestHeight is the calling end of the ShareHeight RPC. The height of the
+// peer is recorded so that it can be used to estimate the height of the
+// network.
+func (g *Gateway) requestHeight(conn modules.PeerConn) error {
+	var height types.BlockHeight
+	if err := encoding.ReadObject(conn, &height, 8); err != nil {
+		return err
+	}
+	g.mu.Lock()
+	g.peerHeights[conn.RPCAddr()] = height
+	g.mu.Unlock()
+	return nil
+}
+
+// permanentHeightGossip periodically requests the block height and the time
+// of every peer.
+func (g *Gateway) permanentHeightGossip(closeChan chan struct{}) {
+	defer close(closeChan)
+
+	for {
+		select {
+		case <-time.After(heightGossipDelay):
+		case <-g.threads.StopChan():
+			// The gateway is shutting down, close out the thread.
+			return
+		}
+
+		g.mu.Lock()
+		peers := make([]modules.NetAddress, 0, len(g.peers))
+		for addr := range g.peers {
+			peers = append(peers, addr)
+		}
+		// Forget the heights and clock offsets of peers that have
+		// disconnected.
+		for addr := range g.peerHeights {
+			if _, exists := g.peers[addr]; !exists {
+				delete(g.peerHeights, addr)
+			}
+		}
+		for addr := range g.peerTimeOffsets {
+			if _, exists := g.peers[addr]; !exists {
+				delete(g.peerTimeOffsets, addr)
+			}
+		}
+		g.mu.Unlock()
+
+		for _, addr := range peers {
+			err := g.managedRPC(addr, "ShareHeight", g.requestHeight)
+			if err != nil {
+				g.log.Debugf("WARN: RPC ShareHeight failed on peer %q: %v", addr, err)
+			}
+			err = g.managedRPC(addr, "ShareTime", g.requestTime)
+			if err != nil {
+				g.log.Debugf("WARN: RPC ShareTime failed on peer %q: %v", addr, err)
+			}
+		}
+	}
+}
+
+// SetBlockHeight sets the block height that the gateway shares with its
+// peers. It is called by the consensus set whenever its height changes.
+func (g *Gateway) SetBlockHeight(height types.BlockHeight) {
+	g.mu.Lock()
+	g.blockHeight = height
+	g.mu.Unlock()
+}
+
+// BestPeerHeight returns the highest block height reported by any of the
+// gateway's connected peers. Comparing it to the local height indicates
+// whether the node has caught up with the network.
+func (g *Gateway) BestPeerHeight() types.BlockHeight {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	var best types.BlockHeight
+	for addr, height := range g.peerHeights {
+		if _, connected := g.peers[addr]; connected && height > best {
+			best = height
+		}
+	}
+	return best
+}
+
+// PeerHeight returns the block height most recently reported by the connected
+// peer 'addr', and whether the peer has reported a height. Heights are
+// reported by peers without proof, so they should only be trusted once the
+// peer has provided the blocks.
+func (g *Gateway) PeerHeight(addr modules.NetAddress) (types.BlockHeight, bool) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	if _, connected := g.peers[addr]; !connected {
+		return 0, false
+	}
+	height, exists := g.peerHeights[addr]
+	return height, exists
+}
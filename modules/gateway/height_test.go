@@ -0,0 +1,63 @@
+package gateway
+
+import (
+	"testing"
+	"time"
+
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestBestPeerHeight connects a gateway to a peer that is further ahead and
+// checks that the gateway learns the height of the peer, including updates
+// to the height after the connection was made.
+func TestBestPeerHeight(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	behind := newTestingGateway("TestBestPeerHeight1", t)
+	defer behind.Close()
+	ahead := newTestingGateway("TestBestPeerHeight2", t)
+	defer ahead.Close()
+	behind.SetBlockHeight(5)
+	ahead.SetBlockHeight(20)
+
+	if behind.BestPeerHeight() != 0 {
+		t.Fatal("gateway without peers should report a best peer height of 0")
+	}
+	err := behind.Connect(ahead.Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// waitForHeight waits until the best peer height of 'g' is 'height'.
+	waitForHeight := func(g *Gateway, height types.BlockHeight) {
+		for i := 0; i < 50; i++ {
+			if g.BestPeerHeight() == height {
+				return
+			}
+			time.Sleep(50 * time.Millisecond)
+		}
+		t.Fatalf("expected best peer height %v, got %v", height, g.BestPeerHeight())
+	}
+	waitForHeight(behind, 20)
+	waitForHeight(ahead, 5)
+
+	// The height is gossiped periodically, so changes should propagate.
+	ahead.SetBlockHeight(30)
+	waitForHeight(behind, 30)
+	if height, exists := behind.PeerHeight(ahead.Address()); !exists || height != 30 {
+		t.Fatal("wrong height reported for the peer:", height, exists)
+	}
+
+	// Heights of disconnected peers are not considered.
+	err = behind.Disconnect(ahead.Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if behind.BestPeerHeight() != 0 {
+		t.Fatal("height of a disconnected peer is still reported:", behind.BestPeerHeight())
+	}
+	if _, exists := behind.PeerHeight(ahead.Address()); exists {
+		t.Fatal("height of a disconnected peer is still reported by PeerHeight")
+	}
+}
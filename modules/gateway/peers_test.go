@@ -251,6 +251,66 @@ func TestConnect(t *testing.T) {
 	g.mu.RUnlock()
 }
 
+// TestConnectListsPeers connects two gateways and checks that each lists the
+// other as a peer with an acceptable version, and that disconnecting removes
+// the peer from both lists.
+func TestConnectListsPeers(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	g1 := newTestingGateway("TestConnectListsPeers1", t)
+	defer g1.Close()
+	g2 := newTestingGateway("TestConnectListsPeers2", t)
+	defer g2.Close()
+
+	err := g1.Connect(g2.Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// hasPeer returns the version of the peer with address 'addr', if any.
+	hasPeer := func(g *Gateway, addr modules.NetAddress) (string, bool) {
+		for _, p := range g.Peers() {
+			if p.NetAddress == addr {
+				return p.Version, true
+			}
+		}
+		return "", false
+	}
+	version, ok := hasPeer(g1, g2.Address())
+	if !ok {
+		t.Fatal("connecting gateway does not list its peer")
+	} else if version != build.Version {
+		t.Fatal("connecting gateway recorded the wrong version:", version)
+	}
+	// The accepting side adds the peer asynchronously.
+	for i := 0; i < 50; i++ {
+		if _, ok = hasPeer(g2, g1.Address()); ok {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if !ok {
+		t.Fatal("accepting gateway does not list its peer")
+	}
+
+	err = g1.Disconnect(g2.Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := hasPeer(g1, g2.Address()); ok {
+		t.Fatal("peer is still listed after disconnecting")
+	}
+	for i := 0; i < 50; i++ {
+		if _, ok = hasPeer(g2, g1.Address()); !ok {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if ok {
+		t.Fatal("remote gateway still lists the peer after disconnecting")
+	}
+}
+
 // TestUnitAcceptableVersion tests that the acceptableVersion func returns an
 // error for unacceptable versions.
 func TestUnitAcceptableVersion(t *testing.T) {
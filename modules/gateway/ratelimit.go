@@ -0,0 +1,92 @@
+package gateway
+
+import (
+	"sync"
+	"time"
+
+	"github.com/NebulousLabs/Sia/modules"
+)
+
+// rateLimiter limits the combined throughput of all connections that share
+// it. Each transfer reserves a slot of time proportional to its size, and
+// waits until the slot has passed, so that on average no more than 'bps'
+// bytes are transferred per second.
+type rateLimiter struct {
+	bps  int64
+	next time.Time
+	mu   sync.Mutex
+}
+
+// setLimit sets the limit of the rateLimiter in bytes per second. A limit of
+// 0 means unlimited.
+func (rl *rateLimiter) setLimit(bps int64) {
+	rl.mu.Lock()
+	rl.bps = bps
+	rl.next = time.Time{}
+	rl.mu.Unlock()
+}
+
+// wait blocks until the transfer of 'n' bytes fits within the limit, or until
+// 'stop' is closed.
+func (rl *rateLimiter) wait(n int, stop <-chan struct{}) {
+	rl.mu.Lock()
+	if rl.bps <= 0 || n <= 0 {
+		rl.mu.Unlock()
+		return
+	}
+	now := time.Now()
+	if rl.next.Before(now) {
+		rl.next = now
+	}
+	rl.next = rl.next.Add(time.Duration(int64(n) * int64(time.Second) / rl.bps))
+	sleep := rl.next.Sub(now)
+	rl.mu.Unlock()
+	select {
+	case <-time.After(sleep):
+	case <-stop:
+	}
+}
+
+// rateLimitedConn is a modules.PeerConn whose reads and writes are throttled
+// by the gateway's download and upload rate limiters.
+type rateLimitedConn struct {
+	modules.PeerConn
+	down *rateLimiter
+	up   *rateLimiter
+	stop <-chan struct{}
+}
+
+// Read reads from the underlying connection, then waits until the bytes read
+// fit within the download limit.
+func (rlc *rateLimitedConn) Read(b []byte) (int, error) {
+	n, err := rlc.PeerConn.Read(b)
+	rlc.down.wait(n, rlc.stop)
+	return n, err
+}
+
+// Write writes to the underlying connection, then waits until the bytes
+// written fit within the upload limit.
+func (rlc *rateLimitedConn) Write(b []byte) (int, error) {
+	n, err := rlc.PeerConn.Write(b)
+	rlc.up.wait(n, rlc.stop)
+	return n, err
+}
+
+// rateLimitConn wraps a peer connection in the gateway's rate limits. Waiting
+// for the limits is interrupted when the gateway shuts down, so that a low
+// limit cannot stall Close.
+func (g *Gateway) rateLimitConn(conn modules.PeerConn) modules.PeerConn {
+	return &rateLimitedConn{
+		PeerConn: conn,
+		down:     &g.downLimit,
+		up:       &g.upLimit,
+		stop:     g.threads.StopChan(),
+	}
+}
+
+// SetRateLimit limits the combined throughput of the gateway's connections
+// to peers, in bytes per second. A limit of 0 means unlimited.
+func (g *Gateway) SetRateLimit(downBytesPerSec, upBytesPerSec int64) {
+	g.downLimit.setLimit(downBytesPerSec)
+	g.upLimit.setLimit(upBytesPerSec)
+}
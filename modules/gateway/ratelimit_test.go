@@ -0,0 +1,114 @@
+package gateway
+
+import (
+	"testing"
+	"time"
+
+	"github.com/NebulousLabs/Sia/encoding"
+	"github.com/NebulousLabs/Sia/modules"
+)
+
+// TestRateLimiter checks that a rateLimiter spreads transfers out according
+// to its limit, and that a limit of 0 is unlimited.
+func TestRateLimiter(t *testing.T) {
+	var rl rateLimiter
+	start := time.Now()
+	rl.wait(1e9, nil)
+	if time.Since(start) > 100*time.Millisecond {
+		t.Fatal("unlimited rateLimiter blocked")
+	}
+
+	rl.setLimit(10e3)
+	start = time.Now()
+	for i := 0; i < 5; i++ {
+		rl.wait(1e3, nil)
+	}
+	if elapsed := time.Since(start); elapsed < 450*time.Millisecond {
+		t.Fatal("rateLimiter did not throttle transfers:", elapsed)
+	}
+}
+
+// TestRateLimiterStop checks that waiting for a rateLimiter is interrupted
+// when the stop channel is closed.
+func TestRateLimiterStop(t *testing.T) {
+	var rl rateLimiter
+	rl.setLimit(1)
+	stop := make(chan struct{})
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		close(stop)
+	}()
+	start := time.Now()
+	rl.wait(1e6, stop)
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatal("rateLimiter was not interrupted by the stop channel:", elapsed)
+	}
+}
+
+// TestSetRateLimit transfers data between two gateways and checks that the
+// transfer is slowed down by both an upload and a download limit, while still
+// completing.
+func TestSetRateLimit(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	g1 := newTestingGateway("TestSetRateLimit1", t)
+	defer g1.Close()
+	g2 := newTestingGateway("TestSetRateLimit2", t)
+	defer g2.Close()
+	err := g1.Connect(g2.Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Register an RPC that receives the data and acknowledges it.
+	data := make([]byte, 20e3)
+	g2.RegisterRPC("Transfer", func(conn modules.PeerConn) error {
+		var received []byte
+		if err := encoding.ReadObject(conn, &received, uint64(len(data))+8); err != nil {
+			return err
+		}
+		return encoding.WriteObject(conn, len(received))
+	})
+	transfer := func() time.Duration {
+		start := time.Now()
+		err := g1.RPC(g2.Address(), "Transfer", func(conn modules.PeerConn) error {
+			if err := encoding.WriteObject(conn, data); err != nil {
+				return err
+			}
+			var n int
+			if err := encoding.ReadObject(conn, &n, 8); err != nil {
+				return err
+			}
+			if n != len(data) {
+				t.Error("wrong number of bytes received:", n)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return time.Since(start)
+	}
+
+	// Without limits the transfer should be fast.
+	if elapsed := transfer(); elapsed > time.Second {
+		t.Fatal("unlimited transfer was slow:", elapsed)
+	}
+
+	// With a limit of 10 KB/s, transferring 20 KB should take about 2
+	// seconds.
+	g1.SetRateLimit(0, 10e3)
+	if elapsed := transfer(); elapsed < 1500*time.Millisecond {
+		t.Fatal("upload limit did not slow down the transfer:", elapsed)
+	}
+	g1.SetRateLimit(0, 0)
+	g2.SetRateLimit(10e3, 0)
+	if elapsed := transfer(); elapsed < 1500*time.Millisecond {
+		t.Fatal("download limit did not slow down the transfer:", elapsed)
+	}
+	g2.SetRateLimit(0, 0)
+	if elapsed := transfer(); elapsed > time.Second {
+		t.Fatal("transfer was slow after removing the limits:", elapsed)
+	}
+}
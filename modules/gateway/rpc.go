@@ -47,6 +47,7 @@ func (g *Gateway) managedRPC(addr modules.NetAddress, name string, fn modules.RP
 	if err != nil {
 		return err
 	}
+	conn = g.rateLimitConn(conn)
 	defer conn.Close()
 
 	// write header
@@ -163,7 +164,7 @@ func (g *Gateway) threadedListenPeer(p *peer) {
 		}
 
 		// it is the handler's responsibility to close the connection
-		go g.threadedHandleConn(conn)
+		go g.threadedHandleConn(g.rateLimitConn(conn))
 	}
 	// Signal that the goroutine can shutdown.
 	close(peerCloseChan)
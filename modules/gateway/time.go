@@ -0,0 +1,75 @@
+package gateway
+
+import (
+	"sort"
+
+	"github.com/NebulousLabs/Sia/encoding"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// shareTime is an RPC that sends the current time of the gateway to the
+// requesting peer.
+func (g *Gateway) shareTime(conn modules.PeerConn) error {
+	return encoding.WriteObject(conn, types.CurrentTimestamp())
+}
+
+// requestTime is the calling end of the ShareTime RPC. The offset between the
+// time of the peer and the local time is recorded so that it can be used to
+// compute the network-adjusted time.
+func (g *Gateway) requestTime(conn modules.PeerConn) error {
+	var peerTime types.Timestamp
+	if err := encoding.ReadObject(conn, &peerTime, 8); err != nil {
+		return err
+	}
+	offset := int64(peerTime) - int64(types.CurrentTimestamp())
+	g.mu.Lock()
+	g.addTimeSample(conn.RPCAddr(), offset)
+	g.mu.Unlock()
+	return nil
+}
+
+// addTimeSample records the clock offset of a peer. Offsets are clamped to
+// maxTimeOffset so that a single peer cannot skew the median arbitrarily far.
+// Once maxTimeSamples samples are known, samples from new peers replace a
+// random existing sample.
+func (g *Gateway) addTimeSample(addr modules.NetAddress, offset int64) {
+	if offset > maxTimeOffset {
+		offset = maxTimeOffset
+	} else if offset < -maxTimeOffset {
+		offset = -maxTimeOffset
+	}
+	if _, exists := g.peerTimeOffsets[addr]; !exists && len(g.peerTimeOffsets) >= maxTimeSamples {
+		for evict := range g.peerTimeOffsets {
+			delete(g.peerTimeOffsets, evict)
+			break
+		}
+	}
+	g.peerTimeOffsets[addr] = offset
+}
+
+// timeOffset returns the median of the recorded peer clock offsets. If fewer
+// than minTimeSamples samples have been recorded, the local clock is trusted
+// and 0 is returned.
+func (g *Gateway) timeOffset() int64 {
+	if len(g.peerTimeOffsets) < minTimeSamples {
+		return 0
+	}
+	offsets := make([]int, 0, len(g.peerTimeOffsets))
+	for _, offset := range g.peerTimeOffsets {
+		offsets = append(offsets, int(offset))
+	}
+	sort.Ints(offsets)
+	return int64(offsets[len(offsets)/2])
+}
+
+// NetworkTime returns the local time adjusted by the median clock offset of
+// the gateway's peers. The adjustment never exceeds maxTimeOffset, so that
+// peers cannot move the clock of the gateway arbitrarily far, while a skewed
+// local clock is still corrected towards the time of the network.
+func (g *Gateway) NetworkTime() types.Timestamp {
+	g.mu.RLock()
+	offset := g.timeOffset()
+	g.mu.RUnlock()
+	return types.Timestamp(int64(types.CurrentTimestamp()) + offset)
+}
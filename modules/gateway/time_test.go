@@ -0,0 +1,112 @@
+package gateway
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestNetworkTime injects peer time samples into a gateway and checks that
+// the network-adjusted time shifts by the median offset, within bounds.
+func TestNetworkTime(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	g := newTestingGateway("TestNetworkTime", t)
+	defer g.Close()
+
+	// checkOffset checks that the network time of g is offset from the local
+	// time by 'offset' seconds. The local time may tick between the two
+	// reads, so a difference of one second is tolerated.
+	checkOffset := func(offset int64) {
+		before := int64(types.CurrentTimestamp())
+		networkTime := int64(g.NetworkTime())
+		after := int64(types.CurrentTimestamp())
+		if networkTime < before+offset || networkTime > after+offset {
+			t.Fatalf("expected an offset of %v, got network time %v at local time %v", offset, networkTime, before)
+		}
+	}
+
+	// Without enough samples, the local clock is used.
+	checkOffset(0)
+	g.mu.Lock()
+	for i := 0; i < minTimeSamples-1; i++ {
+		g.addTimeSample(modules.NetAddress("111.111.111.111:"+strconv.Itoa(1000+i)), 1)
+	}
+	g.mu.Unlock()
+	checkOffset(0)
+
+	// With enough samples, the median offset is applied.
+	g.mu.Lock()
+	g.addTimeSample("222.222.222.222:1111", 1)
+	g.mu.Unlock()
+	checkOffset(1)
+
+	// Extreme offsets are clamped, so a majority of peers reporting times far
+	// in the future only shifts the clock by maxTimeOffset.
+	g.mu.Lock()
+	for i := 0; i < minTimeSamples; i++ {
+		g.addTimeSample(modules.NetAddress("133.133.133.133:"+strconv.Itoa(1000+i)), 1e9)
+	}
+	g.mu.Unlock()
+	checkOffset(maxTimeOffset)
+
+	// The same holds for times far in the past.
+	g.mu.Lock()
+	for i := 0; i < 3*minTimeSamples; i++ {
+		g.addTimeSample(modules.NetAddress("144.144.144.144:"+strconv.Itoa(1000+i)), -1e9)
+	}
+	g.mu.Unlock()
+	checkOffset(-maxTimeOffset)
+}
+
+// TestShareTime checks that connected gateways exchange their time.
+func TestShareTime(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	g1 := newTestingGateway("TestShareTime1", t)
+	defer g1.Close()
+	g2 := newTestingGateway("TestShareTime2", t)
+	defer g2.Close()
+
+	err := g1.Connect(g2.Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The time is requested asynchronously after connecting.
+	var offset int64
+	var exists bool
+	for i := 0; i < 50 && !exists; i++ {
+		time.Sleep(50 * time.Millisecond)
+		g1.mu.RLock()
+		offset, exists = g1.peerTimeOffsets[g2.Address()]
+		g1.mu.RUnlock()
+	}
+	if !exists {
+		t.Fatal("gateway did not receive the time of its peer")
+	}
+	// Both gateways share the same clock, so the offset can only be caused
+	// by the clock ticking during the RPC.
+	if offset < -1 || offset > 1 {
+		t.Fatal("unexpected time offset between gateways with the same clock:", offset)
+	}
+
+	// The offset of a disconnected peer is eventually forgotten.
+	err = g1.Disconnect(g2.Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 50 && exists; i++ {
+		time.Sleep(50 * time.Millisecond)
+		g1.mu.RLock()
+		_, exists = g1.peerTimeOffsets[g2.Address()]
+		g1.mu.RUnlock()
+	}
+	if exists {
+		t.Fatal("gateway did not forget the time offset of a disconnected peer")
+	}
+}
@@ -0,0 +1,201 @@
+package host
+
+// externalobligations.go tracks storage obligations for file contracts that
+// were formed outside of the host's negotiation protocol. The caller provides
+// the file contract and a reader for the contract data, and the host will
+// build and submit a storage proof once the proof window opens. Unlike the
+// negotiated storage obligations, external obligations are kept in memory
+// only, and need to be re-registered after a restart.
+
+import (
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/encoding"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+var (
+	// errObligationExists is returned when an obligation is added for a file
+	// contract that the host is already tracking.
+	errObligationExists = errors.New("host is already tracking an obligation for that file contract")
+
+	// errObligationExpired is returned when an obligation is added for a file
+	// contract whose proof window has already closed.
+	errObligationExpired = errors.New("proof window for the file contract has already closed")
+
+	// errObligationWrongData is returned when the data provided for an
+	// obligation does not match the file contract.
+	errObligationWrongData = errors.New("obligation data does not match the file contract")
+)
+
+// externalObligation is a file contract that the host has promised to submit
+// a storage proof for, along with the data needed to build the proof.
+type externalObligation struct {
+	contract types.FileContract
+	data     io.ReadSeeker
+
+	// proofSubmitted is set while a storage proof is being built or is
+	// waiting in the transaction pool, and proofConfirmed is set once the
+	// storage proof has appeared in the blockchain.
+	proofSubmitted bool
+	proofConfirmed bool
+}
+
+// AddObligation registers a file contract that the host will submit a storage
+// proof for. The host holds on to 'data' until the contract has resolved, and
+// will read from it when the proof window opens. The Merkle root of 'data'
+// must match the FileMerkleRoot of the file contract.
+func (h *Host) AddObligation(fc types.FileContract, fcid types.FileContractID, data io.ReadSeeker) error {
+	err := h.tg.Add()
+	if err != nil {
+		return err
+	}
+	defer h.tg.Done()
+
+	// Check that the data matches the contract before taking the lock, as
+	// reading the data may take a while.
+	if _, err := data.Seek(0, 0); err != nil {
+		return err
+	}
+	root, err := crypto.ReaderMerkleRoot(data)
+	if err != nil {
+		return err
+	}
+	if root != fc.FileMerkleRoot {
+		return errObligationWrongData
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, exists := h.externalObligations[fcid]; exists {
+		return errObligationExists
+	}
+	if h.blockHeight >= fc.WindowEnd {
+		return errObligationExpired
+	}
+	h.externalObligations[fcid] = &externalObligation{
+		contract: fc,
+		data:     data,
+	}
+	return nil
+}
+
+// updateExternalObligations updates the external obligations according to a
+// consensus change, spawning threads to submit storage proofs for any
+// obligations whose proof window is open. The host lock must be held, and
+// 'wg' must be the wait group of the calling ProcessConsensusChange.
+func (h *Host) updateExternalObligations(cc modules.ConsensusChange, wg *sync.WaitGroup) {
+	if len(h.externalObligations) == 0 {
+		return
+	}
+
+	// Track which of the storage proofs have made it into the blockchain.
+	for _, block := range cc.RevertedBlocks {
+		for _, txn := range block.Transactions {
+			for _, sp := range txn.StorageProofs {
+				if eo, exists := h.externalObligations[sp.ParentID]; exists {
+					eo.proofSubmitted = false
+					eo.proofConfirmed = false
+				}
+			}
+		}
+	}
+	for _, block := range cc.AppliedBlocks {
+		for _, txn := range block.Transactions {
+			for _, sp := range txn.StorageProofs {
+				if eo, exists := h.externalObligations[sp.ParentID]; exists {
+					eo.proofConfirmed = true
+				}
+			}
+		}
+	}
+
+	for fcid, eo := range h.externalObligations {
+		// Once the window has closed, the contract has resolved one way or
+		// another and the obligation can be dropped.
+		if h.blockHeight >= eo.contract.WindowEnd {
+			if !eo.proofConfirmed {
+				h.log.Println("storage proof for external obligation was not confirmed by the deadline, id", fcid)
+			}
+			delete(h.externalObligations, fcid)
+			continue
+		}
+		if eo.proofConfirmed || eo.proofSubmitted || h.blockHeight < eo.contract.WindowStart {
+			continue
+		}
+
+		// Add the thread to the wait group outside of the threaded call, the
+		// same as is done for action items.
+		eo.proofSubmitted = true
+		wg.Add(1)
+		go h.threadedSubmitObligationProof(fcid, eo, wg)
+	}
+}
+
+// threadedSubmitObligationProof builds a storage proof for an external
+// obligation and submits it to the transaction pool.
+func (h *Host) threadedSubmitObligationProof(fcid types.FileContractID, eo *externalObligation, wg *sync.WaitGroup) {
+	// The calling thread is responsible for calling Add to the wait group.
+	defer wg.Done()
+
+	err := h.managedSubmitObligationProof(fcid, eo)
+	if err != nil {
+		h.log.Debugln("Host unable to submit storage proof for external obligation:", err)
+		// Clear the flag so that the submission is retried on the next
+		// block.
+		h.mu.Lock()
+		eo.proofSubmitted = false
+		h.mu.Unlock()
+	}
+}
+
+// managedSubmitObligationProof builds and submits the storage proof for an
+// external obligation.
+func (h *Host) managedSubmitObligationProof(fcid types.FileContractID, eo *externalObligation) error {
+	// Get the index of the segment that needs to be proven, and read the
+	// proof from the obligation data.
+	segmentIndex, err := h.cs.StorageProofSegment(fcid)
+	if err != nil {
+		return err
+	}
+	if _, err := eo.data.Seek(0, 0); err != nil {
+		return err
+	}
+	base, hashSet, err := crypto.BuildReaderProof(eo.data, segmentIndex)
+	if err != nil {
+		return err
+	}
+	sp := types.StorageProof{
+		ParentID: fcid,
+		HashSet:  hashSet,
+	}
+	copy(sp.Segment[:], base)
+
+	// Create and build the transaction with the storage proof.
+	builder := h.wallet.StartTransaction()
+	_, feeRecommendation := h.tpool.FeeEstimation()
+	txnSize := uint64(len(encoding.Marshal(sp)) + 300)
+	requiredFee := feeRecommendation.Mul64(txnSize)
+	err = builder.FundSiacoins(requiredFee)
+	if err != nil {
+		builder.Drop()
+		return err
+	}
+	builder.AddMinerFee(requiredFee)
+	builder.AddStorageProof(sp)
+	storageProofSet, err := builder.Sign(true)
+	if err != nil {
+		builder.Drop()
+		return err
+	}
+	err = h.tpool.AcceptTransactionSet(storageProofSet)
+	if err != nil {
+		builder.Drop()
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package host
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/modules/consensus"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestAddObligation creates a file contract outside of the negotiation
+// protocol, registers it with the host, and checks that the host submits a
+// storage proof which resolves the contract as valid.
+func TestAddObligation(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	ht, err := newHostTester("TestAddObligation")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ht.Close()
+
+	// Create the contract data, and a contract whose valid proof output goes
+	// to an address that the test can look up.
+	data, err := crypto.RandBytes(int(crypto.SegmentSize*5 + 10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	validAddress := types.UnlockHash(crypto.HashObject("valid"))
+	payout := types.SiacoinPrecision.Mul64(1e3)
+	ht.host.mu.RLock()
+	height := ht.host.blockHeight
+	ht.host.mu.RUnlock()
+	fc := types.FileContract{
+		FileSize:       uint64(len(data)),
+		FileMerkleRoot: crypto.MerkleRoot(data),
+		WindowStart:    height + 3,
+		WindowEnd:      height + 10,
+		Payout:         payout,
+		ValidProofOutputs: []types.SiacoinOutput{
+			{Value: types.PostTax(height, payout), UnlockHash: validAddress},
+		},
+		MissedProofOutputs: []types.SiacoinOutput{
+			{Value: types.PostTax(height, payout), UnlockHash: types.UnlockHash{}},
+		},
+	}
+	builder := ht.wallet.StartTransaction()
+	err = builder.FundSiacoins(payout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fcIndex := builder.AddFileContract(fc)
+	tSet, err := builder.Sign(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fcid := tSet[len(tSet)-1].FileContractID(fcIndex)
+	err = ht.tpool.AcceptTransactionSet(tSet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = ht.miner.AddBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Data that does not match the contract should be rejected.
+	badData := append([]byte{}, data...)
+	badData[0]++
+	err = ht.host.AddObligation(fc, fcid, bytes.NewReader(badData))
+	if err != errObligationWrongData {
+		t.Fatal("expected errObligationWrongData, got", err)
+	}
+	err = ht.host.AddObligation(fc, fcid, bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ht.host.AddObligation(fc, fcid, bytes.NewReader(data))
+	if err != errObligationExists {
+		t.Fatal("expected errObligationExists, got", err)
+	}
+
+	// Mine blocks until the storage proof has been confirmed. The proof is
+	// submitted asynchronously, so give the host a moment after each block.
+	proofConfirmed := func() bool {
+		ht.host.mu.RLock()
+		defer ht.host.mu.RUnlock()
+		eo, exists := ht.host.externalObligations[fcid]
+		return exists && eo.proofConfirmed
+	}
+	for i := 0; i < 8 && !proofConfirmed(); i++ {
+		time.Sleep(time.Millisecond * 100)
+		_, err = ht.miner.AddBlock()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !proofConfirmed() {
+		t.Fatal("storage proof was not confirmed")
+	}
+
+	// Mine until the window has closed and the valid proof output has
+	// matured, then check that the output exists.
+	ht.host.mu.RLock()
+	height = ht.host.blockHeight
+	ht.host.mu.RUnlock()
+	for height <= fc.WindowEnd+types.MaturityDelay {
+		_, err = ht.miner.AddBlock()
+		if err != nil {
+			t.Fatal(err)
+		}
+		height++
+	}
+	cs := ht.cs.(*consensus.ConsensusSet)
+	sop, err := cs.SiacoinOutputProof(fcid.StorageProofOutputID(types.ProofValid, 0))
+	if err != nil {
+		t.Fatal("valid proof output was not created:", err)
+	}
+	if sop.Output.UnlockHash != validAddress {
+		t.Error("valid proof output has the wrong address")
+	}
+
+	// The obligation should have been dropped once the window closed.
+	ht.host.mu.RLock()
+	_, exists := ht.host.externalObligations[fcid]
+	ht.host.mu.RUnlock()
+	if exists {
+		t.Error("obligation was not dropped after the window closed")
+	}
+}
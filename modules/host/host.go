@@ -172,6 +172,11 @@ type Host struct {
 	// be locked separately.
 	lockedStorageObligations map[types.FileContractID]*siasync.TryMutex
 
+	// A map of storage obligations for file contracts that were formed
+	// outside of the host's negotiation protocol. These obligations are not
+	// persisted.
+	externalObligations map[types.FileContractID]*externalObligation
+
 	// Utilities.
 	db         *persist.BoltDatabase
 	listener   net.Listener
@@ -229,6 +234,7 @@ func newHost(dependencies dependencies, cs modules.ConsensusSet, tpool modules.T
 		wallet:       wallet,
 		dependencies: dependencies,
 
+		externalObligations:      make(map[types.FileContractID]*externalObligation),
 		lockedStorageObligations: make(map[types.FileContractID]*siasync.TryMutex),
 
 		persistDir: persistDir,
@@ -0,0 +1,98 @@
+package host
+
+import (
+	"testing"
+
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestVerifyNewContractPricing checks that the host refuses contracts whose
+// terms fall below its advertised settings.
+func TestVerifyNewContractPricing(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	ht, err := newHostTester("TestVerifyNewContractPricing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ht.Close()
+
+	// Advertise a contract price, and start accepting contracts so that the
+	// host has an unlock hash.
+	settings := ht.host.InternalSettings()
+	settings.AcceptingContracts = true
+	settings.MinContractPrice = types.SiacoinPrecision.Mul64(10)
+	err = ht.host.SetInternalSettings(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ht.host.ExternalSettings().ContractPrice.Cmp(settings.MinContractPrice) != 0 {
+		t.Fatal("external settings do not advertise the contract price")
+	}
+
+	// Create a contract that pays the host exactly the contract price.
+	_, renterPK, err := crypto.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ht.host.mu.RLock()
+	blockHeight := ht.host.blockHeight
+	hostPK := ht.host.publicKey
+	hostUH := ht.host.unlockHash
+	ht.host.mu.RUnlock()
+	uc := types.UnlockConditions{
+		PublicKeys: []types.SiaPublicKey{
+			{Algorithm: types.SignatureEd25519, Key: renterPK[:]},
+			hostPK,
+		},
+		SignaturesRequired: 2,
+	}
+	newContract := func(hostPayout types.Currency, windowStart types.BlockHeight) []types.Transaction {
+		return []types.Transaction{{
+			FileContracts: []types.FileContract{{
+				WindowStart: windowStart,
+				WindowEnd:   windowStart + settings.WindowSize,
+				ValidProofOutputs: []types.SiacoinOutput{
+					{Value: types.ZeroCurrency},
+					{Value: hostPayout, UnlockHash: hostUH},
+				},
+				MissedProofOutputs: []types.SiacoinOutput{
+					{Value: types.ZeroCurrency},
+					{Value: hostPayout, UnlockHash: hostUH},
+					{Value: types.ZeroCurrency},
+				},
+				UnlockHash: uc.UnlockHash(),
+			}},
+		}}
+	}
+	windowStart := blockHeight + revisionSubmissionBuffer + 1
+	err = ht.host.managedVerifyNewContract(newContract(settings.MinContractPrice, windowStart), renterPK)
+	if err != nil && err != errLowTransactionFees {
+		t.Fatal("contract meeting the host's price was refused:", err)
+	}
+
+	// Offer less than the contract price.
+	lowPayout := settings.MinContractPrice.Sub(types.NewCurrency64(1))
+	err = ht.host.managedVerifyNewContract(newContract(lowPayout, windowStart), renterPK)
+	if err != errLowHostValidOutput {
+		t.Fatal("expected errLowHostValidOutput, got", err)
+	}
+
+	// Offer a contract that runs longer than the host's max duration.
+	err = ht.host.managedVerifyNewContract(newContract(settings.MinContractPrice, blockHeight+settings.MaxDuration+1), renterPK)
+	if err != errLongDuration {
+		t.Fatal("expected errLongDuration, got", err)
+	}
+
+	// A host that is not accepting contracts advertises as much.
+	settings.AcceptingContracts = false
+	err = ht.host.SetInternalSettings(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ht.host.ExternalSettings().AcceptingContracts {
+		t.Fatal("host is advertising that it accepts contracts")
+	}
+}
@@ -309,6 +309,10 @@ func (h *Host) ProcessConsensusChange(cc modules.ConsensusChange) {
 		go h.threadedHandleActionItem(actionItems[i], wg)
 	}
 
+	// Submit storage proofs for any external obligations whose proof window
+	// has opened.
+	h.updateExternalObligations(cc, wg)
+
 	// Update the host's recent change pointer to point to the most recent
 	// change.
 	h.recentChange = cc.ID
@@ -12,6 +12,19 @@ const (
 	MinerDir = "miner"
 )
 
+// BlockTemplate contains everything that an external miner needs to assemble
+// and solve a block of its own. The miner payouts must be used as-is, and the
+// transactions must appear in the block in the order provided.
+type BlockTemplate struct {
+	ParentID     types.BlockID         `json:"parentid"`
+	Height       types.BlockHeight     `json:"height"`
+	MinTimestamp types.Timestamp       `json:"mintimestamp"`
+	MaxTimestamp types.Timestamp       `json:"maxtimestamp"`
+	Target       types.Target          `json:"target"`
+	MinerPayouts []types.SiacoinOutput `json:"minerpayouts"`
+	Transactions []types.Transaction   `json:"transactions"`
+}
+
 // BlockManager contains functions that can interface with external miners,
 // providing and receiving blocks that have experienced nonce grinding.
 type BlockManager interface {
@@ -27,6 +40,20 @@ type BlockManager interface {
 	// BlocksMined returns the number of blocks and stale blocks that have been
 	// mined using this miner.
 	BlocksMined() (goodBlocks, staleBlocks int)
+
+	// BlockTemplate returns a template that can be used to assemble a block
+	// outside of the miner. The completed block can be submitted using
+	// SubmitBlock.
+	BlockTemplate() (BlockTemplate, error)
+
+	// SubmitBlock takes a block that has been assembled and solved outside of
+	// the miner and submits it to the consensus set.
+	SubmitBlock(types.Block) error
+
+	// CheckShare reports whether a solved block meets a pool-chosen share
+	// target, and separately whether it meets the network target, in which
+	// case it should be submitted using SubmitBlock.
+	CheckShare(b types.Block, shareTarget types.Target) (meetsShare bool, meetsNetwork bool)
 }
 
 // CPUMiner provides access to a single-threaded cpu miner.
@@ -43,6 +70,10 @@ type CPUMiner interface {
 
 	// StopMining turns off the miner, but keeps the same number of threads.
 	StopCPUMining()
+
+	// SetThreads sets the number of goroutines that the cpu miner uses to
+	// search the nonce space.
+	SetThreads(int) error
 }
 
 // TestMiner provides direct access to block fetching, solving, and
@@ -66,15 +97,37 @@ type TestMiner interface {
 	// solved.
 	FindBlock() (types.Block, error)
 
+	// FindBlockWithTransactions will have the miner make 1 attempt to find a
+	// solved block that builds on the current consensus set and contains
+	// exactly the input transactions.
+	FindBlockWithTransactions([]types.Transaction) (types.Block, error)
+
 	// SolveBlock will have the miner make 1 attempt to solve the input block,
 	// which amounts to trying a few thousand different nonces. SolveBlock is
 	// primarily used for testing.
 	SolveBlock(types.Block, types.Target) (types.Block, bool)
 }
 
+// MinerStats contains statistics about the performance of a miner.
+type MinerStats struct {
+	BlocksFound int          `json:"blocksfound"`
+	StaleBlocks int          `json:"staleblocks"`
+	Hashrate    int64        `json:"hashrate"`
+	Target      types.Target `json:"target"`
+}
+
 // The Miner interface provides access to mining features.
 type Miner interface {
 	BlockManager
 	CPUMiner
 	io.Closer
+
+	// Stats returns the number of blocks found by the miner, the estimated
+	// hashrate of the cpu miner, and the current target.
+	Stats() MinerStats
+
+	// SetPayoutAddresses splits the miner payout of each block across the
+	// provided outputs, in proportion to their values. Passing no outputs
+	// sends the whole payout to a wallet address.
+	SetPayoutAddresses([]types.SiacoinOutput) error
 }
@@ -31,7 +31,7 @@ func (m *Miner) blockForWork() types.Block {
 	if err != nil {
 		m.log.Println(err)
 	}
-	b.MinerPayouts = []types.SiacoinOutput{{Value: b.CalculateSubsidy(m.persist.Height + 1), UnlockHash: m.persist.Address}}
+	b.MinerPayouts = m.minerPayouts(m.blockSubsidy(b, m.persist.Height+1))
 
 	// Add an arb-data txn to the block to create a unique merkle root.
 	randBytes, _ := crypto.RandBytes(types.SpecifierLen)
@@ -145,14 +145,19 @@ func (m *Miner) managedSubmitBlock(b types.Block) error {
 		m.log.Critical("ERROR: an invalid block was submitted:", err)
 		return err
 	}
+	return m.managedRecordBlock(b)
+}
+
+// managedRecordBlock records a block that was accepted by the consensus set as
+// a block found by the miner, and grabs a new address for future payouts.
+func (m *Miner) managedRecordBlock(b types.Block) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
 	// Grab a new address for the miner. Call may fail if the wallet is locked
 	// or if the wallet addresses have been exhausted.
 	m.persist.BlocksFound = append(m.persist.BlocksFound, b.ID())
-	var uc types.UnlockConditions
-	uc, err = m.wallet.NextAddress()
+	uc, err := m.wallet.NextAddress()
 	if err != nil {
 		return err
 	}
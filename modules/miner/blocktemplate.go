@@ -0,0 +1,88 @@
+package miner
+
+import (
+	"errors"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+var (
+	errUnknownParent = errors.New("parent of the unsolved block is not in the consensus set")
+)
+
+// BlockTemplate returns a template for the next block, containing the parent
+// id, the range of acceptable timestamps, the target, the miner payouts and
+// the ordered set of transactions from the transaction pool. The template
+// allows external miners to assemble blocks themselves instead of grinding on
+// headers provided by HeaderForWork.
+func (m *Miner) BlockTemplate() (modules.BlockTemplate, error) {
+	if err := m.tg.Add(); err != nil {
+		return modules.BlockTemplate{}, err
+	}
+	defer m.tg.Done()
+
+	// The miner payouts go to a wallet address, so the wallet must be
+	// unlocked.
+	if !m.wallet.Unlocked() {
+		return modules.BlockTemplate{}, modules.ErrLockedWallet
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	err := m.checkAddress()
+	if err != nil {
+		return modules.BlockTemplate{}, err
+	}
+
+	// Copy the transactions, the underlying memory of the unsolved block is
+	// replaced whenever the transaction pool updates.
+	b := m.persist.UnsolvedBlock
+	txns := make([]types.Transaction, len(b.Transactions))
+	copy(txns, b.Transactions)
+	b.Transactions = txns
+
+	// The timestamp bounds come from the consensus set, which knows the
+	// median timestamp rules, the network time and the future threshold.
+	minTimestamp, exists := m.cs.MinimumValidChildTimestamp(b.ParentID)
+	if !exists {
+		return modules.BlockTemplate{}, errUnknownParent
+	}
+	maxTimestamp := m.cs.MaximumValidChildTimestamp()
+
+	height := m.persist.Height + 1
+	return modules.BlockTemplate{
+		ParentID:     b.ParentID,
+		Height:       height,
+		MinTimestamp: minTimestamp,
+		MaxTimestamp: maxTimestamp,
+		Target:       m.persist.Target,
+		MinerPayouts: m.minerPayouts(m.blockSubsidy(b, height)),
+		Transactions: txns,
+	}, nil
+}
+
+// SubmitBlock accepts a block that was assembled and solved outside of the
+// miner, typically from a template provided by BlockTemplate.
+func (m *Miner) SubmitBlock(b types.Block) error {
+	if err := m.tg.Add(); err != nil {
+		return err
+	}
+	defer m.tg.Done()
+
+	// Unlike blocks built from headers, blocks assembled externally may be
+	// invalid without indicating a bug in the miner, so a rejected block is
+	// returned as an error without further action.
+	err := m.cs.AcceptBlock(b)
+	if err == modules.ErrNonExtendingBlock {
+		m.mu.Lock()
+		m.persist.BlocksFound = append(m.persist.BlocksFound, b.ID())
+		m.mu.Unlock()
+		return err
+	}
+	if err != nil {
+		m.log.Println("Submitted block was rejected:", err)
+		return err
+	}
+	return m.managedRecordBlock(b)
+}
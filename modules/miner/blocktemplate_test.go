@@ -0,0 +1,82 @@
+package miner
+
+import (
+	"testing"
+
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestIntegrationBlockTemplate checks that a block assembled from a block
+// template can be solved and submitted to the miner.
+func TestIntegrationBlockTemplate(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	mt, err := createMinerTester("TestIntegrationBlockTemplate")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Put a transaction in the transaction pool.
+	_, err = mt.wallet.SendSiacoins(types.NewCurrency64(1e6), types.UnlockHash{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	unconfirmed := mt.tpool.TransactionList()
+
+	// Fetch a template and check that it builds on the current block.
+	bt, err := mt.miner.BlockTemplate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bt.ParentID != mt.cs.CurrentBlock().ID() {
+		t.Fatal("template does not build on the current block")
+	}
+	if bt.Height != mt.cs.Height()+1 {
+		t.Fatal("template has the wrong height")
+	}
+	if bt.MinTimestamp > bt.MaxTimestamp {
+		t.Fatal("template has an empty timestamp range")
+	}
+	minTimestamp, _ := mt.cs.MinimumValidChildTimestamp(bt.ParentID)
+	if bt.MinTimestamp != minTimestamp {
+		t.Fatal("template minimum timestamp does not match the consensus set")
+	}
+	if bt.MaxTimestamp > mt.cs.MaximumValidChildTimestamp() {
+		t.Fatal("template maximum timestamp is past the consensus set's limit")
+	}
+	if len(bt.Transactions) != len(unconfirmed) {
+		t.Fatal("template does not contain the unconfirmed transactions")
+	}
+
+	// Assemble the block, solve it, and submit it.
+	b := types.Block{
+		ParentID:     bt.ParentID,
+		Timestamp:    types.CurrentTimestamp(),
+		MinerPayouts: bt.MinerPayouts,
+		Transactions: bt.Transactions,
+	}
+	if b.Timestamp < bt.MinTimestamp {
+		b.Timestamp = bt.MinTimestamp
+	}
+	solved := false
+	for !solved {
+		b, solved = mt.miner.SolveBlock(b, bt.Target)
+	}
+	err = mt.miner.SubmitBlock(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mt.cs.CurrentBlock().ID() != b.ID() {
+		t.Fatal("submitted block did not become the current block")
+	}
+	if len(mt.tpool.TransactionList()) != 0 {
+		t.Error("transactions in the template were not confirmed")
+	}
+
+	// Submitting the block again should fail.
+	err = mt.miner.SubmitBlock(b)
+	if err == nil {
+		t.Error("duplicate block was accepted")
+	}
+}
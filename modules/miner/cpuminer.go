@@ -1,9 +1,15 @@
 package miner
 
 import (
+	"errors"
 	"time"
 
 	"github.com/NebulousLabs/Sia/build"
+	"github.com/NebulousLabs/Sia/modules"
+)
+
+var (
+	errInvalidThreads = errors.New("the miner must use at least one thread")
 )
 
 // threadedMine starts a gothread that does CPU mining. threadedMine is the
@@ -49,10 +55,13 @@ func (m *Miner) threadedMine() {
 		// Prepare the work and release the miner lock.
 		bfw := m.blockForWork()
 		target := m.persist.Target
+		threads := m.threads
 		m.mu.Unlock()
 
 		// Solve the block.
-		b, solved := solveBlock(bfw, target)
+		b, solved, attempts := solveBlockParallel(bfw, target, threads)
+		elapsed := time.Since(cycleStart)
+		cycleStart = time.Now()
 		if solved {
 			err := m.managedSubmitBlock(b)
 			if err != nil {
@@ -60,18 +69,34 @@ func (m *Miner) threadedMine() {
 			}
 		}
 
-		// Update the hashrate. If the block was solved, the full set of
-		// iterations was not completed, so the hashrate should not be updated.
+		// Update the hashrate using the attempts made during this cycle. If
+		// mining was stopped during the cycle, the hashrate has been reset
+		// and should stay at zero.
 		m.mu.Lock()
-		if !solved {
-			nanosecondsElapsed := 1 + time.Since(cycleStart).Nanoseconds() // Add 1 to prevent divide by zero errors.
-			cycleStart = time.Now()                                        // Reset the cycle counter as soon as the previous value is measured.
-			m.hashRate = 1e9 * solveAttempts / nanosecondsElapsed
+		if m.miningOn {
+			m.recordHashAttempts(attempts, elapsed)
 		}
 		m.mu.Unlock()
 	}
 }
 
+// recordHashAttempts adds a cycle of hash attempts to the sliding window of
+// recent cycles, and updates the hashrate to reflect the window.
+func (m *Miner) recordHashAttempts(attempts int, elapsed time.Duration) {
+	m.hashSamples = append(m.hashSamples, hashSample{attempts: attempts, elapsed: elapsed})
+	if len(m.hashSamples) > hashrateWindow {
+		m.hashSamples = m.hashSamples[len(m.hashSamples)-hashrateWindow:]
+	}
+	var totalAttempts int64
+	var totalElapsed time.Duration
+	for _, sample := range m.hashSamples {
+		totalAttempts += int64(sample.attempts)
+		totalElapsed += sample.elapsed
+	}
+	nanosecondsElapsed := 1 + totalElapsed.Nanoseconds() // Add 1 to prevent divide by zero errors.
+	m.hashRate = 1e9 * totalAttempts / nanosecondsElapsed
+}
+
 // CPUHashrate returns an estimated cpu hashrate.
 func (m *Miner) CPUHashrate() int {
 	if err := m.tg.Add(); err != nil {
@@ -110,6 +135,25 @@ func (m *Miner) StartCPUMining() {
 	go m.threadedMine()
 }
 
+// SetThreads sets the number of goroutines that the cpu miner uses to search
+// the nonce space. The change takes effect at the start of the next mining
+// cycle.
+func (m *Miner) SetThreads(n int) error {
+	if err := m.tg.Add(); err != nil {
+		return err
+	}
+	defer m.tg.Done()
+
+	if n < 1 {
+		return errInvalidThreads
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.threads = n
+	m.hashSamples = nil
+	return nil
+}
+
 // StopCPUMining will stop the cpu miner. If the cpu miner is already stopped,
 // nothing will happen.
 func (m *Miner) StopCPUMining() {
@@ -121,5 +165,31 @@ func (m *Miner) StopCPUMining() {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	m.hashRate = 0
+	m.hashSamples = nil
 	m.miningOn = false
 }
+
+// Stats returns statistics about the miner, including the number of blocks
+// found, the estimated hashrate of the cpu miner, and the current target.
+func (m *Miner) Stats() modules.MinerStats {
+	if err := m.tg.Add(); err != nil {
+		build.Critical(err)
+	}
+	defer m.tg.Done()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats := modules.MinerStats{
+		Hashrate: m.hashRate,
+		Target:   m.persist.Target,
+	}
+	for _, blockID := range m.persist.BlocksFound {
+		if m.cs.InCurrentPath(blockID) {
+			stats.BlocksFound++
+		} else {
+			stats.StaleBlocks++
+		}
+	}
+	return stats
+}
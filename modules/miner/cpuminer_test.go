@@ -0,0 +1,131 @@
+package miner
+
+import (
+	"testing"
+	"time"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestIntegrationStats runs the cpu miner briefly and checks that the miner
+// statistics reflect the work done.
+func TestIntegrationStats(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	mt, err := createMinerTester("TestIntegrationStats")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	initialStats := mt.miner.Stats()
+	if initialStats.Hashrate != 0 {
+		t.Error("hashrate should be zero before mining")
+	}
+	if initialStats.Target != mt.miner.persist.Target {
+		t.Error("stats report the wrong target")
+	}
+
+	// Mine until a block has been found and the hashrate is known.
+	mt.miner.StartCPUMining()
+	var stats modules.MinerStats
+	for i := 0; i < 100; i++ {
+		time.Sleep(time.Millisecond * 50)
+		stats = mt.miner.Stats()
+		if stats.BlocksFound > initialStats.BlocksFound && stats.Hashrate > 0 {
+			break
+		}
+	}
+	mt.miner.StopCPUMining()
+	if stats.BlocksFound <= initialStats.BlocksFound {
+		t.Error("no blocks were found while mining")
+	}
+	if stats.Hashrate <= 0 {
+		t.Error("hashrate was not measured while mining")
+	}
+
+	// Stopping the miner should reset the hashrate.
+	if mt.miner.Stats().Hashrate != 0 {
+		t.Error("hashrate should be zero after mining has stopped")
+	}
+}
+
+// TestIntegrationSetThreads checks that the miner can find valid blocks using
+// multiple threads, and that mining with multiple threads can be stopped.
+func TestIntegrationSetThreads(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	mt, err := createMinerTester("TestIntegrationSetThreads")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = mt.miner.SetThreads(0)
+	if err != errInvalidThreads {
+		t.Fatal("expecting errInvalidThreads, got", err)
+	}
+	err = mt.miner.SetThreads(4)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Blocks found using multiple threads should meet the target.
+	for i := 0; i < 5; i++ {
+		target := mt.miner.persist.Target
+		b, err := mt.miner.FindBlock()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !meetsTarget(b, target) {
+			t.Fatal("block found with multiple threads does not meet the target")
+		}
+		err = mt.cs.AcceptBlock(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Mine using multiple threads, then stop the miner and check that it
+	// halts.
+	mt.miner.StartCPUMining()
+	time.Sleep(time.Millisecond * 100)
+	mt.miner.StopCPUMining()
+	for i := 0; i < 50; i++ {
+		mt.miner.mu.Lock()
+		mining := mt.miner.mining
+		mt.miner.mu.Unlock()
+		if !mining {
+			return
+		}
+		time.Sleep(time.Millisecond * 20)
+	}
+	t.Error("miner did not halt after being stopped")
+}
+
+// benchmarkSolveBlock measures how long it takes to solve a block with the
+// given number of threads.
+func benchmarkSolveBlock(b *testing.B, threads int) {
+	target := types.Target{0, 2}
+	block := types.Block{Timestamp: types.CurrentTimestamp()}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		solved := false
+		for !solved {
+			block.Timestamp++
+			_, solved, _ = solveBlockParallel(block, target, threads)
+		}
+	}
+}
+
+// BenchmarkSolveBlockSingleThread benchmarks solving a block with one thread.
+func BenchmarkSolveBlockSingleThread(b *testing.B) {
+	benchmarkSolveBlock(b, 1)
+}
+
+// BenchmarkSolveBlockMultiThread benchmarks solving a block with four
+// threads.
+func BenchmarkSolveBlockMultiThread(b *testing.B) {
+	benchmarkSolveBlock(b, 4)
+}
@@ -19,6 +19,8 @@ var (
 	errNilTpool  = errors.New("miner cannot use a nil transaction pool")
 	errNilWallet = errors.New("miner cannot use a nil wallet")
 
+	errZeroPayoutSplit = errors.New("payout splits must all have a nonzero value")
+
 	// HeaderMemory is the number of previous calls to 'header'
 	// that are remembered. Additionally, 'header' will only poll for a
 	// new block every 'headerMemory / blockMemory' times it is
@@ -53,6 +55,21 @@ var (
 		panic("unrecognized build.Release")
 	}()
 
+	// hashrateWindow is the number of recent mining cycles that are used to
+	// estimate the hashrate.
+	hashrateWindow = func() int {
+		if build.Release == "dev" {
+			return 10
+		}
+		if build.Release == "standard" {
+			return 20
+		}
+		if build.Release == "testing" {
+			return 3
+		}
+		panic("unrecognized build.Release")
+	}()
+
 	// MaxSourceBlockAge is the maximum amount of time that is allowed to
 	// elapse between generating source blocks.
 	MaxSourceBlockAge = func() time.Duration {
@@ -69,6 +86,13 @@ var (
 	}()
 )
 
+// hashSample records the number of hashes attempted during a single mining
+// cycle, and how long the cycle took.
+type hashSample struct {
+	attempts int
+	elapsed  time.Duration
+}
+
 // Miner struct contains all variables the miner needs
 // in order to create and submit blocks.
 type Miner struct {
@@ -96,9 +120,11 @@ type Miner struct {
 	memProgress     int                                            // The index of the most recent header used in headerMem.
 
 	// CPUMiner variables.
-	miningOn bool  // indicates if the miner is supposed to be running
-	mining   bool  // indicates if the miner is actually running
-	hashRate int64 // indicates hashes per second
+	miningOn    bool         // indicates if the miner is supposed to be running
+	mining      bool         // indicates if the miner is actually running
+	hashRate    int64        // indicates hashes per second
+	hashSamples []hashSample // hash attempts of the most recent mining cycles
+	threads     int          // number of goroutines used to search the nonce space
 
 	// Utils
 	log        *persist.Logger
@@ -170,6 +196,8 @@ func New(cs modules.ConsensusSet, tpool modules.TransactionPool, w modules.Walle
 		arbDataMem: make(map[types.BlockHeader][crypto.EntropySize]byte),
 		headerMem:  make([]types.BlockHeader, HeaderMemory),
 
+		threads: 1,
+
 		persistDir: persistDir,
 	}
 
@@ -244,6 +272,69 @@ func (m *Miner) checkAddress() error {
 	return nil
 }
 
+// blockSubsidy returns the subsidy of a block at the given height, using the
+// coinbase schedule of the consensus set.
+func (m *Miner) blockSubsidy(b types.Block, height types.BlockHeight) types.Currency {
+	return m.cs.Coinbase(height).Add(b.CalculateMinerFees())
+}
+
+// minerPayouts returns the miner payouts for a block with the given subsidy.
+// If payout splits have been set, the subsidy is divided between them in
+// proportion to their values, with any rounding remainder going to the last
+// split. Otherwise the whole subsidy goes to the miner's address.
+func (m *Miner) minerPayouts(subsidy types.Currency) []types.SiacoinOutput {
+	if len(m.persist.PayoutSplits) == 0 {
+		return []types.SiacoinOutput{{Value: subsidy, UnlockHash: m.persist.Address}}
+	}
+
+	var totalWeight types.Currency
+	for _, split := range m.persist.PayoutSplits {
+		totalWeight = totalWeight.Add(split.Value)
+	}
+	var payouts []types.SiacoinOutput
+	remaining := subsidy
+	for i, split := range m.persist.PayoutSplits {
+		value := remaining
+		if i != len(m.persist.PayoutSplits)-1 {
+			value = subsidy.Mul(split.Value).Div(totalWeight)
+		}
+		remaining = remaining.Sub(value)
+		// Consensus does not allow miner payouts with a zero value, which can
+		// happen if a split is very small relative to the others.
+		if value.IsZero() {
+			continue
+		}
+		payouts = append(payouts, types.SiacoinOutput{Value: value, UnlockHash: split.UnlockHash})
+	}
+	return payouts
+}
+
+// SetPayoutAddresses sets the outputs that the miner payout of each block is
+// split between. The value of each split is a weight: the payout is divided
+// in proportion to the weights, and the weights do not need to add up to
+// anything in particular. Passing no splits sends the whole payout to an
+// address from the wallet.
+func (m *Miner) SetPayoutAddresses(splits []types.SiacoinOutput) error {
+	if err := m.tg.Add(); err != nil {
+		return err
+	}
+	defer m.tg.Done()
+
+	for _, split := range splits {
+		if split.Value.IsZero() {
+			return errZeroPayoutSplit
+		}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.persist.PayoutSplits = append([]types.SiacoinOutput(nil), splits...)
+
+	// Headers that were already handed out keep their old payouts, but any
+	// new headers should use the new splits.
+	m.sourceBlockTime = time.Time{}
+	return m.save()
+}
+
 // BlocksMined returns the number of good blocks and stale blocks that have
 // been mined by the miner.
 func (m *Miner) BlocksMined() (goodBlocks, staleBlocks int) {
@@ -348,3 +348,151 @@ func TestMinerCloseDeadlock(t *testing.T) {
 		t.Fatal("mt.miner.Close never completed")
 	}
 }
+
+// TestIntegrationPayoutSplits checks that the miner divides the block subsidy
+// between the payout splits, and that the resulting blocks are valid.
+func TestIntegrationPayoutSplits(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	mt, err := createMinerTester("TestIntegrationPayoutSplits")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Splits with a zero weight are not allowed.
+	err = mt.miner.SetPayoutAddresses([]types.SiacoinOutput{{Value: types.ZeroCurrency}})
+	if err != errZeroPayoutSplit {
+		t.Fatal("expected errZeroPayoutSplit, got", err)
+	}
+
+	// Split the payout 1:3 between two addresses, and put a transaction with
+	// fees in the transaction pool so that the fees get split as well.
+	addr1 := types.UnlockHash{1}
+	addr2 := types.UnlockHash{2}
+	err = mt.miner.SetPayoutAddresses([]types.SiacoinOutput{
+		{Value: types.NewCurrency64(1), UnlockHash: addr1},
+		{Value: types.NewCurrency64(3), UnlockHash: addr2},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = mt.wallet.SendSiacoins(types.NewCurrency64(1e6), types.UnlockHash{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := mt.miner.AddBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(b.MinerPayouts) != 2 {
+		t.Fatal("expected two miner payouts, got", len(b.MinerPayouts))
+	}
+	subsidy := b.CalculateSubsidy(mt.cs.Height())
+	if subsidy.Cmp(types.CalculateCoinbase(mt.cs.Height())) <= 0 {
+		t.Fatal("block did not collect any fees")
+	}
+	share1 := subsidy.Div64(4)
+	if b.MinerPayouts[0].UnlockHash != addr1 || b.MinerPayouts[0].Value.Cmp(share1) != 0 {
+		t.Error("first payout is wrong:", b.MinerPayouts[0])
+	}
+	if b.MinerPayouts[1].UnlockHash != addr2 || b.MinerPayouts[1].Value.Cmp(subsidy.Sub(share1)) != 0 {
+		t.Error("second payout is wrong:", b.MinerPayouts[1])
+	}
+
+	// Headers handed out by the block manager should use the splits too.
+	header, target, err := mt.miner.HeaderForWork()
+	if err != nil {
+		t.Fatal(err)
+	}
+	header = solveHeader(header, target)
+	err = mt.miner.SubmitHeader(header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hb := mt.cs.CurrentBlock()
+	if len(hb.MinerPayouts) != 2 || hb.MinerPayouts[0].UnlockHash != addr1 || hb.MinerPayouts[1].UnlockHash != addr2 {
+		t.Error("block mined from a header does not use the payout splits")
+	}
+
+	// Clearing the splits sends the payout to a single address again.
+	err = mt.miner.SetPayoutAddresses(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err = mt.miner.AddBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(b.MinerPayouts) != 1 || b.MinerPayouts[0].UnlockHash != mt.miner.persist.Address {
+		t.Error("payouts were not reset to the miner address")
+	}
+}
+
+// TestIntegrationCustomCoinbase checks that the miner pays out the coinbase
+// from the coinbase schedule of the consensus set.
+func TestIntegrationCustomCoinbase(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	testdir := build.TempDir(modules.MinerDir, "TestIntegrationCustomCoinbase")
+	g, err := gateway.New("localhost:0", false, filepath.Join(testdir, modules.GatewayDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A flat coinbase of 25 SC.
+	schedule := func(types.BlockHeight) types.Currency {
+		return types.SiacoinPrecision.Mul64(25)
+	}
+	cs, err := consensus.NewCustomConsensusSet(g, false, filepath.Join(testdir, modules.ConsensusDir), consensus.GenesisParams{
+		Timestamp:         types.GenesisTimestamp,
+		SiafundAllocation: types.GenesisSiafundAllocation,
+		RootTarget:        types.RootTarget,
+		CoinbaseSchedule:  schedule,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tp, err := transactionpool.New(cs, g, filepath.Join(testdir, modules.TransactionPoolDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := wallet.New(cs, tp, filepath.Join(testdir, modules.WalletDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var key crypto.TwofishKey
+	_, err = rand.Read(key[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = w.Encrypt(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = w.Unlock(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := New(cs, tp, w, filepath.Join(testdir, modules.MinerDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 3; i++ {
+		b, err := m.AddBlock()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b.MinerPayouts[0].Value.Cmp(schedule(cs.Height())) != 0 {
+			t.Error("miner payout does not match the coinbase schedule")
+		}
+	}
+	b, _, err := m.BlockForWork()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.MinerPayouts[0].Value.Cmp(schedule(cs.Height()+1)) != 0 {
+		t.Error("BlockForWork payout does not match the coinbase schedule")
+	}
+}
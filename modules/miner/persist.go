@@ -28,6 +28,7 @@ type (
 		Height        types.BlockHeight
 		Target        types.Target
 		Address       types.UnlockHash
+		PayoutSplits  []types.SiacoinOutput
 		BlocksFound   []types.BlockID
 		UnsolvedBlock types.Block
 	}
@@ -0,0 +1,31 @@
+package miner
+
+import (
+	"bytes"
+
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// meetsTarget returns true if the id of the block meets the target.
+func meetsTarget(b types.Block, target types.Target) bool {
+	id := b.ID()
+	return bytes.Compare(target[:], id[:]) >= 0
+}
+
+// CheckShare checks a solved block against a share target chosen by a mining
+// pool, and separately reports whether the block also meets the network
+// target. Blocks that meet the network target should be submitted using
+// SubmitBlock. A block with an unknown parent never meets the network target.
+func (m *Miner) CheckShare(b types.Block, shareTarget types.Target) (meetsShare bool, meetsNetwork bool) {
+	if err := m.tg.Add(); err != nil {
+		return false, false
+	}
+	defer m.tg.Done()
+
+	meetsShare = meetsTarget(b, shareTarget)
+	networkTarget, exists := m.cs.ChildTarget(b.ParentID)
+	if !exists {
+		return meetsShare, false
+	}
+	return meetsShare, meetsTarget(b, networkTarget)
+}
@@ -0,0 +1,80 @@
+package miner
+
+import (
+	"testing"
+
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestIntegrationCheckShare checks that shares are validated against both the
+// share target and the network target.
+func TestIntegrationCheckShare(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	mt, err := createMinerTester("TestIntegrationCheckShare")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bt, err := mt.miner.BlockTemplate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := types.Block{
+		ParentID:     bt.ParentID,
+		Timestamp:    bt.MinTimestamp,
+		MinerPayouts: bt.MinerPayouts,
+		Transactions: bt.Transactions,
+	}
+
+	// Every block meets the easiest possible share target, and no block meets
+	// the hardest possible share target.
+	var easiest, hardest types.Target
+	for i := range easiest {
+		easiest[i] = 0xff
+	}
+	meetsShare, _ := mt.miner.CheckShare(b, easiest)
+	if !meetsShare {
+		t.Error("block should meet the easiest share target")
+	}
+	meetsShare, _ = mt.miner.CheckShare(b, hardest)
+	if meetsShare {
+		t.Error("block should not meet the hardest share target")
+	}
+
+	// Find a block that meets the share target but not the network target.
+	shareTarget := easiest
+	for {
+		b.Nonce[0]++
+		if b.Nonce[0] == 0 {
+			b.Nonce[1]++
+		}
+		meetsShare, meetsNetwork := mt.miner.CheckShare(b, shareTarget)
+		if meetsShare && !meetsNetwork {
+			break
+		}
+	}
+
+	// Solve the block for the network target, it should meet both targets and
+	// be accepted.
+	solved := false
+	for !solved {
+		b, solved = mt.miner.SolveBlock(b, bt.Target)
+	}
+	meetsShare, meetsNetwork := mt.miner.CheckShare(b, shareTarget)
+	if !meetsShare || !meetsNetwork {
+		t.Fatal("solved block should meet the share and network targets")
+	}
+	err = mt.miner.SubmitBlock(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A block with an unknown parent does not meet the network target.
+	b.ParentID = types.BlockID{}
+	_, meetsNetwork = mt.miner.CheckShare(b, easiest)
+	if meetsNetwork {
+		t.Error("block with an unknown parent should not meet the network target")
+	}
+}
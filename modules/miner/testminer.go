@@ -7,9 +7,11 @@ import (
 	"bytes"
 	"encoding/binary"
 	"errors"
-	"unsafe"
+	"sync"
+	"sync/atomic"
 
 	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/encoding"
 	"github.com/NebulousLabs/Sia/modules"
 	"github.com/NebulousLabs/Sia/types"
 )
@@ -20,28 +22,86 @@ const (
 	solveAttempts = 16e3
 )
 
-// solveBlock takes a block and a target and tries to solve the block for the
-// target. A bool is returned indicating whether the block was successfully
-// solved.
-func solveBlock(b types.Block, target types.Target) (types.Block, bool) {
-	// Assemble the header.
+var (
+	errLargeBlock    = errors.New("transactions do not fit in a block")
+	errUnsolvedBlock = errors.New("could not solve block using limited hashing power")
+)
+
+// blockHeaderBytes returns the serialized header of a block, with the nonce
+// left at zero.
+func blockHeaderBytes(b types.Block) []byte {
 	merkleRoot := b.MerkleRoot()
 	header := make([]byte, 80)
 	copy(header, b.ParentID[:])
 	binary.LittleEndian.PutUint64(header[40:48], uint64(b.Timestamp))
 	copy(header[48:], merkleRoot[:])
+	return header
+}
 
-	var nonce uint64
-	for i := 0; i < solveAttempts; i++ {
+// solveNonceRange tries up to 'attempts' nonces starting at 'start', stopping
+// early if 'stop' is set by another worker. The solving nonce is returned
+// along with whether the header was solved and the number of hashes that were
+// attempted.
+func solveNonceRange(header []byte, target types.Target, start uint64, attempts int, stop *int32) (uint64, bool, int) {
+	for i := 0; i < attempts; i++ {
+		if atomic.LoadInt32(stop) != 0 {
+			return 0, false, i
+		}
+		nonce := start + uint64(i)
+		binary.LittleEndian.PutUint64(header[32:40], nonce)
 		id := crypto.HashBytes(header)
 		if bytes.Compare(target[:], id[:]) >= 0 {
-			copy(b.Nonce[:], header[32:40])
-			return b, true
+			atomic.StoreInt32(stop, 1)
+			return nonce, true, i + 1
+		}
+	}
+	return 0, false, attempts
+}
+
+// solveBlock takes a block and a target and tries to solve the block for the
+// target. A bool is returned indicating whether the block was successfully
+// solved, along with the number of hashes that were attempted.
+func solveBlock(b types.Block, target types.Target) (types.Block, bool, int) {
+	return solveBlockParallel(b, target, 1)
+}
+
+// solveBlockParallel splits the work of solving a block across 'threads'
+// goroutines, each of which scans a disjoint range of 'solveAttempts' nonces.
+// All goroutines stop as soon as any of them finds a nonce that meets the
+// target.
+func solveBlockParallel(b types.Block, target types.Target, threads int) (types.Block, bool, int) {
+	if threads < 1 {
+		threads = 1
+	}
+	header := blockHeaderBytes(b)
+
+	var stop int32
+	var wg sync.WaitGroup
+	nonces := make([]uint64, threads)
+	solved := make([]bool, threads)
+	tried := make([]int, threads)
+	for i := 0; i < threads; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			workerHeader := make([]byte, len(header))
+			copy(workerHeader, header)
+			nonces[i], solved[i], tried[i] = solveNonceRange(workerHeader, target, uint64(i)*solveAttempts, solveAttempts, &stop)
+		}(i)
+	}
+	wg.Wait()
+
+	attempts := 0
+	for i := range tried {
+		attempts += tried[i]
+	}
+	for i := range solved {
+		if solved[i] {
+			binary.LittleEndian.PutUint64(b.Nonce[:], nonces[i])
+			return b, true, attempts
 		}
-		*(*uint64)(unsafe.Pointer(&header[32])) = nonce
-		nonce++
 	}
-	return b, false
+	return b, false, attempts
 }
 
 // BlockForWork returns a block that is ready for nonce grinding, along with
@@ -81,6 +141,7 @@ func (m *Miner) AddBlock() (types.Block, error) {
 func (m *Miner) FindBlock() (types.Block, error) {
 	var bfw types.Block
 	var target types.Target
+	var threads int
 	err := func() error {
 		m.mu.Lock()
 		defer m.mu.Unlock()
@@ -96,15 +157,62 @@ func (m *Miner) FindBlock() (types.Block, error) {
 		// Get a block for work.
 		bfw = m.blockForWork()
 		target = m.persist.Target
+		threads = m.threads
+		return nil
+	}()
+	if err != nil {
+		return types.Block{}, err
+	}
+
+	block, ok, _ := solveBlockParallel(bfw, target, threads)
+	if !ok {
+		return types.Block{}, errUnsolvedBlock
+	}
+	return block, nil
+}
+
+// FindBlockWithTransactions finds at most one block that extends the current
+// blockchain and contains exactly the input transactions. An error is
+// returned if the block would exceed the block size limit.
+func (m *Miner) FindBlockWithTransactions(txns []types.Transaction) (types.Block, error) {
+	var b types.Block
+	var target types.Target
+	var threads int
+	err := func() error {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		if !m.wallet.Unlocked() {
+			return modules.ErrLockedWallet
+		}
+		err := m.checkAddress()
+		if err != nil {
+			return err
+		}
+
+		b = types.Block{
+			ParentID:     m.persist.UnsolvedBlock.ParentID,
+			Timestamp:    m.persist.UnsolvedBlock.Timestamp,
+			Transactions: txns,
+		}
+		if b.Timestamp < types.CurrentTimestamp() {
+			b.Timestamp = types.CurrentTimestamp()
+		}
+		b.MinerPayouts = m.minerPayouts(m.blockSubsidy(b, m.persist.Height+1))
+		target = m.persist.Target
+		threads = m.threads
 		return nil
 	}()
 	if err != nil {
 		return types.Block{}, err
 	}
+	if uint64(len(encoding.Marshal(b))) > m.cs.BlockSizeLimit() {
+		return types.Block{}, errLargeBlock
+	}
 
-	block, ok := m.SolveBlock(bfw, target)
+	block, ok, _ := solveBlockParallel(b, target, threads)
 	if !ok {
-		return types.Block{}, errors.New("could not solve block using limited hashing power")
+		return types.Block{}, errUnsolvedBlock
 	}
 	return block, nil
 }
@@ -113,5 +221,6 @@ func (m *Miner) FindBlock() (types.Block, error) {
 // target. A bool is returned indicating whether the block was successfully
 // solved.
 func (m *Miner) SolveBlock(b types.Block, target types.Target) (types.Block, bool) {
-	return solveBlock(b, target)
+	b, solved, _ := solveBlock(b, target)
+	return b, solved
 }
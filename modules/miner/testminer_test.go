@@ -0,0 +1,66 @@
+package miner
+
+import (
+	"testing"
+
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestIntegrationFindBlockWithTransactions checks that FindBlockWithTransactions
+// mines blocks containing exactly the input transactions, and that it rejects
+// transactions that do not fit in a block.
+func TestIntegrationFindBlockWithTransactions(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	mt, err := createMinerTester("TestIntegrationFindBlockWithTransactions")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Create a transaction paying a fee, without giving it to the transaction
+	// pool.
+	fee := types.SiacoinPrecision.Mul64(7)
+	txnBuilder := mt.wallet.StartTransaction()
+	err = txnBuilder.FundSiacoins(types.SiacoinPrecision.Add(fee))
+	if err != nil {
+		t.Fatal(err)
+	}
+	txnBuilder.AddSiacoinOutput(types.SiacoinOutput{Value: types.SiacoinPrecision})
+	txnBuilder.AddMinerFee(fee)
+	txnSet, err := txnBuilder.Sign(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	block, err := mt.miner.FindBlockWithTransactions(txnSet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(block.Transactions) != len(txnSet) {
+		t.Fatalf("expected %v transactions, got %v", len(txnSet), len(block.Transactions))
+	}
+	for i := range txnSet {
+		if block.Transactions[i].ID() != txnSet[i].ID() {
+			t.Fatal("block does not contain the input transactions")
+		}
+	}
+	subsidy := types.CalculateCoinbase(mt.cs.Height() + 1).Add(fee)
+	if len(block.MinerPayouts) != 1 || block.MinerPayouts[0].Value.Cmp(subsidy) != 0 {
+		t.Fatal("block has the wrong miner payout")
+	}
+	err = mt.cs.AcceptBlock(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A transaction set that does not fit in a block should be rejected.
+	largeTxn := types.Transaction{
+		ArbitraryData: [][]byte{make([]byte, types.BlockSizeLimit)},
+	}
+	_, err = mt.miner.FindBlockWithTransactions([]types.Transaction{largeTxn})
+	if err != errLargeBlock {
+		t.Fatal("expected errLargeBlock, got", err)
+	}
+}
@@ -0,0 +1,137 @@
+package miner
+
+import (
+	"sort"
+
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/encoding"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+type (
+	// txnGroup is a group of transactions that depend on each other, in
+	// dependency order. A group is either added to a block as a whole or not
+	// at all.
+	txnGroup struct {
+		txns []types.Transaction
+		fees types.Currency
+		size int
+	}
+
+	// groupsByFeeDensity sorts transaction groups by fee density, highest
+	// first.
+	groupsByFeeDensity []txnGroup
+)
+
+// Len is part of sort.Interface.
+func (gs groupsByFeeDensity) Len() int { return len(gs) }
+
+// Less is part of sort.Interface. The fee densities are compared by cross
+// multiplication to avoid rounding.
+func (gs groupsByFeeDensity) Less(i, j int) bool {
+	return gs[i].fees.Mul64(uint64(gs[j].size)).Cmp(gs[j].fees.Mul64(uint64(gs[i].size))) > 0
+}
+
+// Swap is part of sort.Interface.
+func (gs groupsByFeeDensity) Swap(i, j int) { gs[i], gs[j] = gs[j], gs[i] }
+
+// spentObjects returns the ids of all objects consumed by a transaction.
+func spentObjects(t types.Transaction) []crypto.Hash {
+	var ids []crypto.Hash
+	for _, sci := range t.SiacoinInputs {
+		ids = append(ids, crypto.Hash(sci.ParentID))
+	}
+	for _, fcr := range t.FileContractRevisions {
+		ids = append(ids, crypto.Hash(fcr.ParentID))
+	}
+	for _, sp := range t.StorageProofs {
+		ids = append(ids, crypto.Hash(sp.ParentID))
+	}
+	for _, sfi := range t.SiafundInputs {
+		ids = append(ids, crypto.Hash(sfi.ParentID))
+	}
+	return ids
+}
+
+// createdObjects returns the ids of all objects created by a transaction.
+func createdObjects(t types.Transaction) []crypto.Hash {
+	var ids []crypto.Hash
+	for i := range t.SiacoinOutputs {
+		ids = append(ids, crypto.Hash(t.SiacoinOutputID(uint64(i))))
+	}
+	for i := range t.FileContracts {
+		ids = append(ids, crypto.Hash(t.FileContractID(uint64(i))))
+	}
+	for i := range t.SiafundOutputs {
+		ids = append(ids, crypto.Hash(t.SiafundOutputID(uint64(i))))
+	}
+	return ids
+}
+
+// transactionGroups splits a list of transactions into groups of dependent
+// transactions. A transaction that spends an object created by an earlier
+// transaction is put into the same group as that transaction. The order of
+// the input is preserved within each group, and the groups are ordered by
+// their first transaction.
+func transactionGroups(txns []types.Transaction) []txnGroup {
+	// Join dependent transactions using a disjoint-set forest.
+	parents := make([]int, len(txns))
+	find := func(i int) int {
+		for parents[i] != i {
+			parents[i] = parents[parents[i]]
+			i = parents[i]
+		}
+		return i
+	}
+	creators := make(map[crypto.Hash]int)
+	for i, t := range txns {
+		parents[i] = i
+		for _, id := range spentObjects(t) {
+			if creator, exists := creators[id]; exists {
+				parents[find(creator)] = find(i)
+			}
+		}
+		for _, id := range createdObjects(t) {
+			creators[id] = i
+		}
+	}
+
+	// Collect the groups.
+	var groups []txnGroup
+	groupIndex := make(map[int]int)
+	for i, t := range txns {
+		root := find(i)
+		index, exists := groupIndex[root]
+		if !exists {
+			index = len(groups)
+			groupIndex[root] = index
+			groups = append(groups, txnGroup{})
+		}
+		g := &groups[index]
+		g.txns = append(g.txns, t)
+		g.fees = g.fees.Add(t.TotalMinerFees())
+		g.size += len(encoding.Marshal(t))
+	}
+	return groups
+}
+
+// selectTransactions picks the transactions that should go into a block of at
+// most 'sizeLimit' bytes of transactions. Groups of dependent transactions
+// are added in order of decreasing fee density, skipping the groups that no
+// longer fit. The transactions that are not selected are left in the
+// transaction pool for later blocks.
+func selectTransactions(txns []types.Transaction, sizeLimit int) []types.Transaction {
+	groups := transactionGroups(txns)
+	sort.Stable(groupsByFeeDensity(groups))
+
+	var selected []types.Transaction
+	remainingSize := sizeLimit
+	for _, g := range groups {
+		if g.size > remainingSize {
+			continue
+		}
+		selected = append(selected, g.txns...)
+		remainingSize -= g.size
+	}
+	return selected
+}
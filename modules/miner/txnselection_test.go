@@ -0,0 +1,69 @@
+package miner
+
+import (
+	"testing"
+
+	"github.com/NebulousLabs/Sia/encoding"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestSelectTransactions seeds a list of transactions with varied fees and
+// checks that the transactions with the highest fee density that fit are
+// selected, keeping dependent transactions together and in order.
+func TestSelectTransactions(t *testing.T) {
+	// newTxn creates a transaction with the given fee and arbitrary data
+	// size, which is used to control the size of the transaction.
+	newTxn := func(fee uint64, dataSize int) types.Transaction {
+		return types.Transaction{
+			MinerFees:     []types.Currency{types.NewCurrency64(fee)},
+			ArbitraryData: [][]byte{make([]byte, dataSize)},
+		}
+	}
+	low := newTxn(1, 1000)
+	mid := newTxn(10, 1000)
+	high := newTxn(100, 1000)
+	big := newTxn(10000, 5000)
+
+	// A low fee parent with a high fee child. Together, their fee density is
+	// between that of 'mid' and 'high'.
+	parent := newTxn(0, 1000)
+	parent.SiacoinOutputs = []types.SiacoinOutput{{Value: types.NewCurrency64(1)}}
+	child := newTxn(110, 1000)
+	child.SiacoinInputs = []types.SiacoinInput{{ParentID: parent.SiacoinOutputID(0)}}
+
+	size := func(txns ...types.Transaction) int {
+		var size int
+		for _, txn := range txns {
+			size += len(encoding.Marshal(txn))
+		}
+		return size
+	}
+	txns := []types.Transaction{low, mid, parent, child, high, big}
+
+	// With room for everything, all transactions are selected.
+	selected := selectTransactions(txns, size(txns...))
+	if len(selected) != len(txns) {
+		t.Fatal("expected all transactions to be selected, got", len(selected))
+	}
+
+	// With room for four of the small transactions, 'big' has the highest
+	// density but does not fit, so 'high', the parent-child pair, and 'mid'
+	// are selected in that order.
+	selected = selectTransactions(txns, size(high, parent, child, mid))
+	expected := []types.Transaction{high, parent, child, mid}
+	if len(selected) != len(expected) {
+		t.Fatalf("expected %v transactions, got %v", len(expected), len(selected))
+	}
+	for i := range expected {
+		if selected[i].ID() != expected[i].ID() {
+			t.Fatal("unexpected transaction at index", i)
+		}
+	}
+
+	// The parent-child pair is never split, even though 'child' alone would
+	// fit. The smaller 'high' fits in the remaining space instead.
+	selected = selectTransactions(txns, size(big, child))
+	if len(selected) != 2 || selected[0].ID() != big.ID() || selected[1].ID() != high.ID() {
+		t.Fatal("expected the big and high transactions to be selected")
+	}
+}
@@ -1,7 +1,6 @@
 package miner
 
 import (
-	"github.com/NebulousLabs/Sia/encoding"
 	"github.com/NebulousLabs/Sia/modules"
 	"github.com/NebulousLabs/Sia/types"
 )
@@ -65,15 +64,13 @@ func (m *Miner) ReceiveUpdatedUnconfirmedTransactions(unconfirmedTransactions []
 		return
 	}
 
-	// Add transactions to the block until the block size limit is reached.
-	// Transactions are assumed to be in a sensible order.
-	var i int
-	remainingSize := int(types.BlockSizeLimit - 5e3)
-	for i = range unconfirmedTransactions {
-		remainingSize -= len(encoding.Marshal(unconfirmedTransactions[i]))
-		if remainingSize < 0 {
-			break
-		}
+	// Add the transactions with the highest fee density to the block until
+	// the block size limit is reached. Space is reserved for the header and
+	// the miner payouts.
+	sizeLimit := m.cs.BlockSizeLimit()
+	if sizeLimit < 5e3 {
+		m.persist.UnsolvedBlock.Transactions = nil
+		return
 	}
-	m.persist.UnsolvedBlock.Transactions = unconfirmedTransactions[:i+1]
+	m.persist.UnsolvedBlock.Transactions = selectTransactions(unconfirmedTransactions, int(sizeLimit-5e3))
 }
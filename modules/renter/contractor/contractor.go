@@ -46,6 +46,7 @@ type Contractor struct {
 	downloaders     map[types.FileContractID]*hostDownloader
 	editors         map[types.FileContractID]*hostEditor
 	lastChange      modules.ConsensusChangeID
+	missedIDs       map[types.FileContractID]struct{} // contracts whose host missed the storage proof
 	renewedIDs      map[types.FileContractID]types.FileContractID
 	renewing        map[types.FileContractID]bool // prevent revising during renewal
 	revising        map[types.FileContractID]bool // prevent overlapping revisions
@@ -103,6 +104,24 @@ func (c *Contractor) resolveID(id types.FileContractID) types.FileContractID {
 	return id
 }
 
+// IsMissed reports whether the host of the contract, or of any renewal of the
+// contract, failed to submit a storage proof. Data stored under a missed
+// contract should be considered lost.
+func (c *Contractor) IsMissed(id types.FileContractID) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for {
+		if _, missed := c.missedIDs[id]; missed {
+			return true
+		}
+		newID, ok := c.renewedIDs[id]
+		if !ok || newID == id {
+			return false
+		}
+		id = newID
+	}
+}
+
 // New returns a new Contractor.
 func New(cs consensusSet, wallet walletShim, tpool transactionPool, hdb hostDB, persistDir string) (*Contractor, error) {
 	// Check for nil inputs.
@@ -146,6 +165,7 @@ func newContractor(cs consensusSet, w wallet, tp transactionPool, hdb hostDB, p
 		contracts:       make(map[types.FileContractID]modules.RenterContract),
 		downloaders:     make(map[types.FileContractID]*hostDownloader),
 		editors:         make(map[types.FileContractID]*hostEditor),
+		missedIDs:       make(map[types.FileContractID]struct{}),
 		renewedIDs:      make(map[types.FileContractID]types.FileContractID),
 		renewing:        make(map[types.FileContractID]bool),
 		revising:        make(map[types.FileContractID]bool),
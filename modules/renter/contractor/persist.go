@@ -14,6 +14,7 @@ type contractorPersist struct {
 	Contracts        []modules.RenterContract
 	FinancialMetrics modules.RenterFinancialMetrics
 	LastChange       modules.ConsensusChangeID
+	MissedIDs        []types.FileContractID
 	RenewedIDs       map[string]string
 }
 
@@ -32,6 +33,9 @@ func (c *Contractor) persistData() contractorPersist {
 	for _, contract := range c.contracts {
 		data.Contracts = append(data.Contracts, contract)
 	}
+	for id := range c.missedIDs {
+		data.MissedIDs = append(data.MissedIDs, id)
+	}
 	for oldID, newID := range c.renewedIDs {
 		data.RenewedIDs[oldID.String()] = newID.String()
 	}
@@ -55,6 +59,9 @@ func (c *Contractor) load() error {
 	}
 	c.financialMetrics = data.FinancialMetrics
 	c.lastChange = data.LastChange
+	for _, id := range data.MissedIDs {
+		c.missedIDs[id] = struct{}{}
+	}
 	for oldString, newString := range data.RenewedIDs {
 		var oldHash, newHash crypto.Hash
 		oldHash.LoadString(oldString)
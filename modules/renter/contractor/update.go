@@ -20,6 +20,24 @@ func (c *Contractor) ProcessConsensusChange(cc modules.ConsensusChange) {
 		}
 	}
 
+	// Track contracts whose host failed to submit a storage proof. The data
+	// under those contracts is presumed lost; the renter will notice through
+	// IsMissed and repair the affected pieces using other hosts.
+	for _, event := range cc.FileContractEvents {
+		if event.Type != modules.FileContractProofMissed || !c.isTracked(event.ID) {
+			continue
+		}
+		if event.Direction == modules.DiffRevert {
+			delete(c.missedIDs, event.ID)
+			continue
+		}
+		if c.missedIDs == nil {
+			c.missedIDs = make(map[types.FileContractID]struct{})
+		}
+		c.missedIDs[event.ID] = struct{}{}
+		c.log.Println("WARN: host missed the storage proof for contract", event.ID)
+	}
+
 	// delete expired contracts
 	var expired []types.FileContractID
 	for id, contract := range c.contracts {
@@ -79,3 +97,20 @@ func (c *Contractor) ProcessConsensusChange(cc modules.ConsensusChange) {
 		}()
 	}
 }
+
+// isTracked returns whether the contract was formed by the contractor, either
+// directly or as a renewal.
+func (c *Contractor) isTracked(id types.FileContractID) bool {
+	if _, ok := c.contracts[id]; ok {
+		return true
+	}
+	if _, ok := c.renewedIDs[id]; ok {
+		return true
+	}
+	for _, newID := range c.renewedIDs {
+		if newID == id {
+			return true
+		}
+	}
+	return false
+}
@@ -48,6 +48,61 @@ func TestProcessConsensusUpdate(t *testing.T) {
 	}
 }
 
+// TestProcessConsensusMissedProof tests that a contract is reported as missed
+// when the storage proof for it, or for the contract it renewed, is missed.
+func TestProcessConsensusMissedProof(t *testing.T) {
+	var stub newStub
+	oldID := types.FileContractID{1}
+	newID := types.FileContractID{2}
+	otherID := types.FileContractID{3}
+	c := &Contractor{
+		cs:  stub,
+		hdb: stub,
+		contracts: map[types.FileContractID]modules.RenterContract{
+			newID:   {ID: newID},
+			otherID: {ID: otherID},
+		},
+		missedIDs: make(map[types.FileContractID]struct{}),
+		renewedIDs: map[types.FileContractID]types.FileContractID{
+			oldID: newID,
+		},
+		persist: new(memPersist),
+		log:     persist.NewLogger(ioutil.Discard),
+	}
+
+	// A valid proof and a missed proof of an unknown contract should not
+	// affect the contracts.
+	c.ProcessConsensusChange(modules.ConsensusChange{
+		FileContractEvents: []modules.FileContractEvent{
+			{Direction: modules.DiffApply, ID: oldID, Type: modules.FileContractProofValid},
+			{Direction: modules.DiffApply, ID: types.FileContractID{4}, Type: modules.FileContractProofMissed},
+		},
+	})
+	if c.IsMissed(oldID) || len(c.missedIDs) != 0 {
+		t.Fatal("no contracts should have been marked as missed")
+	}
+
+	// The host misses the proof for the old contract.
+	c.ProcessConsensusChange(modules.ConsensusChange{
+		FileContractEvents: []modules.FileContractEvent{
+			{Direction: modules.DiffApply, ID: oldID, Type: modules.FileContractProofMissed},
+		},
+	})
+	if !c.IsMissed(oldID) || c.IsMissed(newID) || c.IsMissed(otherID) {
+		t.Error("IsMissed reports the wrong contracts")
+	}
+
+	// Reverting the event should clear the missed status.
+	c.ProcessConsensusChange(modules.ConsensusChange{
+		FileContractEvents: []modules.FileContractEvent{
+			{Direction: modules.DiffRevert, ID: oldID, Type: modules.FileContractProofMissed},
+		},
+	})
+	if c.IsMissed(oldID) {
+		t.Error("missed status was not cleared by the revert")
+	}
+}
+
 // TestIntegrationAutoRenew tests that contracts are automatically renwed at
 // the expected block height.
 func TestIntegrationAutoRenew(t *testing.T) {
@@ -57,6 +57,51 @@ func TestRSEncode(t *testing.T) {
 	}
 }
 
+// TestRSRecoverMissingPieces checks that data can be recovered from any
+// MinPieces of the encoded pieces, and that recovery fails with fewer.
+func TestRSRecoverMissingPieces(t *testing.T) {
+	const dataPieces, parityPieces = 4, 3
+	rsc, err := NewRSCode(dataPieces, parityPieces)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := make([]byte, 777)
+	rand.Read(data)
+
+	// Drop each window of parityPieces consecutive pieces, which covers
+	// dropping data pieces, parity pieces, and a mix of the two.
+	for start := 0; start < rsc.NumPieces(); start++ {
+		pieces, err := rsc.Encode(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < parityPieces; i++ {
+			pieces[(start+i)%len(pieces)] = nil
+		}
+		buf := new(bytes.Buffer)
+		err = rsc.Recover(pieces, uint64(len(data)), buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(data, buf.Bytes()) {
+			t.Fatal("recovered data does not match original")
+		}
+	}
+
+	// Dropping more than parityPieces pieces should make recovery fail.
+	pieces, err := rsc.Encode(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i <= parityPieces; i++ {
+		pieces[i] = nil
+	}
+	err = rsc.Recover(pieces, uint64(len(data)), ioutil.Discard)
+	if err == nil {
+		t.Fatal("expected recovery to fail with too few pieces")
+	}
+}
+
 func BenchmarkRSEncode(b *testing.B) {
 	rsc, err := NewRSCode(80, 20)
 	if err != nil {
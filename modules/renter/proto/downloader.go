@@ -11,6 +11,29 @@ import (
 	"github.com/NebulousLabs/Sia/types"
 )
 
+var (
+	// errBadSectorData is returned if the host sends sector data that does
+	// not match the requested Merkle root.
+	errBadSectorData = errors.New("host sent bad sector data")
+
+	// errShortSectorData is returned if the host sends less than a full
+	// sector of data.
+	errShortSectorData = errors.New("host did not send enough sector data")
+)
+
+// verifySector checks that the sector data sent by the host is a full sector
+// and matches the Merkle root that was requested. Sector data must be
+// verified before it is returned to the caller, so that corrupt data is never
+// written to disk.
+func verifySector(sector []byte, root crypto.Hash) error {
+	if uint64(len(sector)) != modules.SectorSize {
+		return errShortSectorData
+	} else if crypto.MerkleRoot(sector) != root {
+		return errBadSectorData
+	}
+	return nil
+}
+
 // A Downloader retrieves sectors by calling the download RPC on a host.
 // Downloaders are NOT thread- safe; calls to Sector must be serialized.
 type Downloader struct {
@@ -85,10 +108,8 @@ func (hd *Downloader) Sector(root crypto.Hash) (modules.RenterContract, []byte,
 		return modules.RenterContract{}, nil, errors.New("host did not send enough sectors")
 	}
 	sector := sectors[0]
-	if uint64(len(sector)) != modules.SectorSize {
-		return modules.RenterContract{}, nil, errors.New("host did not send enough sector data")
-	} else if crypto.MerkleRoot(sector) != root {
-		return modules.RenterContract{}, nil, errors.New("host sent bad sector data")
+	if err := verifySector(sector, root); err != nil {
+		return modules.RenterContract{}, nil, err
 	}
 
 	// update contract and metrics
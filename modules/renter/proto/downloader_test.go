@@ -0,0 +1,33 @@
+package proto
+
+import (
+	"testing"
+
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/modules"
+)
+
+// TestVerifySector checks that sector data from a host is only accepted if it
+// matches the requested Merkle root.
+func TestVerifySector(t *testing.T) {
+	sector, err := crypto.RandBytes(int(modules.SectorSize))
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := crypto.MerkleRoot(sector)
+	if err := verifySector(sector, root); err != nil {
+		t.Fatal("valid sector was rejected:", err)
+	}
+
+	// Tamper with a single segment of the sector.
+	tampered := append([]byte(nil), sector...)
+	tampered[crypto.SegmentSize*3]++
+	if err := verifySector(tampered, root); err != errBadSectorData {
+		t.Fatal("expected errBadSectorData, got", err)
+	}
+
+	// Send a truncated sector.
+	if err := verifySector(sector[:len(sector)-1], root); err != errShortSectorData {
+		t.Fatal("expected errShortSectorData, got", err)
+	}
+}
@@ -63,6 +63,10 @@ type hostContractor interface {
 	// FinancialMetrics returns the financial metrics of the contractor.
 	FinancialMetrics() modules.RenterFinancialMetrics
 
+	// IsMissed reports whether the host of the contract failed to submit a
+	// storage proof, meaning that the data under the contract is lost.
+	IsMissed(types.FileContractID) bool
+
 	// Downloader creates a Downloader from the specified contract ID,
 	// allowing the retrieval of sectors.
 	Downloader(types.FileContractID) (contractor.Downloader, error)
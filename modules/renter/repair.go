@@ -126,6 +126,23 @@ func (f *file) repair(chunkIndex uint64, missingPieces []uint64, r io.ReaderAt,
 	return nil
 }
 
+// removeMissedContracts removes the contracts for which 'missed' returns
+// true, so that the pieces stored under them are reported by
+// incompleteChunks. It returns the hosts of the removed contracts.
+func (f *file) removeMissedContracts(missed func(types.FileContractID) bool) []modules.NetAddress {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var hosts []modules.NetAddress
+	for id, fc := range f.contracts {
+		if missed(id) {
+			delete(f.contracts, id)
+			hosts = append(hosts, fc.IP)
+		}
+	}
+	return hosts
+}
+
 // incompleteChunks returns a map of chunks containing pieces that have not
 // been uploaded.
 func (f *file) incompleteChunks() map[uint64][]uint64 {
@@ -276,6 +293,21 @@ func (r *Renter) threadedRepairFile(name string, meta trackedFile, pool *hostPoo
 		return
 	}
 
+	// drop the pieces stored with hosts that missed a storage proof, and
+	// don't repair the file using those hosts
+	if missedHosts := f.removeMissedContracts(r.hostContractor.IsMissed); len(missedHosts) > 0 {
+		r.log.Printf("dropped contracts with missed storage proofs from %v", f.name)
+		for _, host := range missedHosts {
+			pool.remove(host)
+		}
+		f.mu.RLock()
+		err := r.saveFile(f)
+		f.mu.RUnlock()
+		if err != nil {
+			r.log.Printf("failed to save %v after dropping missed contracts: %v", f.name, err)
+		}
+	}
+
 	// determine if there is any work to do
 	incChunks := f.incompleteChunks()
 	if len(incChunks) == 0 {
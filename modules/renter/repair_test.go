@@ -200,3 +200,34 @@ func TestOfflineChunks(t *testing.T) {
 		}
 	}
 }
+
+// TestRemoveMissedContracts checks that pieces stored under contracts whose
+// host missed a storage proof are reported as needing repair.
+func TestRemoveMissedContracts(t *testing.T) {
+	rsc, _ := NewRSCode(1, 1)
+	f := newFile("foo", rsc, pieceSize, pieceSize)
+	f.contracts[types.FileContractID{0}] = fileContract{IP: "foo", Pieces: []pieceData{{0, 0, crypto.Hash{}}}}
+	f.contracts[types.FileContractID{1}] = fileContract{IP: "bar", Pieces: []pieceData{{0, 1, crypto.Hash{}}}}
+	if len(f.incompleteChunks()) != 0 {
+		t.Fatal("file should not need repair")
+	}
+
+	// Removing contracts when no host missed a proof should be a no-op.
+	if hosts := f.removeMissedContracts(func(types.FileContractID) bool { return false }); len(hosts) != 0 {
+		t.Fatal("contracts were removed even though no proofs were missed")
+	}
+
+	// The host of contract 1 missed its proof; piece 1 of chunk 0 should need
+	// to be repaired.
+	missed := func(id types.FileContractID) bool { return id == types.FileContractID{1} }
+	hosts := f.removeMissedContracts(missed)
+	if !reflect.DeepEqual(hosts, []modules.NetAddress{"bar"}) {
+		t.Fatal("missed contract was not removed:", hosts)
+	}
+	expChunks := map[uint64][]uint64{
+		0: {1},
+	}
+	if chunks := f.incompleteChunks(); !reflect.DeepEqual(chunks, expChunks) {
+		t.Fatalf("incompleteChunks did not return correct chunks: expected %v, got %v", expChunks, chunks)
+	}
+}
@@ -3,6 +3,7 @@ package modules
 import (
 	"errors"
 
+	"github.com/NebulousLabs/Sia/build"
 	"github.com/NebulousLabs/Sia/encoding"
 	"github.com/NebulousLabs/Sia/types"
 )
@@ -16,13 +17,47 @@ const (
 	// TransactionSetSizeLimit defines the largest set of dependent unconfirmed
 	// transactions that will be accepted by the transaction pool.
 	TransactionSetSizeLimit = 250e3
+
+	// TpoolSizeLimit is the default limit on the combined size of all
+	// transactions in the transaction pool. The default is small enough that
+	// the whole pool fits in a single block.
+	TpoolSizeLimit = 2e6 - 5e3 - TransactionSetSizeLimit
 )
 
 var (
+	// DustThreshold is the smallest value that a siacoin output created by a
+	// standard transaction may have. Outputs below the threshold are worth
+	// less than the fees needed to spend them, and would bloat the set of
+	// unspent outputs forever. Testing builds use a single hasting, so that
+	// tests are free to send arbitrarily small amounts.
+	DustThreshold = func() types.Currency {
+		switch build.Release {
+		case "dev":
+			return types.SiacoinPrecision.Div64(1e6)
+		case "standard":
+			return types.SiacoinPrecision.Div64(1e6)
+		case "testing":
+			return types.NewCurrency64(1)
+		default:
+			panic("unrecognized build.Release in DustThreshold")
+		}
+	}()
+
 	// ErrDuplicateTransactionSet is the error that gets returned if a
 	// duplicate transaction set is given to the transaction pool.
 	ErrDuplicateTransactionSet = errors.New("transaction set contains only duplicate transactions")
 
+	// ErrDoubleSpend is the error that gets returned if a transaction set
+	// given to the transaction pool spends an object that is already spent
+	// by a transaction in the pool, and is not a fee-bump replacement of that
+	// transaction.
+	ErrDoubleSpend = errors.New("transaction set double spends an object spent by a transaction in the pool")
+
+	// ErrDustOutput is the error that gets returned if a transaction given to
+	// the transaction pool creates a siacoin output with a value below the
+	// DustThreshold.
+	ErrDustOutput = errors.New("transaction creates a siacoin output below the dust threshold")
+
 	// ErrLargeTransaction is the error that gets returned if a transaction
 	// provided to the transaction pool is larger than what is allowed by the
 	// IsStandard rules.
@@ -33,6 +68,11 @@ var (
 	// IsStandard rules of the transaction pool.
 	ErrLargeTransactionSet = errors.New("transaction set is too large for this transaction pool")
 
+	// ErrLowFee is the error that gets returned if a transaction set given to
+	// the transaction pool pays less than the minimum relay fee of the
+	// transaction pool.
+	ErrLowFee = errors.New("transaction set pays less than the minimum relay fee")
+
 	// ErrInvalidArbPrefix is the error that gets returned if a transaction is
 	// submitted to the transaction pool which contains a prefix that is not
 	// recognized. This helps prevent miners on old versions from mining
@@ -64,7 +104,8 @@ type TransactionPoolSubscriber interface {
 // A TransactionPool manages unconfirmed transactions.
 type TransactionPool interface {
 	// AcceptTransactionSet accepts a set of potentially interdependent
-	// transactions.
+	// transactions. A set that double spends transactions already in the
+	// pool replaces them if it pays strictly more in miner fees.
 	AcceptTransactionSet([]types.Transaction) error
 
 	// Close is necessary for clean shutdown (e.g. during testing).
@@ -89,6 +130,21 @@ type TransactionPool interface {
 	// that make this condition necessary.
 	PurgeTransactionPool()
 
+	// SetMinRelayFee sets the minimum fee per byte that transaction sets
+	// must pay to be accepted by the transaction pool. Transactions
+	// containing storage proofs are exempt.
+	SetMinRelayFee(feePerByte types.Currency)
+
+	// SetSizeLimit sets the limit on the combined size of all transactions
+	// in the transaction pool. Transaction sets with the lowest fee density
+	// are evicted to stay within the limit.
+	SetSizeLimit(limit uint64)
+
+	// Snapshot returns every transaction currently in the transaction pool.
+	// The contents of the pool are saved at shutdown and revalidated when
+	// the pool is next started.
+	Snapshot() []types.Transaction
+
 	// TransactionList returns a list of all transactions in the transaction
 	// pool. The transactions are provided in an order that can acceptably be
 	// put into a block.
@@ -15,18 +15,10 @@ import (
 )
 
 const (
-	// The TransactionPoolSizeLimit is first checked, and then a transaction
-	// set is added. The current transaction pool does not do any priority
-	// ordering, so the size limit is such that the transaction pool will never
-	// exceed the size of a block.
-	//
-	// TODO: Add a priority structure that will allow the transaction pool to
-	// fill up beyond the size of a single block, without being subject to
-	// manipulation.
-	//
 	// The first ~1/4 of the transaction pool can be filled for free. This is
 	// mostly to preserve compatibility with clients that do not add fees.
-	TransactionPoolSizeLimit  = 2e6 - 5e3 - modules.TransactionSetSizeLimit
+	// Once the size limit of the pool is reached, the transaction sets with
+	// the lowest fee density are evicted to make room for new sets.
 	TransactionPoolSizeForFee = 500e3
 )
 
@@ -76,12 +68,6 @@ func relatedObjectIDs(ts []types.Transaction) []ObjectID {
 // checkMinerFees checks that the total amount of transaction fees in the
 // transaction set is sufficient to earn a spot in the transaction pool.
 func (tp *TransactionPool) checkMinerFees(ts []types.Transaction) error {
-	// Transactions cannot be added after the TransactionPoolSizeLimit has been
-	// hit.
-	if tp.transactionListSize > TransactionPoolSizeLimit {
-		return errFullTransactionPool
-	}
-
 	// The first TransactionPoolSizeForFee transactions do not need fees.
 	if tp.transactionListSize > TransactionPoolSizeForFee {
 		// Currently required fees are set on a per-transaction basis. 2 coins
@@ -101,6 +87,39 @@ func (tp *TransactionPool) checkMinerFees(ts []types.Transaction) error {
 	return nil
 }
 
+// checkMinRelayFee checks that the transaction set pays at least the minimum
+// relay fee per byte. Transactions containing storage proofs are exempt, as
+// hosts are not always able to add fees to them, and are not counted towards
+// the size of the set.
+func (tp *TransactionPool) checkMinRelayFee(ts []types.Transaction) error {
+	if tp.minRelayFee.IsZero() {
+		return nil
+	}
+	var feeSum types.Currency
+	var size int
+	for _, t := range ts {
+		if len(t.StorageProofs) > 0 {
+			continue
+		}
+		for _, fee := range t.MinerFees {
+			feeSum = feeSum.Add(fee)
+		}
+		size += len(encoding.Marshal(t))
+	}
+	if feeSum.Cmp(tp.minRelayFee.Mul64(uint64(size))) < 0 {
+		return modules.ErrLowFee
+	}
+	return nil
+}
+
+// SetMinRelayFee sets the minimum fee per byte that transaction sets must pay
+// to be accepted by the transaction pool. A fee of zero disables the check.
+func (tp *TransactionPool) SetMinRelayFee(feePerByte types.Currency) {
+	tp.mu.Lock()
+	tp.minRelayFee = feePerByte
+	tp.mu.Unlock()
+}
+
 // checkTransactionSetComposition checks if the transaction set is valid given
 // the state of the pool. It does not check that each individual transaction
 // would be legal in the next block, but does check things like miner fees and
@@ -119,6 +138,10 @@ func (tp *TransactionPool) checkTransactionSetComposition(ts []types.Transaction
 	if err != nil {
 		return err
 	}
+	err = tp.checkMinRelayFee(ts)
+	if err != nil {
+		return err
+	}
 
 	// All checks after this are expensive.
 	//
@@ -202,15 +225,135 @@ func (tp *TransactionPool) handleConflicts(ts []types.Transaction, conflicts []T
 		return err
 	}
 
-	// Check that the transaction set is valid.
+	// Check that the transaction set is valid. If the superset is invalid,
+	// the new set may be double spending transactions in the conflicting sets,
+	// in which case the new set may replace them if it pays more fees.
+	cc, err := tp.consensusSet.TryTransactionSet(superset)
+	if err != nil {
+		return tp.handleReplacement(dedupSet, supersetMap, modules.NewConsensusConflict(err.Error()))
+	}
+	err = tp.makeRoom(superset, supersetMap)
+	if err != nil {
+		return err
+	}
+	tp.replaceSets(supersetMap, superset, cc)
+	return nil
+}
+
+// spentObjectIDs returns the ids of all objects consumed by a transaction.
+func spentObjectIDs(t types.Transaction) []ObjectID {
+	var oids []ObjectID
+	for _, sci := range t.SiacoinInputs {
+		oids = append(oids, ObjectID(sci.ParentID))
+	}
+	for _, fcr := range t.FileContractRevisions {
+		oids = append(oids, ObjectID(fcr.ParentID))
+	}
+	for _, sp := range t.StorageProofs {
+		oids = append(oids, ObjectID(sp.ParentID))
+	}
+	for _, sfi := range t.SiafundInputs {
+		oids = append(oids, ObjectID(sfi.ParentID))
+	}
+	return oids
+}
+
+// transactionSetFees returns the sum of the miner fees in a set of
+// transactions.
+func transactionSetFees(ts []types.Transaction) (fees types.Currency) {
+	for _, t := range ts {
+		for _, fee := range t.MinerFees {
+			fees = fees.Add(fee)
+		}
+	}
+	return fees
+}
+
+// handleReplacement attempts to replace the transactions in the conflicting
+// sets that double spend the objects spent by the new transaction set 'ts'.
+// Every transaction that spends an object that is also spent by 'ts' is
+// evicted, along with every transaction that depends on an evicted
+// transaction. The replacement is only accepted if 'ts' pays strictly more in
+// miner fees than the evicted transactions. The remaining transactions of the
+// conflicting sets are kept and merged with 'ts'. If 'ts' does not double
+// spend any object, 'conflictErr' is returned.
+func (tp *TransactionPool) handleReplacement(ts []types.Transaction, conflicts map[TransactionSetID]struct{}, conflictErr error) error {
+	spent := make(map[ObjectID]struct{})
+	for _, t := range ts {
+		for _, oid := range spentObjectIDs(t) {
+			spent[oid] = struct{}{}
+		}
+	}
+
+	// Split the conflicting sets into evicted and kept transactions. The
+	// transaction sets are in dependency order, so the objects created by an
+	// evicted transaction are known before any of its children are visited.
+	var kept, evicted []types.Transaction
+	evictedObjects := make(map[ObjectID]struct{})
+	for conflict := range conflicts {
+		for _, t := range tp.transactionSets[conflict] {
+			evict := false
+			for _, oid := range spentObjectIDs(t) {
+				_, doubleSpend := spent[oid]
+				_, dependent := evictedObjects[oid]
+				if doubleSpend || dependent {
+					evict = true
+					break
+				}
+			}
+			if !evict {
+				kept = append(kept, t)
+				continue
+			}
+			evicted = append(evicted, t)
+			for _, oid := range relatedObjectIDs([]types.Transaction{t}) {
+				evictedObjects[oid] = struct{}{}
+			}
+		}
+	}
+	if len(evicted) == 0 {
+		return conflictErr
+	}
+	// A set that does not pay strictly more miner fees than the transactions
+	// it evicts is not a fee-bump replacement, it is a plain double spend.
+	if transactionSetFees(ts).Cmp(transactionSetFees(evicted)) <= 0 {
+		return modules.ErrDoubleSpend
+	}
+
+	// Check that the kept transactions combined with the new set form a
+	// valid transaction set.
+	superset := append(kept, ts...)
+	err := tp.checkTransactionSetComposition(superset)
+	if err != nil {
+		return err
+	}
 	cc, err := tp.consensusSet.TryTransactionSet(superset)
 	if err != nil {
 		return modules.NewConsensusConflict(err.Error())
 	}
 
+	err = tp.makeRoom(superset, conflicts)
+	if err != nil {
+		return err
+	}
+
+	// Clear the objects of the evicted transactions, then replace the
+	// conflicting sets with the superset.
+	for _, oid := range relatedObjectIDs(evicted) {
+		if _, exists := conflicts[tp.knownObjects[oid]]; exists {
+			delete(tp.knownObjects, oid)
+		}
+	}
+	tp.replaceSets(conflicts, superset, cc)
+	return nil
+}
+
+// replaceSets removes the provided transaction sets from the transaction pool
+// and adds the superset, which has the consensus change 'cc'.
+func (tp *TransactionPool) replaceSets(conflicts map[TransactionSetID]struct{}, superset []types.Transaction, cc modules.ConsensusChange) {
 	// Remove the conflicts from the transaction pool. The diffs do not need to
 	// be removed, they will be overwritten later in the function.
-	for _, conflict := range conflictMap {
+	for conflict := range conflicts {
 		conflictSet := tp.transactionSets[conflict]
 		tp.transactionListSize -= len(encoding.Marshal(conflictSet))
 		delete(tp.transactionSets, conflict)
@@ -231,7 +374,6 @@ func (tp *TransactionPool) handleConflicts(ts []types.Transaction, conflicts []T
 	}
 	tp.transactionSetDiffs[setID] = cc
 	tp.transactionListSize += len(encoding.Marshal(superset))
-	return nil
 }
 
 // acceptTransactionSet verifies that a transaction set is allowed to be in the
@@ -285,6 +427,10 @@ func (tp *TransactionPool) acceptTransactionSet(ts []types.Transaction) error {
 	if err != nil {
 		return modules.NewConsensusConflict(err.Error())
 	}
+	err = tp.makeRoom(ts, nil)
+	if err != nil {
+		return err
+	}
 
 	// Add the transaction set to the pool.
 	setID := TransactionSetID(crypto.HashObject(ts))
@@ -297,24 +443,42 @@ func (tp *TransactionPool) acceptTransactionSet(ts []types.Transaction) error {
 	return nil
 }
 
-// AcceptTransaction adds a transaction to the unconfirmed set of
-// transactions. If the transaction is accepted, it will be relayed to
-// connected peers.
-func (tp *TransactionPool) AcceptTransactionSet(ts []types.Transaction) error {
+// acceptTransactionSetFrom adds a transaction set received from a peer to the
+// transaction pool, holding it as an orphan if its parents are unknown. Local
+// transaction sets have an empty peer address.
+func (tp *TransactionPool) acceptTransactionSetFrom(ts []types.Transaction, peer modules.NetAddress) error {
 	tp.mu.Lock()
 	defer tp.mu.Unlock()
 
 	err := tp.acceptTransactionSet(ts)
+	if err == errOrphanConflict {
+		// The parents of the set may still be propagating through the
+		// network, hold on to the set in case they arrive.
+		tp.addOrphan(ts, peer)
+	}
 	if err != nil {
 		return err
 	}
 
-	// Notify subscribers and broadcast the transaction set.
+	// The new set may provide the parents of orphan sets.
+	promoted := tp.promoteOrphans(createdSiacoinOutputs(ts))
+
+	// Notify subscribers and broadcast the transaction sets.
 	go tp.gateway.Broadcast("RelayTransactionSet", ts, tp.gateway.Peers())
+	for _, set := range promoted {
+		go tp.gateway.Broadcast("RelayTransactionSet", set, tp.gateway.Peers())
+	}
 	tp.updateSubscribersTransactions()
 	return nil
 }
 
+// AcceptTransaction adds a transaction to the unconfirmed set of
+// transactions. If the transaction is accepted, it will be relayed to
+// connected peers.
+func (tp *TransactionPool) AcceptTransactionSet(ts []types.Transaction) error {
+	return tp.acceptTransactionSetFrom(ts, "")
+}
+
 // relayTransactionSet is an RPC that accepts a transaction set from a peer. If
 // the accept is successful, the transaction will be relayed to the gateway's
 // other peers.
@@ -324,5 +488,5 @@ func (tp *TransactionPool) relayTransactionSet(conn modules.PeerConn) error {
 	if err != nil {
 		return err
 	}
-	return tp.AcceptTransactionSet(ts)
+	return tp.acceptTransactionSetFrom(ts, modules.NetAddress(conn.RemoteAddr().String()))
 }
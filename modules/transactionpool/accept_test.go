@@ -2,6 +2,7 @@ package transactionpool
 
 import (
 	"crypto/rand"
+	"fmt"
 	"testing"
 
 	"github.com/NebulousLabs/Sia/modules"
@@ -102,15 +103,128 @@ func TestIntegrationConflictingTransactionSets(t *testing.T) {
 		t.Error("transaction should not have passed inspection")
 	}
 
-	// Purge and try the sets in the reverse order.
+	// Purge and try the sets in the reverse order. The set paying more fees
+	// should replace the double spend.
 	tpt.tpool.PurgeTransactionPool()
 	err = tpt.tpool.AcceptTransactionSet(txnSetDoubleSpend)
 	if err != nil {
 		t.Error(err)
 	}
 	err = tpt.tpool.AcceptTransactionSet(txnSet)
-	if err == nil {
-		t.Error("transaction should not have passed inspection")
+	if err != nil {
+		t.Error("higher fee transaction set should replace the double spend:", err)
+	}
+	txns := tpt.tpool.TransactionList()
+	if len(txns) != len(txnSet) || txns[len(txns)-1].ID() != txnSet[txnIndex].ID() {
+		t.Error("the transaction pool does not contain the replacement set")
+	}
+}
+
+// TestIntegrationReplacementFees checks that a transaction set double spending
+// a set in the pool is only accepted when it pays strictly more fees.
+func TestIntegrationReplacementFees(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	tpt, err := createTpoolTester("TestIntegrationReplacementFees")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tpt.Close()
+
+	// Create four sets spending the same output, paying fees of 2, 1, 2, and
+	// 3 siacoins.
+	fund := types.SiacoinPrecision.Mul64(10)
+	txnBuilder := tpt.wallet.StartTransaction()
+	err = txnBuilder.FundSiacoins(fund)
+	if err != nil {
+		t.Fatal(err)
+	}
+	txnSet, err := txnBuilder.Sign(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	txnIndex := len(txnSet) - 1
+	createSet := func(fee types.Currency, dest types.UnlockHash) []types.Transaction {
+		set := make([]types.Transaction, len(txnSet))
+		copy(set, txnSet)
+		set[txnIndex].MinerFees = append(set[txnIndex].MinerFees, fee)
+		set[txnIndex].SiacoinOutputs = append(set[txnIndex].SiacoinOutputs, types.SiacoinOutput{Value: fund.Sub(fee), UnlockHash: dest})
+		return set
+	}
+	firstSet := createSet(types.SiacoinPrecision.Mul64(2), types.UnlockHash{1})
+	lowSet := createSet(types.SiacoinPrecision, types.UnlockHash{2})
+	equalSet := createSet(types.SiacoinPrecision.Mul64(2), types.UnlockHash{3})
+	highSet := createSet(types.SiacoinPrecision.Mul64(3), types.UnlockHash{4})
+
+	err = tpt.tpool.AcceptTransactionSet(firstSet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tpt.tpool.AcceptTransactionSet(lowSet)
+	if err != modules.ErrDoubleSpend {
+		t.Fatal("expecting modules.ErrDoubleSpend, got", err)
+	}
+	err = tpt.tpool.AcceptTransactionSet(equalSet)
+	if err != modules.ErrDoubleSpend {
+		t.Fatal("expecting modules.ErrDoubleSpend, got", err)
+	}
+	err = tpt.tpool.AcceptTransactionSet(highSet)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Only the high fee set should make it into a block.
+	_, err = tpt.miner.AddBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tpt.tpool.TransactionList()) != 0 {
+		t.Error("transaction pool should be empty after mining a block")
+	}
+}
+
+// TestIntegrationDoubleSpend submits two transactions spending the same
+// output and checks that the second one is rejected.
+func TestIntegrationDoubleSpend(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	tpt, err := createTpoolTester("TestIntegrationDoubleSpend")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tpt.Close()
+
+	// Create two fee-less sets spending the same output to different
+	// addresses.
+	fund := types.SiacoinPrecision.Mul64(10)
+	txnBuilder := tpt.wallet.StartTransaction()
+	err = txnBuilder.FundSiacoins(fund)
+	if err != nil {
+		t.Fatal(err)
+	}
+	txnSet, err := txnBuilder.Sign(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	txnIndex := len(txnSet) - 1
+	doubleSpendSet := make([]types.Transaction, len(txnSet))
+	copy(doubleSpendSet, txnSet)
+	txnSet[txnIndex].SiacoinOutputs = append(txnSet[txnIndex].SiacoinOutputs, types.SiacoinOutput{Value: fund, UnlockHash: types.UnlockHash{1}})
+	doubleSpendSet[txnIndex].SiacoinOutputs = append(doubleSpendSet[txnIndex].SiacoinOutputs, types.SiacoinOutput{Value: fund, UnlockHash: types.UnlockHash{2}})
+
+	err = tpt.tpool.AcceptTransactionSet(txnSet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tpt.tpool.AcceptTransactionSet(doubleSpendSet)
+	if err != modules.ErrDoubleSpend {
+		t.Fatal("expecting ErrDoubleSpend, got", err)
+	}
+	txns := tpt.tpool.TransactionList()
+	if len(txns) != len(txnSet) || txns[len(txns)-1].ID() != txnSet[txnIndex].ID() {
+		t.Error("the first transaction set should remain in the pool")
 	}
 }
 
@@ -157,6 +271,53 @@ func TestIntegrationCheckMinerFees(t *testing.T) {
 	// TODO: fill the pool up all the way and try again.
 }
 
+// TestIntegrationMinRelayFee checks that transaction sets paying less than
+// the minimum relay fee are rejected, and that sets paying more are accepted.
+func TestIntegrationMinRelayFee(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	tpt, err := createTpoolTester("TestIntegrationMinRelayFee")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tpt.Close()
+
+	// Set the minimum relay fee to 1 SC per kb.
+	tpt.tpool.SetMinRelayFee(types.SiacoinPrecision.Div64(1e3))
+
+	// A transaction without fees should be rejected.
+	arbData := append(modules.PrefixNonSia[:], []byte("no fee")...)
+	err = tpt.tpool.AcceptTransactionSet([]types.Transaction{{ArbitraryData: [][]byte{arbData}}})
+	if err != modules.ErrLowFee {
+		t.Fatal("expecting ErrLowFee, got", err)
+	}
+
+	// A transaction set paying more than the minimum should be accepted.
+	fee := types.SiacoinPrecision.Mul64(10)
+	txnBuilder := tpt.wallet.StartTransaction()
+	err = txnBuilder.FundSiacoins(fee)
+	if err != nil {
+		t.Fatal(err)
+	}
+	txnBuilder.AddMinerFee(fee)
+	txnSet, err := txnBuilder.Sign(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tpt.tpool.AcceptTransactionSet(txnSet)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Disabling the minimum relay fee allows fee-less transactions again.
+	tpt.tpool.SetMinRelayFee(types.ZeroCurrency)
+	err = tpt.tpool.AcceptTransactionSet([]types.Transaction{{ArbitraryData: [][]byte{arbData}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
 // TestTransactionSuperset submits a single transaction to the network,
 // followed by a transaction set containing that single transaction.
 func TestIntegrationTransactionSuperset(t *testing.T) {
@@ -192,6 +353,9 @@ func TestIntegrationTransactionSuperset(t *testing.T) {
 	if err == nil {
 		t.Fatal("transaction set must have dependent transactions")
 	}
+	// Clear the orphan pool so that the child is not promoted automatically
+	// when the parent arrives.
+	tpt.tpool.orphans = newOrphanPool()
 
 	// Submit the first transaction in the set to the transaction pool, and
 	// then the superset.
@@ -251,6 +415,9 @@ func TestIntegrationTransactionSubset(t *testing.T) {
 	if err == nil {
 		t.Fatal("transaction set must have dependent transactions")
 	}
+	// Clear the orphan pool so that the child is not promoted automatically
+	// when the parent arrives.
+	tpt.tpool.orphans = newOrphanPool()
 
 	// Submit the set to the pool, followed by just the transaction.
 	err = tpt.tpool.AcceptTransactionSet(txnSet)
@@ -298,6 +465,9 @@ func TestIntegrationTransactionChild(t *testing.T) {
 	if err == nil {
 		t.Fatal("transaction set must have dependent transactions")
 	}
+	// Clear the orphan pool so that the child is not promoted automatically
+	// when the parent arrives.
+	tpt.tpool.orphans = newOrphanPool()
 
 	// Submit the first transaction in the set to the transaction pool.
 	err = tpt.tpool.AcceptTransactionSet(txnSet[:1])
@@ -310,6 +480,140 @@ func TestIntegrationTransactionChild(t *testing.T) {
 	}
 }
 
+// TestIntegrationAtomicTransactionSet checks that a parent and child
+// transaction are accepted together as a set, and that a set with an invalid
+// member is rejected entirely.
+func TestIntegrationAtomicTransactionSet(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	tpt, err := createTpoolTester("TestIntegrationAtomicTransactionSet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tpt.Close()
+
+	// createSet creates a transaction set of a parent and a child that spends
+	// an output of the parent.
+	createSet := func() ([]types.Transaction, modules.TransactionBuilder) {
+		fund := types.NewCurrency64(30e6)
+		txnBuilder := tpt.wallet.StartTransaction()
+		err := txnBuilder.FundSiacoins(fund)
+		if err != nil {
+			t.Fatal(err)
+		}
+		txnBuilder.AddMinerFee(fund)
+		txnSet, err := txnBuilder.Sign(true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(txnSet) != 2 {
+			t.Fatal("test is invalid unless the transaction set has a parent and a child")
+		}
+		return txnSet, txnBuilder
+	}
+
+	// A set with an invalid child should be rejected without accepting the
+	// parent.
+	txnSet, txnBuilder := createSet()
+	txnSet[1].TransactionSignatures[0].Signature[0]++
+	err = tpt.tpool.AcceptTransactionSet(txnSet)
+	if err == nil {
+		t.Fatal("transaction set with an invalid member was accepted")
+	}
+	if len(tpt.tpool.TransactionList()) != 0 {
+		t.Fatal("part of an invalid transaction set was accepted")
+	}
+	// Release the outputs of the rejected set so that they can be reused.
+	txnBuilder.Drop()
+
+	// A valid parent and child should be accepted together.
+	txnSet, _ = createSet()
+	err = tpt.tpool.AcceptTransactionSet(txnSet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tpt.tpool.TransactionList()) != 2 {
+		t.Fatal("expected the parent and child in the transaction pool, got", len(tpt.tpool.TransactionList()))
+	}
+}
+
+// TestIntegrationTransactionChildMined submits a parent and then a child
+// spending the parent's output as separate sets, and checks that both are
+// mined, with the parent ahead of the child.
+func TestIntegrationTransactionChildMined(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	tpt, err := createTpoolTester("TestIntegrationTransactionChildMined")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tpt.Close()
+
+	// Create a parent and child transaction.
+	fund := types.NewCurrency64(30e6)
+	txnBuilder := tpt.wallet.StartTransaction()
+	err = txnBuilder.FundSiacoins(fund)
+	if err != nil {
+		t.Fatal(err)
+	}
+	txnBuilder.AddMinerFee(fund)
+	txnSet, err := txnBuilder.Sign(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(txnSet) != 2 {
+		t.Fatal("test is invalid unless the transaction set has a parent and a child")
+	}
+	parent, child := txnSet[0], txnSet[1]
+
+	// Submit the parent and the child separately.
+	err = tpt.tpool.AcceptTransactionSet([]types.Transaction{parent})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tpt.tpool.AcceptTransactionSet([]types.Transaction{child})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The transaction list should present the parent before the child.
+	parentIndex, childIndex := -1, -1
+	for i, txn := range tpt.tpool.TransactionList() {
+		if txn.ID() == parent.ID() {
+			parentIndex = i
+		}
+		if txn.ID() == child.ID() {
+			childIndex = i
+		}
+	}
+	if parentIndex == -1 || childIndex == -1 || parentIndex > childIndex {
+		t.Fatal("transaction list has the wrong order:", parentIndex, childIndex)
+	}
+
+	// Mine a block, both transactions should be confirmed in order.
+	block, err := tpt.miner.AddBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	parentIndex, childIndex = -1, -1
+	for i, txn := range block.Transactions {
+		if txn.ID() == parent.ID() {
+			parentIndex = i
+		}
+		if txn.ID() == child.ID() {
+			childIndex = i
+		}
+	}
+	if parentIndex == -1 || childIndex == -1 || parentIndex > childIndex {
+		t.Fatal("block has the wrong transactions or order:", parentIndex, childIndex)
+	}
+	if len(tpt.tpool.TransactionList()) != 0 {
+		t.Error("transaction pool should be empty after the transactions are mined")
+	}
+}
+
 // TestIntegrationNilAccept tries submitting a nil transaction set and a 0-len
 // transaction set to the transaction pool.
 func TestIntegrationNilAccept(t *testing.T) {
@@ -595,3 +899,148 @@ func TestPartialConfirmationWeave(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+// TestIntegrationOrphanPromotion submits a child before its parent, and checks
+// that the child is promoted into the transaction pool once the parent
+// arrives.
+func TestIntegrationOrphanPromotion(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	tpt, err := createTpoolTester("TestIntegrationOrphanPromotion")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tpt.Close()
+
+	// Create a parent and a child transaction.
+	fund := types.NewCurrency64(30e6)
+	txnBuilder := tpt.wallet.StartTransaction()
+	err = txnBuilder.FundSiacoins(fund)
+	if err != nil {
+		t.Fatal(err)
+	}
+	txnBuilder.AddMinerFee(fund)
+	txnSet, err := txnBuilder.Sign(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(txnSet) != 2 {
+		t.Fatal("test is invalid unless the transaction set has a parent and a child")
+	}
+
+	// Submit the child first, it should be held as an orphan.
+	err = tpt.tpool.AcceptTransactionSet(txnSet[1:])
+	if err != errOrphanConflict {
+		t.Fatal("expecting errOrphanConflict, got", err)
+	}
+	if len(tpt.tpool.TransactionList()) != 0 {
+		t.Fatal("orphan should not be in the transaction pool")
+	}
+	if len(tpt.tpool.orphans.sets) != 1 {
+		t.Fatal("child should be held in the orphan pool")
+	}
+
+	// Submit the parent, the child should be promoted.
+	err = tpt.tpool.AcceptTransactionSet(txnSet[:1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	txns := tpt.tpool.TransactionList()
+	if len(txns) != 2 || txns[0].ID() != txnSet[0].ID() || txns[1].ID() != txnSet[1].ID() {
+		t.Fatal("child was not promoted into the transaction pool")
+	}
+	if len(tpt.tpool.orphans.sets) != 0 {
+		t.Error("orphan pool should be empty after the promotion")
+	}
+
+	// Both transactions should be mined.
+	_, err = tpt.miner.AddBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tpt.tpool.TransactionList()) != 0 {
+		t.Error("transaction pool should be empty after mining")
+	}
+}
+
+// TestOrphanPoolLimits checks that orphan sets are indexed by the outputs
+// they are missing, and that the per-peer and total size limits of the orphan
+// pool are enforced.
+func TestOrphanPoolLimits(t *testing.T) {
+	tp := &TransactionPool{orphans: newOrphanPool()}
+
+	// orphan returns a transaction set that spends a random output and has
+	// roughly the given size.
+	orphan := func(size int) []types.Transaction {
+		var parentID types.SiacoinOutputID
+		rand.Read(parentID[:])
+		data := make([]byte, size)
+		return []types.Transaction{{
+			SiacoinInputs: []types.SiacoinInput{{ParentID: parentID}},
+			ArbitraryData: [][]byte{data},
+		}}
+	}
+
+	// An orphan should be indexed by its missing parent, and should only be
+	// retried when that parent appears.
+	peer := modules.NetAddress("1.2.3.4:5")
+	ts := orphan(10)
+	tp.addOrphan(ts, peer)
+	parentID := ts[0].SiacoinInputs[0].ParentID
+	if len(tp.orphans.parents[parentID]) != 1 {
+		t.Fatal("orphan was not indexed by its missing parent")
+	}
+	var unrelated types.SiacoinOutputID
+	rand.Read(unrelated[:])
+	if len(tp.promoteOrphans([]types.SiacoinOutputID{unrelated})) != 0 || len(tp.orphans.sets) != 1 {
+		t.Fatal("orphan should not be affected by an unrelated output")
+	}
+	tp.orphans = newOrphanPool()
+
+	// A single peer can only fill maxPeerOrphanSize; the oldest orphan of
+	// the peer is discarded to make room.
+	var first []types.Transaction
+	for i := 0; i < 4; i++ {
+		ts := orphan(30e3)
+		if i == 0 {
+			first = ts
+		}
+		tp.addOrphan(ts, peer)
+	}
+	if len(tp.orphans.sets) != 3 {
+		t.Fatal("expecting 3 orphans from the peer, got", len(tp.orphans.sets))
+	}
+	if tp.orphans.peerSize[peer] > maxPeerOrphanSize {
+		t.Error("peer exceeded the orphan size limit")
+	}
+	if len(tp.orphans.parents[first[0].SiacoinInputs[0].ParentID]) != 0 {
+		t.Error("oldest orphan of the peer was not discarded")
+	}
+
+	// An orphan that is too large for a single peer is not held at all.
+	tp.addOrphan(orphan(maxPeerOrphanSize), modules.NetAddress("2.3.4.5:6"))
+	if len(tp.orphans.sets) != 3 {
+		t.Error("oversized orphan was added to the orphan pool")
+	}
+
+	// Many peers together can only fill maxOrphanPoolSize.
+	tp.orphans = newOrphanPool()
+	for i := 0; i < 20; i++ {
+		tp.addOrphan(orphan(90e3), modules.NetAddress(fmt.Sprintf("10.0.0.%d:1", i)))
+		if tp.orphans.size > maxOrphanPoolSize {
+			t.Fatal("orphan pool exceeded its size limit")
+		}
+	}
+	if len(tp.orphans.sets) == 0 || len(tp.orphans.sets) == 20 {
+		t.Error("unexpected number of orphans:", len(tp.orphans.sets))
+	}
+
+	// Removing every orphan should leave the indexes empty.
+	for id := range tp.orphans.sets {
+		tp.orphans.remove(id)
+	}
+	if len(tp.orphans.parents) != 0 || len(tp.orphans.peerSize) != 0 || tp.orphans.size != 0 {
+		t.Error("orphan pool indexes were not cleaned up")
+	}
+}
@@ -0,0 +1,131 @@
+package transactionpool
+
+import (
+	"sort"
+
+	"github.com/NebulousLabs/Sia/encoding"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// A feeDensity is the total miner fees paid by a transaction set together with
+// the size of the set in bytes.
+type feeDensity struct {
+	fees types.Currency
+	size int
+}
+
+// less returns true if fd pays fewer fees per byte than other.
+func (fd feeDensity) less(other feeDensity) bool {
+	return fd.fees.Mul64(uint64(other.size)).Cmp(other.fees.Mul64(uint64(fd.size))) < 0
+}
+
+// setFeeDensity returns the fee density of a transaction set.
+func setFeeDensity(ts []types.Transaction) feeDensity {
+	return feeDensity{
+		fees: transactionSetFees(ts),
+		size: len(encoding.Marshal(ts)),
+	}
+}
+
+// setsByDensity sorts transaction set ids by the fee density of the
+// corresponding sets, from lowest to highest.
+type setsByDensity struct {
+	ids       []TransactionSetID
+	densities map[TransactionSetID]feeDensity
+}
+
+func (sd setsByDensity) Len() int      { return len(sd.ids) }
+func (sd setsByDensity) Swap(i, j int) { sd.ids[i], sd.ids[j] = sd.ids[j], sd.ids[i] }
+func (sd setsByDensity) Less(i, j int) bool {
+	return sd.densities[sd.ids[i]].less(sd.densities[sd.ids[j]])
+}
+
+// evictionCandidates returns the ids of the transaction sets in the pool,
+// excluding the sets in 'exclude', sorted by fee density from lowest to
+// highest.
+func (tp *TransactionPool) evictionCandidates(exclude map[TransactionSetID]struct{}) ([]TransactionSetID, map[TransactionSetID]feeDensity) {
+	var ids []TransactionSetID
+	densities := make(map[TransactionSetID]feeDensity)
+	for id, ts := range tp.transactionSets {
+		if _, excluded := exclude[id]; excluded {
+			continue
+		}
+		ids = append(ids, id)
+		densities[id] = setFeeDensity(ts)
+	}
+	sort.Sort(setsByDensity{ids: ids, densities: densities})
+	return ids, densities
+}
+
+// evictSet removes a transaction set from the transaction pool, along with all
+// of the objects that point to the set.
+func (tp *TransactionPool) evictSet(id TransactionSetID) {
+	ts := tp.transactionSets[id]
+	for _, oid := range relatedObjectIDs(ts) {
+		if tp.knownObjects[oid] == id {
+			delete(tp.knownObjects, oid)
+		}
+	}
+	tp.transactionListSize -= len(encoding.Marshal(ts))
+	delete(tp.transactionSets, id)
+	delete(tp.transactionSetDiffs, id)
+}
+
+// makeRoom ensures that the transaction set 'ts' fits in the transaction pool
+// once the sets in 'replaced' have been removed. Sets with a lower fee density
+// than 'ts' are evicted until there is enough room. If there is not enough
+// room even after evicting all sets with a lower fee density, nothing is
+// evicted and errFullTransactionPool is returned.
+func (tp *TransactionPool) makeRoom(ts []types.Transaction, replaced map[TransactionSetID]struct{}) error {
+	newSize := tp.transactionListSize + len(encoding.Marshal(ts))
+	for id := range replaced {
+		newSize -= len(encoding.Marshal(tp.transactionSets[id]))
+	}
+	if newSize <= tp.sizeLimit {
+		return nil
+	}
+
+	// Plan the evictions before evicting anything, so that the pool is left
+	// untouched if the set is rejected.
+	density := setFeeDensity(ts)
+	candidates, densities := tp.evictionCandidates(replaced)
+	var evictions []TransactionSetID
+	for _, id := range candidates {
+		if newSize <= tp.sizeLimit {
+			break
+		}
+		if !densities[id].less(density) {
+			break
+		}
+		evictions = append(evictions, id)
+		newSize -= densities[id].size
+	}
+	if newSize > tp.sizeLimit {
+		return errFullTransactionPool
+	}
+	for _, id := range evictions {
+		tp.evictSet(id)
+	}
+	return nil
+}
+
+// SetSizeLimit sets the limit on the combined size of all transactions in the
+// transaction pool. If the pool exceeds the new limit, the transaction sets
+// with the lowest fee density are evicted until the pool is within the limit.
+func (tp *TransactionPool) SetSizeLimit(limit uint64) {
+	tp.mu.Lock()
+	defer tp.mu.Unlock()
+
+	tp.sizeLimit = int(limit)
+	if tp.transactionListSize <= tp.sizeLimit {
+		return
+	}
+	candidates, _ := tp.evictionCandidates(nil)
+	for _, id := range candidates {
+		if tp.transactionListSize <= tp.sizeLimit {
+			break
+		}
+		tp.evictSet(id)
+	}
+	tp.updateSubscribersTransactions()
+}
@@ -0,0 +1,90 @@
+package transactionpool
+
+import (
+	"testing"
+
+	"github.com/NebulousLabs/Sia/encoding"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// arbDataSet returns a transaction set containing a single fee-less
+// transaction with unique arbitrary data.
+func arbDataSet(i int) []types.Transaction {
+	return []types.Transaction{{
+		ArbitraryData: [][]byte{
+			append(modules.PrefixNonSia[:], encoding.Marshal(uint64(i))...),
+		},
+	}}
+}
+
+// TestIntegrationSizeLimitEviction fills the transaction pool with fee-less
+// transactions and checks that a transaction paying fees displaces them.
+func TestIntegrationSizeLimitEviction(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	tpt, err := createTpoolTester("TestIntegrationSizeLimitEviction")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tpt.Close()
+
+	// Fill the transaction pool with fee-less transactions, leaving no room
+	// for more.
+	setSize := len(encoding.Marshal(arbDataSet(0)))
+	tpt.tpool.SetSizeLimit(uint64(10 * setSize))
+	for i := 0; i < 10; i++ {
+		err = tpt.tpool.AcceptTransactionSet(arbDataSet(i))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Another fee-less transaction should be rejected, as it does not pay a
+	// higher fee density than any transaction in the pool.
+	err = tpt.tpool.AcceptTransactionSet(arbDataSet(10))
+	if err != errFullTransactionPool {
+		t.Fatal("expecting errFullTransactionPool, got", err)
+	}
+
+	// A transaction set paying fees should displace fee-less transactions.
+	fee := types.SiacoinPrecision.Mul64(10)
+	txnBuilder := tpt.wallet.StartTransaction()
+	err = txnBuilder.FundSiacoins(fee)
+	if err != nil {
+		t.Fatal(err)
+	}
+	txnBuilder.AddMinerFee(fee)
+	txnSet, err := txnBuilder.Sign(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tpt.tpool.AcceptTransactionSet(txnSet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	txns := tpt.tpool.TransactionList()
+	if len(txns) >= 10+len(txnSet) {
+		t.Fatal("no transactions were evicted")
+	}
+	found := false
+	for _, txn := range txns {
+		if txn.ID() == txnSet[len(txnSet)-1].ID() {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatal("fee paying transaction is not in the transaction pool")
+	}
+	if tpt.tpool.transactionListSize > 10*setSize {
+		t.Error("transaction pool exceeds its size limit")
+	}
+
+	// Lowering the size limit should evict the fee-less transactions first.
+	tpt.tpool.SetSizeLimit(uint64(len(encoding.Marshal(txnSet))))
+	txns = tpt.tpool.TransactionList()
+	if len(txns) != len(txnSet) || txns[len(txns)-1].ID() != txnSet[len(txnSet)-1].ID() {
+		t.Error("lowering the size limit did not evict the fee-less transactions")
+	}
+}
@@ -0,0 +1,222 @@
+package transactionpool
+
+import (
+	"time"
+
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/encoding"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+const (
+	// maxOrphanSets is the maximum number of orphan transaction sets that are
+	// held by the transaction pool. When the limit is reached, the oldest
+	// orphan set is discarded.
+	maxOrphanSets = 100
+
+	// maxOrphanPoolSize is the maximum combined size in bytes of the orphan
+	// transaction sets held by the transaction pool.
+	maxOrphanPoolSize = 1 << 20
+
+	// maxPeerOrphanSize is the maximum combined size in bytes of the orphan
+	// transaction sets held on behalf of a single peer. When the limit is
+	// reached, the oldest orphan set from that peer is discarded.
+	maxPeerOrphanSize = 100e3
+
+	// orphanExpiration is the amount of time that an orphan transaction set
+	// is held before being discarded.
+	orphanExpiration = 10 * time.Minute
+)
+
+var (
+	// errOrphanConflict is the error returned when a transaction set spends
+	// siacoin outputs that are unknown to the consensus set and the
+	// transaction pool.
+	errOrphanConflict = modules.NewConsensusConflict(modules.ErrMissingSiacoinOutput.Error())
+)
+
+type (
+	// An orphanSet is a transaction set that spends outputs which are not yet
+	// known to the transaction pool, along with the time it was received and
+	// the peer that sent it.
+	orphanSet struct {
+		transactions []types.Transaction
+		parents      []types.SiacoinOutputID
+		peer         modules.NetAddress
+		received     time.Time
+		size         int
+	}
+
+	// An orphanPool holds orphan transaction sets, indexed by the ids of the
+	// outputs that they spend but do not create. An orphan is only retried
+	// once one of those outputs appears.
+	orphanPool struct {
+		sets     map[TransactionSetID]orphanSet
+		parents  map[types.SiacoinOutputID]map[TransactionSetID]struct{}
+		peerSize map[modules.NetAddress]int
+		size     int
+	}
+)
+
+// newOrphanPool returns an empty orphan pool.
+func newOrphanPool() orphanPool {
+	return orphanPool{
+		sets:     make(map[TransactionSetID]orphanSet),
+		parents:  make(map[types.SiacoinOutputID]map[TransactionSetID]struct{}),
+		peerSize: make(map[modules.NetAddress]int),
+	}
+}
+
+// createdSiacoinOutputs returns the ids of the siacoin outputs created by a
+// transaction set.
+func createdSiacoinOutputs(ts []types.Transaction) (ids []types.SiacoinOutputID) {
+	for _, txn := range ts {
+		for i := range txn.SiacoinOutputs {
+			ids = append(ids, txn.SiacoinOutputID(uint64(i)))
+		}
+	}
+	return ids
+}
+
+// externalParents returns the ids of the siacoin outputs spent by a
+// transaction set that are not created by the set itself.
+func externalParents(ts []types.Transaction) (ids []types.SiacoinOutputID) {
+	created := make(map[types.SiacoinOutputID]struct{})
+	for _, id := range createdSiacoinOutputs(ts) {
+		created[id] = struct{}{}
+	}
+	for _, txn := range ts {
+		for _, sci := range txn.SiacoinInputs {
+			if _, exists := created[sci.ParentID]; !exists {
+				ids = append(ids, sci.ParentID)
+			}
+		}
+	}
+	return ids
+}
+
+// oldest returns the id of the oldest orphan set, considering only the sets
+// from the given peer if fromPeer is set.
+func (op *orphanPool) oldest(peer modules.NetAddress, fromPeer bool) (oldestID TransactionSetID) {
+	var oldest time.Time
+	for id, orphan := range op.sets {
+		if fromPeer && orphan.peer != peer {
+			continue
+		}
+		if oldest.IsZero() || orphan.received.Before(oldest) {
+			oldestID = id
+			oldest = orphan.received
+		}
+	}
+	return oldestID
+}
+
+// remove deletes an orphan set from the orphan pool.
+func (op *orphanPool) remove(id TransactionSetID) {
+	orphan, exists := op.sets[id]
+	if !exists {
+		return
+	}
+	delete(op.sets, id)
+	for _, parent := range orphan.parents {
+		delete(op.parents[parent], id)
+		if len(op.parents[parent]) == 0 {
+			delete(op.parents, parent)
+		}
+	}
+	op.size -= orphan.size
+	op.peerSize[orphan.peer] -= orphan.size
+	if op.peerSize[orphan.peer] <= 0 {
+		delete(op.peerSize, orphan.peer)
+	}
+}
+
+// removeExpired deletes every orphan set that has been held for longer than
+// orphanExpiration.
+func (op *orphanPool) removeExpired() {
+	for id, orphan := range op.sets {
+		if time.Since(orphan.received) > orphanExpiration {
+			op.remove(id)
+		}
+	}
+}
+
+// addOrphan adds a transaction set received from a peer to the orphan pool.
+// If the set would exceed the limits of the orphan pool, the oldest orphan
+// sets are discarded first, starting with the oldest sets of the same peer.
+func (tp *TransactionPool) addOrphan(ts []types.Transaction, peer modules.NetAddress) {
+	op := &tp.orphans
+	setID := TransactionSetID(crypto.HashObject(ts))
+	if _, exists := op.sets[setID]; exists {
+		return
+	}
+	parents := externalParents(ts)
+	size := len(encoding.Marshal(ts))
+	if len(parents) == 0 || size > maxPeerOrphanSize {
+		return
+	}
+
+	op.removeExpired()
+	for op.peerSize[peer]+size > maxPeerOrphanSize {
+		op.remove(op.oldest(peer, true))
+	}
+	for len(op.sets) >= maxOrphanSets || op.size+size > maxOrphanPoolSize {
+		op.remove(op.oldest(peer, false))
+	}
+
+	op.sets[setID] = orphanSet{
+		transactions: ts,
+		parents:      parents,
+		peer:         peer,
+		received:     time.Now(),
+		size:         size,
+	}
+	for _, parent := range parents {
+		if op.parents[parent] == nil {
+			op.parents[parent] = make(map[TransactionSetID]struct{})
+		}
+		op.parents[parent][setID] = struct{}{}
+	}
+	op.size += size
+	op.peerSize[peer] += size
+}
+
+// promoteOrphans tries to move the orphan transaction sets that spend any of
+// the given outputs into the transaction pool, returning the sets that were
+// accepted. Orphans that spend none of the outputs are not retried. Orphans
+// that have expired or that have become invalid for reasons other than
+// missing parents are discarded. The outputs created by a promoted orphan
+// may be spent by other orphans, so those are retried as well.
+func (tp *TransactionPool) promoteOrphans(outputs []types.SiacoinOutputID) (promoted [][]types.Transaction) {
+	op := &tp.orphans
+	for len(outputs) > 0 {
+		parent := outputs[0]
+		outputs = outputs[1:]
+
+		var ids []TransactionSetID
+		for id := range op.parents[parent] {
+			ids = append(ids, id)
+		}
+		for _, id := range ids {
+			orphan, exists := op.sets[id]
+			if !exists {
+				continue
+			}
+			if time.Since(orphan.received) > orphanExpiration {
+				op.remove(id)
+				continue
+			}
+			err := tp.acceptTransactionSet(orphan.transactions)
+			if err == errOrphanConflict {
+				continue
+			}
+			op.remove(id)
+			if err == nil {
+				promoted = append(promoted, orphan.transactions)
+				outputs = append(outputs, createdSiacoinOutputs(orphan.transactions)...)
+			}
+		}
+	}
+	return promoted
+}
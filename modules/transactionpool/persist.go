@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/NebulousLabs/Sia/encoding"
 	"github.com/NebulousLabs/Sia/modules"
 	"github.com/NebulousLabs/Sia/persist"
 	"github.com/NebulousLabs/Sia/types"
@@ -21,6 +22,11 @@ var (
 	// been confirmed on the blockchain.
 	bucketConfirmedTransactions = []byte("ConfirmedTransactions")
 
+	// bucketUnconfirmedSets holds the unconfirmed transaction sets that were
+	// in the transaction pool when it was last closed. The sets are keyed by
+	// their TransactionSetID.
+	bucketUnconfirmedSets = []byte("UnconfirmedSets")
+
 	// errNilConsensusChange is returned if there is no consensus change in the
 	// database.
 	errNilConsensusChange = errors.New("no consensus change found")
@@ -65,6 +71,7 @@ func (tp *TransactionPool) initPersist() error {
 		buckets := [][]byte{
 			bucketRecentConsensusChange,
 			bucketConfirmedTransactions,
+			bucketUnconfirmedSets,
 		}
 		for _, bucket := range buckets {
 			_, err := tx.CreateBucketIfNotExists(bucket)
@@ -95,9 +102,64 @@ func (tp *TransactionPool) initPersist() error {
 		if resetErr != nil {
 			return resetErr
 		}
-		return tp.consensusSet.ConsensusSetSubscribe(tp, modules.ConsensusChangeBeginning)
+		err = tp.consensusSet.ConsensusSetSubscribe(tp, modules.ConsensusChangeBeginning)
 	}
-	return err
+	if err != nil {
+		return err
+	}
+
+	// Now that the transaction pool is caught up with the consensus set,
+	// restore the transaction sets that were saved at shutdown.
+	return tp.loadUnconfirmedSets()
+}
+
+// loadUnconfirmedSets re-adds the transaction sets that were saved when the
+// transaction pool was last closed. Each set is validated against the current
+// consensus set; transactions that have since been confirmed are stripped, and
+// sets that are no longer valid are dropped.
+func (tp *TransactionPool) loadUnconfirmedSets() error {
+	var sets [][]types.Transaction
+	err := tp.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketUnconfirmedSets).ForEach(func(_, setBytes []byte) error {
+			var set []types.Transaction
+			err := encoding.Unmarshal(setBytes, &set)
+			if err != nil {
+				return err
+			}
+			sets = append(sets, set)
+			return nil
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	tp.mu.Lock()
+	defer tp.mu.Unlock()
+	for _, set := range sets {
+		tp.acceptTransactionSet(set) // Error is not checked.
+	}
+	return nil
+}
+
+// saveUnconfirmedSets replaces the saved transaction sets with the sets
+// currently in the transaction pool.
+func (tp *TransactionPool) saveUnconfirmedSets(tx *bolt.Tx) error {
+	err := tx.DeleteBucket(bucketUnconfirmedSets)
+	if err != nil {
+		return err
+	}
+	b, err := tx.CreateBucket(bucketUnconfirmedSets)
+	if err != nil {
+		return err
+	}
+	for setID, set := range tp.transactionSets {
+		err = b.Put(setID[:], encoding.Marshal(set))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // getRecentConsensusChange returns the most recent consensus change from the
@@ -93,3 +93,103 @@ func TestRescan(t *testing.T) {
 		t.Fatal("expecting modules.ErrDuplicateTransactionSet, got:", err)
 	}
 }
+
+// TestRestoreUnconfirmedSets checks that the transaction pool saves its
+// unconfirmed transactions at shutdown, and that on startup the still-valid
+// transactions are restored while confirmed transactions are dropped.
+func TestRestoreUnconfirmedSets(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+
+	tpt, err := createTpoolTester("TestRestoreUnconfirmedSets")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tpt.Close()
+
+	// Put a transaction set into the pool and close the pool.
+	confirmedTxns, err := tpt.wallet.SendSiacoins(types.NewCurrency64(100), types.UnlockHash{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	persistDir := tpt.tpool.persistDir
+	err = tpt.tpool.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Mine the set into a block while the pool is down, then restart the
+	// pool. The set has been confirmed, and should not be restored.
+	b, err := tpt.miner.FindBlockWithTransactions(confirmedTxns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tpt.cs.AcceptBlock(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tpt.tpool, err = New(tpt.cs, tpt.gateway, persistDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tpt.tpool.Snapshot()) != 0 {
+		t.Fatal("confirmed transactions were restored to the pool")
+	}
+
+	// Add a new transaction set, restart the pool, and check that the set was
+	// restored. The wallet is still subscribed to the old pool, so the set is
+	// built by hand and given to the new pool directly.
+	builder := tpt.wallet.StartTransaction()
+	err = builder.FundSiacoins(types.NewCurrency64(100))
+	if err != nil {
+		t.Fatal(err)
+	}
+	builder.AddSiacoinOutput(types.SiacoinOutput{Value: types.NewCurrency64(100)})
+	txns, err := builder.Sign(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tpt.tpool.AcceptTransactionSet(txns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	snapshot := tpt.tpool.Snapshot()
+	if len(snapshot) != len(txns) {
+		t.Fatal("snapshot has the wrong number of transactions:", len(snapshot), len(txns))
+	}
+	err = tpt.tpool.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tpt.tpool, err = New(tpt.cs, tpt.gateway, persistDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored := tpt.tpool.Snapshot()
+	if len(restored) != len(txns) {
+		t.Fatal("transactions were not restored to the pool:", len(restored), len(txns))
+	}
+	restoredIDs := make(map[types.TransactionID]struct{})
+	for _, txn := range restored {
+		restoredIDs[txn.ID()] = struct{}{}
+	}
+	for _, txn := range txns {
+		if _, exists := restoredIDs[txn.ID()]; !exists {
+			t.Error("transaction missing from the restored pool")
+		}
+	}
+
+	// The restored transactions should still be valid in a block.
+	b, err = tpt.miner.FindBlockWithTransactions(restored)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tpt.cs.AcceptBlock(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tpt.tpool.Snapshot()) != 0 {
+		t.Error("restored transactions were not confirmed")
+	}
+}
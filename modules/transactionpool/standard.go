@@ -36,6 +36,12 @@ import (
 // Rule: The transaction set size is limited.
 //		A group of dependent transactions cannot exceed 100kb to limit how
 //		quickly the transaction pool can be filled with new transactions.
+//
+// Rule: Siacoin outputs cannot be dust.
+//		Every unspent output has to be tracked by every node forever. Outputs
+//		below the DustThreshold cost more to spend than they are worth, and
+//		would likely never be spent. Zero value outputs are already rejected
+//		by the consensus rules.
 
 // checkUnlockConditions looks at the UnlockConditions and verifies that all
 // public keys are recognized. Unrecognized public keys are automatically
@@ -91,6 +97,13 @@ func (tp *TransactionPool) IsStandardTransaction(t types.Transaction) error {
 		}
 	}
 
+	// Check that the transaction does not create any dust outputs.
+	for _, sco := range t.SiacoinOutputs {
+		if sco.Value.Cmp(tp.dustThreshold) < 0 {
+			return modules.ErrDustOutput
+		}
+	}
+
 	// Check that all arbitrary data is prefixed using the recognized set of
 	// prefixes. The allowed prefixes include a 'NonSia' prefix for truly
 	// arbitrary data. Blocking all other prefixes allows arbitrary data to be
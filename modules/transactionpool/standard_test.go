@@ -51,3 +51,57 @@ func TestIntegrationLargeTransactions(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+// TestIntegrationDustOutputs checks that transactions creating siacoin
+// outputs below the dust threshold are rejected, while outputs at exactly the
+// threshold are accepted.
+func TestIntegrationDustOutputs(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	tpt, err := createTpoolTester("TestIntegrationDustOutputs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tpt.Close()
+
+	// Use a threshold that is large enough for a nonzero output to fall
+	// below it.
+	dust := types.SiacoinPrecision
+	tpt.tpool.dustThreshold = dust
+
+	// sendOutput creates a transaction set containing a siacoin output of the
+	// given value and submits it to the transaction pool. The rest of the
+	// funds are sent to a second output that is never dust.
+	sendOutput := func(value types.Currency) error {
+		fund := dust.Mul64(2)
+		txnBuilder := tpt.wallet.StartTransaction()
+		err := txnBuilder.FundSiacoins(fund)
+		if err != nil {
+			t.Fatal(err)
+		}
+		txnBuilder.AddSiacoinOutput(types.SiacoinOutput{Value: value})
+		txnBuilder.AddSiacoinOutput(types.SiacoinOutput{Value: fund.Sub(value)})
+		txnSet, err := txnBuilder.Sign(true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = tpt.tpool.AcceptTransactionSet(txnSet)
+		if err != nil {
+			txnBuilder.Drop()
+		}
+		return err
+	}
+
+	// A nonzero output below the threshold is rejected.
+	err = sendOutput(dust.Sub(types.NewCurrency64(1)))
+	if err != modules.ErrDustOutput {
+		t.Fatal("expecting ErrDustOutput, got", err)
+	}
+
+	// An output at exactly the threshold is accepted.
+	err = sendOutput(dust)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
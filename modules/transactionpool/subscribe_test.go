@@ -69,3 +69,45 @@ func TestSubscription(t *testing.T) {
 		t.Error("transaction pool failed to unsubscribe mock subscriber")
 	}
 }
+
+// TestSubscriberReceivesAcceptedSet checks that a subscriber is handed a
+// transaction set as soon as the transaction pool accepts it, which is the
+// hook used to relay transactions to peers.
+func TestSubscriberReceivesAcceptedSet(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	tpt, err := createTpoolTester("TestSubscriberReceivesAcceptedSet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tpt.Close()
+
+	ms := mockSubscriber{}
+	tpt.tpool.TransactionPoolSubscribe(&ms)
+
+	// Accept a transaction set directly and check that the subscriber
+	// received every transaction in it.
+	txnBuilder := tpt.wallet.StartTransaction()
+	err = txnBuilder.FundSiacoins(types.NewCurrency64(100))
+	if err != nil {
+		t.Fatal(err)
+	}
+	txnBuilder.AddMinerFee(types.NewCurrency64(100))
+	txnSet, err := txnBuilder.Sign(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tpt.tpool.AcceptTransactionSet(txnSet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ms.txns) != len(txnSet) {
+		t.Fatalf("subscriber should have received %v transactions, received %v", len(txnSet), len(ms.txns))
+	}
+	for i := range txnSet {
+		if ms.txns[i].ID() != txnSet[i].ID() {
+			t.Error("subscriber received the wrong transaction at index", i)
+		}
+	}
+}
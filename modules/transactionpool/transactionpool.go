@@ -56,6 +56,19 @@ type (
 		transactionSets     map[TransactionSetID][]types.Transaction
 		transactionSetDiffs map[TransactionSetID]modules.ConsensusChange
 		transactionListSize int
+		sizeLimit           int
+		minRelayFee         types.Currency
+
+		// dustThreshold is the smallest value of a siacoin output created by
+		// a standard transaction. It is modules.DustThreshold outside of
+		// tests.
+		dustThreshold types.Currency
+
+		// orphans holds transaction sets that spend outputs which are unknown
+		// to the transaction pool. A set is retried when one of the outputs
+		// it spends appears, and is promoted into the pool once all of its
+		// parents have arrived.
+		orphans orphanPool
 		// TODO: Write a consistency check comparing transactionSets,
 		// transactionSetDiffs.
 		//
@@ -93,6 +106,9 @@ func New(cs modules.ConsensusSet, g modules.Gateway, persistDir string) (*Transa
 		knownObjects:        make(map[ObjectID]TransactionSetID),
 		transactionSets:     make(map[TransactionSetID][]types.Transaction),
 		transactionSetDiffs: make(map[TransactionSetID]modules.ConsensusChange),
+		sizeLimit:           modules.TpoolSizeLimit,
+		dustThreshold:       modules.DustThreshold,
+		orphans:             newOrphanPool(),
 
 		persistDir: persistDir,
 	}
@@ -108,9 +124,20 @@ func New(cs modules.ConsensusSet, g modules.Gateway, persistDir string) (*Transa
 	return tp, nil
 }
 
+// Close saves the unconfirmed transaction sets and shuts down the transaction
+// pool. The saved sets are restored the next time the transaction pool is
+// started.
 func (tp *TransactionPool) Close() error {
 	tp.gateway.UnregisterRPC("RelayTransactionSet")
 	tp.consensusSet.Unsubscribe(tp)
+
+	tp.mu.RLock()
+	err := tp.db.Update(tp.saveUnconfirmedSets)
+	tp.mu.RUnlock()
+	if err != nil {
+		tp.db.Close()
+		return err
+	}
 	return tp.db.Close()
 }
 
@@ -141,3 +168,12 @@ func (tp *TransactionPool) TransactionList() []types.Transaction {
 	}
 	return txns
 }
+
+// Snapshot returns every transaction in the transaction pool. Unlike
+// TransactionList, Snapshot holds the transaction pool lock while the list is
+// being built.
+func (tp *TransactionPool) Snapshot() []types.Transaction {
+	tp.mu.RLock()
+	defer tp.mu.RUnlock()
+	return tp.TransactionList()
+}
@@ -111,6 +111,18 @@ func (tp *TransactionPool) ProcessConsensusChange(cc modules.ConsensusChange) {
 		tp.acceptTransactionSet(set) // Error is not checked.
 	}
 
+	// The new blocks may contain the parents of orphan transaction sets.
+	var created []types.SiacoinOutputID
+	for _, diff := range cc.SiacoinOutputDiffs {
+		if diff.Direction == modules.DiffApply {
+			created = append(created, diff.ID)
+		}
+	}
+	promoted := tp.promoteOrphans(created)
+	for _, set := range promoted {
+		go tp.gateway.Broadcast("RelayTransactionSet", set, tp.gateway.Peers())
+	}
+
 	// Inform subscribers that an update has executed.
 	tp.mu.Demote()
 	tp.updateSubscribersTransactions()
@@ -27,6 +27,21 @@ const (
 	WalletSeedPreloadDepth = 25
 )
 
+const (
+	// LargestFirst selects the largest outputs of the wallet first when
+	// funding a transaction, consolidating the wallet over time.
+	LargestFirst CoinSelectionStrategy = iota
+
+	// SmallestFirst selects the smallest outputs of the wallet first when
+	// funding a transaction, which reduces the number of small outputs in the
+	// wallet at the cost of larger transactions.
+	SmallestFirst
+
+	// MinimizeChange selects the set of outputs whose sum is closest to the
+	// amount being funded, reducing the size of the change output.
+	MinimizeChange
+)
+
 var (
 	// ErrBadEncryptionKey is returned if the incorrect encryption key to a
 	// file is provided.
@@ -48,6 +63,10 @@ var (
 )
 
 type (
+	// CoinSelectionStrategy determines which outputs the wallet uses when
+	// funding a transaction.
+	CoinSelectionStrategy int
+
 	// Seed is cryptographic entropy that is used to derive spendable wallet
 	// addresses.
 	Seed [crypto.EntropySize]byte
@@ -57,12 +76,14 @@ type (
 
 	// A ProcessedInput represents funding to a transaction. The input is
 	// coming from an address and going to the outputs. The fund types are
-	// 'SiacoinInput', 'SiafundInput'.
+	// 'SiacoinInput', 'SiafundInput'. RelatedAddressLabel is the label that
+	// the user attached to the related address, if any.
 	ProcessedInput struct {
-		FundType       types.Specifier  `json:"fundtype"`
-		WalletAddress  bool             `json:"walletaddress"`
-		RelatedAddress types.UnlockHash `json:"relatedaddress"`
-		Value          types.Currency   `json:"value"`
+		FundType            types.Specifier  `json:"fundtype"`
+		WalletAddress       bool             `json:"walletaddress"`
+		RelatedAddress      types.UnlockHash `json:"relatedaddress"`
+		RelatedAddressLabel string           `json:"relatedaddresslabel"`
+		Value               types.Currency   `json:"value"`
 	}
 
 	// A ProcessedOutput is a siacoin output that appears in a transaction.
@@ -77,12 +98,16 @@ type (
 	// MaturityHeight indicates at what block height the output becomes
 	// available. SiacoinInputs and SiafundInputs become available immediately.
 	// ClaimInputs and MinerPayouts become available after 144 confirmations.
+	//
+	// RelatedAddressLabel is the label that the user attached to the related
+	// address, if any.
 	ProcessedOutput struct {
-		FundType       types.Specifier   `json:"fundtype"`
-		MaturityHeight types.BlockHeight `json:"maturityheight"`
-		WalletAddress  bool              `json:"walletaddress"`
-		RelatedAddress types.UnlockHash  `json:"relatedaddress"`
-		Value          types.Currency    `json:"value"`
+		FundType            types.Specifier   `json:"fundtype"`
+		MaturityHeight      types.BlockHeight `json:"maturityheight"`
+		WalletAddress       bool              `json:"walletaddress"`
+		RelatedAddress      types.UnlockHash  `json:"relatedaddress"`
+		RelatedAddressLabel string            `json:"relatedaddresslabel"`
+		Value               types.Currency    `json:"value"`
 	}
 
 	// A ProcessedTransaction is a transaction that has been processed into
@@ -102,6 +127,22 @@ type (
 		Outputs []ProcessedOutput `json:"outputs"`
 	}
 
+	// A TransactionRecord summarizes the effect of a confirmed transaction on
+	// the wallet, and is used to export the wallet's history. The net change
+	// to the wallet's balance is the inflow minus the outflow. MinerFees is
+	// only counted if the wallet helped fund the transaction.
+	TransactionRecord struct {
+		TransactionID         types.TransactionID `json:"transactionid"`
+		ConfirmationHeight    types.BlockHeight   `json:"confirmationheight"`
+		ConfirmationTimestamp types.Timestamp     `json:"confirmationtimestamp"`
+
+		SiacoinInflow  types.Currency `json:"siacoininflow"`
+		SiacoinOutflow types.Currency `json:"siacoinoutflow"`
+		SiafundInflow  types.Currency `json:"siafundinflow"`
+		SiafundOutflow types.Currency `json:"siafundoutflow"`
+		MinerFees      types.Currency `json:"minerfees"`
+	}
+
 	// TransactionBuilder is used to construct custom transactions. A transaction
 	// builder is initialized via 'RegisterTransaction' and then can be modified by
 	// adding funds or other fields. The transaction is completed by calling
@@ -122,6 +163,10 @@ type (
 		// transaction failed.
 		FundSiacoins(amount types.Currency) error
 
+		// FundSiacoinsWithStrategy functions like FundSiacoins, but uses the
+		// provided strategy to select the outputs that fund the transaction.
+		FundSiacoinsWithStrategy(amount types.Currency, strategy CoinSelectionStrategy) error
+
 		// FundSiafunds will add a siafund input of exactly 'amount' to the
 		// transaction. A parent transaction may be needed to achieve an input
 		// with the correct value. The siafund input will not be signed until
@@ -149,6 +194,12 @@ type (
 		// the index of the siacoin output within the transaction.
 		AddSiacoinOutput(types.SiacoinOutput) uint64
 
+		// AddSiacoinOutputs adds a batch of siacoin outputs to the
+		// transaction, returning the indices of the outputs within the
+		// transaction. An error is returned and no outputs are added if the
+		// inputs of the transaction do not cover the new total.
+		AddSiacoinOutputs([]types.SiacoinOutput) ([]uint64, error)
+
 		// AddFileContract adds a file contract to the transaction, returning
 		// the index of the file contract within the transaction.
 		AddFileContract(types.FileContract) uint64
@@ -234,6 +285,12 @@ type (
 		// unlocked using the encryption password.
 		Encrypted() bool
 
+		// InitFromSeed functions like Encrypt, but using a specified seed
+		// instead of a randomly generated one. The blockchain is scanned for
+		// addresses generated by the seed, so that previously used addresses
+		// are recovered.
+		InitFromSeed(masterKey crypto.TwofishKey, seed Seed) error
+
 		// Lock deletes all keys in memory and prevents the wallet from being
 		// used to spend coins or extract keys until 'Unlock' is called.
 		Lock() error
@@ -316,6 +373,11 @@ type (
 		// refund transactions.
 		ConfirmedBalance() (siacoinBalance types.Currency, siafundBalance types.Currency, siacoinClaimBalance types.Currency)
 
+		// ImmatureBalance returns the value of the delayed siacoin outputs,
+		// such as miner payouts, that belong to the wallet but have not yet
+		// matured.
+		ImmatureBalance() types.Currency
+
 		// UnconfirmedBalance returns the unconfirmed balance of the wallet.
 		// Outgoing funds and incoming funds are reported separately. Refund
 		// outputs are included, meaning that sending a single coin to
@@ -323,6 +385,29 @@ type (
 		// not considered in the unconfirmed balance.
 		UnconfirmedBalance() (outgoingSiacoins types.Currency, incomingSiacoins types.Currency)
 
+		// Rescan rebuilds the wallet's outputs, balance, and transaction
+		// history by replaying the blockchain from the genesis block.
+		Rescan() error
+
+		// AddWatchAddress adds an address that the wallet will track without
+		// being able to spend from it.
+		AddWatchAddress(types.UnlockHash) error
+
+		// WatchedAddresses returns the addresses watched by the wallet.
+		WatchedAddresses() []types.UnlockHash
+
+		// WatchedBalance returns the confirmed balance of the watched
+		// addresses. The watched balance is not spendable by the wallet.
+		WatchedBalance() (siacoinBalance types.Currency, siafundBalance types.Currency)
+
+		// SetAddressLabel attaches a label to an address. An empty label
+		// removes the existing label.
+		SetAddressLabel(types.UnlockHash, string)
+
+		// AddressLabel returns the label attached to an address, or an empty
+		// string if the address has no label.
+		AddressLabel(types.UnlockHash) string
+
 		// AddressTransactions returns all of the transactions that are related
 		// to a given address.
 		AddressTransactions(types.UnlockHash) []ProcessedTransaction
@@ -336,19 +421,50 @@ type (
 		// wallet only stores transactions that are related to the wallet.
 		Transaction(types.TransactionID) (ProcessedTransaction, bool)
 
+		// TransactionStatus returns the number of confirmations that a
+		// transaction has and whether it has been confirmed. Unconfirmed
+		// transactions have 0 confirmations.
+		TransactionStatus(types.TransactionID) (confirmations types.BlockHeight, confirmed bool, err error)
+
 		// Transactions returns all of the transactions that were confirmed at
 		// heights [startHeight, endHeight]. Unconfirmed transactions are not
 		// included.
 		Transactions(startHeight types.BlockHeight, endHeight types.BlockHeight) ([]ProcessedTransaction, error)
 
+		// TransactionHistory returns a record of the effect of every
+		// transaction confirmed at heights [startHeight, endHeight] on the
+		// wallet's balance.
+		TransactionHistory(startHeight types.BlockHeight, endHeight types.BlockHeight) ([]TransactionRecord, error)
+
 		// UnconfirmedTransactions returns all unconfirmed transactions
 		// relative to the wallet.
 		UnconfirmedTransactions() []ProcessedTransaction
 
+		// EstimateFee returns a recommended miner fee for a transaction of
+		// the provided size in bytes, based on the fees paid by transactions
+		// in recent blocks.
+		EstimateFee(txnSize uint64) types.Currency
+
 		// RegisterTransaction takes a transaction and its parents and returns
 		// a TransactionBuilder which can be used to expand the transaction.
 		RegisterTransaction(t types.Transaction, parents []types.Transaction) TransactionBuilder
 
+		// AddSignature adds the wallet's signature for the public key at
+		// 'keyIndex' of the unlock conditions to every input of the
+		// transaction that uses the unlock conditions. It is used to co-sign
+		// transactions spending from multisig addresses.
+		AddSignature(txn types.Transaction, uc types.UnlockConditions, keyIndex int) (types.Transaction, error)
+
+		// SignTransactionInputs signs only the designated siacoin and siafund
+		// inputs of the transaction, which must be controlled by the wallet.
+		// The remaining inputs are left for counterparties to sign.
+		SignTransactionInputs(txn types.Transaction, siacoinInputs []int, siafundInputs []int) (types.Transaction, error)
+
+		// RenewContract creates and broadcasts a revision of an existing
+		// contract of the wallet that keeps the same file and payouts, but
+		// moves its window so that it ends at 'newWindowEnd'.
+		RenewContract(oldID types.FileContractID, newWindowEnd types.BlockHeight) (types.Transaction, error)
+
 		// StartTransaction is a convenience method that calls
 		// RegisterTransaction(types.Transaction{}, nil)
 		StartTransaction() TransactionBuilder
@@ -364,6 +480,23 @@ type (
 		// transactions are automatically given to the transaction pool, and
 		// are also returned to the caller.
 		SendSiafunds(amount types.Currency, dest types.UnlockHash) ([]types.Transaction, error)
+
+		// BumpFee replaces an unconfirmed transaction of the wallet with a
+		// version that pays 'additionalFee' more in miner fees. The
+		// replacement is submitted to the transaction pool and is also
+		// returned.
+		BumpFee(txid types.TransactionID, additionalFee types.Currency) (types.Transaction, error)
+
+		// Defrag consolidates up to 'maxInputs' of the smallest siacoin
+		// outputs of the wallet into a single output. The transaction is
+		// submitted to the transaction pool and is also returned.
+		Defrag(maxInputs int) (types.Transaction, error)
+
+		// SweepAddress moves all of the siacoins locked to the address of
+		// the provided unlock conditions into the wallet, signing with the
+		// provided key. The transaction is submitted to the transaction pool
+		// and is also returned.
+		SweepAddress(uc types.UnlockConditions, key crypto.SecretKey) (types.Transaction, error)
 	}
 )
 
@@ -0,0 +1,169 @@
+package wallet
+
+import (
+	"errors"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+var (
+	errBumpForeignInput       = errors.New("transaction has inputs that the wallet cannot sign")
+	errBumpUnknownTransaction = errors.New("transaction is not an unconfirmed transaction of the wallet")
+)
+
+// unconfirmedParents returns the unconfirmed transactions that 'txn' depends
+// on, in dependency order. Only unconfirmed transactions that are relevant to
+// the wallet are considered.
+func (w *Wallet) unconfirmedParents(txn types.Transaction) []types.Transaction {
+	// Map each unconfirmed output to the transaction that creates it.
+	creators := make(map[types.OutputID]int)
+	for i, upt := range w.unconfirmedProcessedTransactions {
+		for j := range upt.Transaction.SiacoinOutputs {
+			creators[types.OutputID(upt.Transaction.SiacoinOutputID(uint64(j)))] = i
+		}
+		for j := range upt.Transaction.SiafundOutputs {
+			creators[types.OutputID(upt.Transaction.SiafundOutputID(uint64(j)))] = i
+		}
+	}
+
+	// Walk the dependency graph, starting at the input transaction.
+	needed := make(map[int]struct{})
+	queue := []types.Transaction{txn}
+	for len(queue) > 0 {
+		t := queue[0]
+		queue = queue[1:]
+		var parentIDs []types.OutputID
+		for _, sci := range t.SiacoinInputs {
+			parentIDs = append(parentIDs, types.OutputID(sci.ParentID))
+		}
+		for _, sfi := range t.SiafundInputs {
+			parentIDs = append(parentIDs, types.OutputID(sfi.ParentID))
+		}
+		for _, id := range parentIDs {
+			i, exists := creators[id]
+			if !exists {
+				continue
+			}
+			if _, seen := needed[i]; seen {
+				continue
+			}
+			needed[i] = struct{}{}
+			queue = append(queue, w.unconfirmedProcessedTransactions[i].Transaction)
+		}
+	}
+
+	// The unconfirmed transactions are in dependency order, preserve it.
+	var parents []types.Transaction
+	for i, upt := range w.unconfirmedProcessedTransactions {
+		if _, exists := needed[i]; exists {
+			parents = append(parents, upt.Transaction)
+		}
+	}
+	return parents
+}
+
+// BumpFee replaces an unconfirmed transaction of the wallet with a version
+// that pays 'additionalFee' more in miner fees. The inputs of the original
+// transaction are reused. If the transaction has an output to the wallet that
+// is large enough, the output is reduced to pay the fee, otherwise an input is
+// added to the transaction. The replacement is submitted to the transaction
+// pool, where it evicts the original transaction, and is also returned.
+//
+// All inputs of the original transaction must be spendable by the wallet.
+func (w *Wallet) BumpFee(txid types.TransactionID, additionalFee types.Currency) (types.Transaction, error) {
+	if err := w.tg.Add(); err != nil {
+		return types.Transaction{}, err
+	}
+	defer w.tg.Done()
+
+	// Find the transaction and its parents, and determine whether there is an
+	// output that can pay for the fee.
+	var txn types.Transaction
+	var parents []types.Transaction
+	changeIndex := -1
+	err := func() error {
+		w.mu.RLock()
+		defer w.mu.RUnlock()
+		if !w.unlocked {
+			return modules.ErrLockedWallet
+		}
+
+		found := false
+		for _, upt := range w.unconfirmedProcessedTransactions {
+			if upt.TransactionID == txid {
+				txn = upt.Transaction
+				found = true
+				break
+			}
+		}
+		if !found {
+			return errBumpUnknownTransaction
+		}
+		for _, sci := range txn.SiacoinInputs {
+			if _, exists := w.keys[sci.UnlockConditions.UnlockHash()]; !exists {
+				return errBumpForeignInput
+			}
+		}
+		for _, sfi := range txn.SiafundInputs {
+			if _, exists := w.keys[sfi.UnlockConditions.UnlockHash()]; !exists {
+				return errBumpForeignInput
+			}
+		}
+		for i, sco := range txn.SiacoinOutputs {
+			if _, exists := w.keys[sco.UnlockHash]; exists && sco.Value.Cmp(additionalFee) > 0 {
+				changeIndex = i
+				break
+			}
+		}
+		parents = w.unconfirmedParents(txn)
+		return nil
+	}()
+	if err != nil {
+		return types.Transaction{}, err
+	}
+
+	// Register a copy of the transaction with the increased fee. The old
+	// signatures are invalidated by the changes, so they are removed and all
+	// of the inputs are marked to be signed by the builder.
+	tb := w.RegisterTransaction(txn, parents).(*transactionBuilder)
+	tb.transaction.TransactionSignatures = nil
+	if len(tb.transaction.MinerFees) == 0 {
+		tb.transaction.MinerFees = []types.Currency{additionalFee}
+	} else {
+		tb.transaction.MinerFees[0] = tb.transaction.MinerFees[0].Add(additionalFee)
+	}
+	for i := range tb.transaction.SiacoinInputs {
+		tb.siacoinInputs = append(tb.siacoinInputs, i)
+	}
+	for i := range tb.transaction.SiafundInputs {
+		tb.siafundInputs = append(tb.siafundInputs, i)
+	}
+	if changeIndex >= 0 {
+		change := &tb.transaction.SiacoinOutputs[changeIndex]
+		change.Value = change.Value.Sub(additionalFee)
+	} else {
+		err = tb.FundSiacoins(additionalFee)
+		if err != nil {
+			return types.Transaction{}, err
+		}
+	}
+
+	txnSet, err := tb.Sign(true)
+	if err == nil {
+		err = w.tpool.AcceptTransactionSet(txnSet)
+	}
+	if err != nil {
+		// Only the outputs spent by new parents are returned to the wallet,
+		// the original transaction remains in the transaction pool.
+		w.mu.Lock()
+		for _, i := range tb.newParents {
+			for _, sci := range tb.parents[i].SiacoinInputs {
+				delete(w.spentOutputs, types.OutputID(sci.ParentID))
+			}
+		}
+		w.mu.Unlock()
+		return types.Transaction{}, err
+	}
+	return txnSet[len(txnSet)-1], nil
+}
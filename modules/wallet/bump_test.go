@@ -0,0 +1,74 @@
+package wallet
+
+import (
+	"testing"
+
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestBumpFee checks that BumpFee replaces a transaction in the transaction
+// pool with a version paying a higher fee.
+func TestBumpFee(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester("TestBumpFee")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	// Send coins to an outside address.
+	dest := types.UnlockHash{1}
+	amount := types.SiacoinPrecision.Mul64(100)
+	txnSet, err := wt.wallet.SendSiacoins(amount, dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	original := txnSet[len(txnSet)-1]
+
+	// Bump the fee of the transaction.
+	additionalFee := types.SiacoinPrecision.Mul64(5)
+	bumped, err := wt.wallet.BumpFee(original.ID(), additionalFee)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var originalFees, bumpedFees types.Currency
+	for _, fee := range original.MinerFees {
+		originalFees = originalFees.Add(fee)
+	}
+	for _, fee := range bumped.MinerFees {
+		bumpedFees = bumpedFees.Add(fee)
+	}
+	if bumpedFees.Cmp(originalFees.Add(additionalFee)) != 0 {
+		t.Error("bumped transaction has the wrong fees:", bumpedFees)
+	}
+
+	// The bumped transaction should have replaced the original in the
+	// transaction pool.
+	foundOriginal, foundBumped := false, false
+	for _, txn := range wt.tpool.TransactionList() {
+		if txn.ID() == original.ID() {
+			foundOriginal = true
+		}
+		if txn.ID() == bumped.ID() {
+			foundBumped = true
+		}
+	}
+	if foundOriginal || !foundBumped {
+		t.Fatal("bumped transaction did not replace the original:", foundOriginal, foundBumped)
+	}
+
+	// Mine a block, the destination should receive the coins exactly once.
+	_, err = wt.miner.AddBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(wt.tpool.TransactionList()) != 0 {
+		t.Error("transaction pool should be empty")
+	}
+	_, err = wt.wallet.BumpFee(bumped.ID(), additionalFee)
+	if err != errBumpUnknownTransaction {
+		t.Error("expecting errBumpUnknownTransaction, got", err)
+	}
+}
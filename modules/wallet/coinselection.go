@@ -0,0 +1,117 @@
+package wallet
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+var (
+	// errUnknownCoinSelectionStrategy is returned when a transaction is
+	// funded using a coin selection strategy that the wallet does not
+	// recognize.
+	errUnknownCoinSelectionStrategy = errors.New("unknown coin selection strategy")
+)
+
+// selectSiacoinOutputs returns the indices of the outputs in 'so' that should
+// be used to fund a transaction with 'amount' siacoins according to
+// 'strategy'. If the outputs are not sufficient to cover 'amount', all of the
+// outputs are returned. 'so' will be sorted by selectSiacoinOutputs.
+func selectSiacoinOutputs(so sortedOutputs, amount types.Currency, strategy modules.CoinSelectionStrategy) ([]int, error) {
+	switch strategy {
+	case modules.LargestFirst:
+		sort.Sort(sort.Reverse(so))
+		return selectInOrder(so, amount), nil
+	case modules.SmallestFirst:
+		sort.Sort(so)
+		return selectInOrder(so, amount), nil
+	case modules.MinimizeChange:
+		sort.Sort(so)
+		return selectMinimizeChange(so, amount), nil
+	default:
+		return nil, errUnknownCoinSelectionStrategy
+	}
+}
+
+// selectInOrder selects outputs in the order they appear in 'so' until their
+// sum reaches 'amount'.
+func selectInOrder(so sortedOutputs, amount types.Currency) []int {
+	var selected []int
+	var fund types.Currency
+	for i, sco := range so.outputs {
+		selected = append(selected, i)
+		fund = fund.Add(sco.Value)
+		if fund.Cmp(amount) >= 0 {
+			break
+		}
+	}
+	return selected
+}
+
+// selectMinimizeChange selects the set of outputs whose sum is closest to
+// 'amount' without falling short of 'amount'. 'so' must be sorted from smallest
+// to largest.
+//
+// Two candidate sets are considered. The first is the smallest single output
+// that covers 'amount' on its own. The second is built from the outputs that
+// are smaller than 'amount': outputs are added from largest to smallest as
+// long as they do not overshoot 'amount', and if the sum still falls short,
+// the smallest remaining output that completes the sum is added. The candidate
+// resulting in the least change is returned.
+func selectMinimizeChange(so sortedOutputs, amount types.Currency) []int {
+	// Find the smallest output that covers the amount on its own, and the
+	// boundary between the smaller and larger outputs.
+	boundary := sort.Search(len(so.outputs), func(i int) bool {
+		return so.outputs[i].Value.Cmp(amount) >= 0
+	})
+	if boundary < len(so.outputs) && so.outputs[boundary].Value.Cmp(amount) == 0 {
+		return []int{boundary}
+	}
+
+	// Build a set out of the outputs that are smaller than the amount.
+	var small []int
+	var smallFund types.Currency
+	used := make(map[int]bool)
+	for i := boundary - 1; i >= 0; i-- {
+		if smallFund.Add(so.outputs[i].Value).Cmp(amount) > 0 {
+			continue
+		}
+		small = append(small, i)
+		smallFund = smallFund.Add(so.outputs[i].Value)
+		used[i] = true
+	}
+	if smallFund.Cmp(amount) < 0 {
+		// Add the smallest unused output that completes the set. Because the
+		// outputs are sorted, this is the first unused output that is large
+		// enough.
+		completed := false
+		for i := 0; i < boundary; i++ {
+			if used[i] || smallFund.Add(so.outputs[i].Value).Cmp(amount) < 0 {
+				continue
+			}
+			small = append(small, i)
+			smallFund = smallFund.Add(so.outputs[i].Value)
+			completed = true
+			break
+		}
+		if !completed {
+			// The smaller outputs cannot cover the amount.
+			if boundary < len(so.outputs) {
+				return []int{boundary}
+			}
+			// No set of outputs covers the amount, return all of them.
+			return small
+		}
+	}
+
+	// Compare the change of the two candidates.
+	if boundary < len(so.outputs) {
+		singleChange := so.outputs[boundary].Value.Sub(amount)
+		if singleChange.Cmp(smallFund.Sub(amount)) <= 0 {
+			return []int{boundary}
+		}
+	}
+	return small
+}
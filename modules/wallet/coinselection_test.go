@@ -0,0 +1,140 @@
+package wallet
+
+import (
+	"testing"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// outputsFromValues creates a sortedOutputs object with outputs of the
+// provided values. The id of each output is set to its value, so that the
+// selected outputs can be identified after sorting.
+func outputsFromValues(values ...uint64) sortedOutputs {
+	var so sortedOutputs
+	for _, v := range values {
+		so.ids = append(so.ids, types.SiacoinOutputID{byte(v)})
+		so.outputs = append(so.outputs, types.SiacoinOutput{Value: types.NewCurrency64(v)})
+	}
+	return so
+}
+
+// selectedValues returns the values of the outputs selected by
+// selectSiacoinOutputs, in the order that they were selected.
+func selectedValues(t *testing.T, values []uint64, amount uint64, strategy modules.CoinSelectionStrategy) []uint64 {
+	so := outputsFromValues(values...)
+	selected, err := selectSiacoinOutputs(so, types.NewCurrency64(amount), strategy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var selectedVals []uint64
+	for _, i := range selected {
+		selectedVals = append(selectedVals, uint64(so.ids[i][0]))
+	}
+	return selectedVals
+}
+
+// equalValues checks whether two slices of values contain the same values,
+// ignoring order.
+func equalValues(a, b []uint64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	counts := make(map[uint64]int)
+	for _, v := range a {
+		counts[v]++
+	}
+	for _, v := range b {
+		counts[v]--
+		if counts[v] < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// TestSelectSiacoinOutputs probes the selectSiacoinOutputs function.
+func TestSelectSiacoinOutputs(t *testing.T) {
+	values := []uint64{7, 1, 50, 3, 20, 12}
+	tests := []struct {
+		strategy modules.CoinSelectionStrategy
+		amount   uint64
+		expected []uint64
+	}{
+		// LargestFirst uses the largest outputs first.
+		{modules.LargestFirst, 10, []uint64{50}},
+		{modules.LargestFirst, 60, []uint64{50, 20}},
+		{modules.LargestFirst, 1000, []uint64{50, 20, 12, 7, 3, 1}},
+
+		// SmallestFirst uses the smallest outputs first.
+		{modules.SmallestFirst, 10, []uint64{1, 3, 7}},
+		{modules.SmallestFirst, 30, []uint64{1, 3, 7, 12, 20}},
+		{modules.SmallestFirst, 1000, []uint64{1, 3, 7, 12, 20, 50}},
+
+		// MinimizeChange uses the set closest to the amount.
+		{modules.MinimizeChange, 12, []uint64{12}},
+		{modules.MinimizeChange, 10, []uint64{7, 3}},
+		{modules.MinimizeChange, 22, []uint64{20, 1, 3}},
+		{modules.MinimizeChange, 45, []uint64{50}},
+		{modules.MinimizeChange, 42, []uint64{20, 12, 7, 3}},
+		{modules.MinimizeChange, 1000, []uint64{1, 3, 7, 12, 20, 50}},
+	}
+	for _, test := range tests {
+		selected := selectedValues(t, values, test.amount, test.strategy)
+		if !equalValues(selected, test.expected) {
+			t.Errorf("strategy %v with amount %v: expected %v, got %v", test.strategy, test.amount, test.expected, selected)
+		}
+	}
+
+	// Unknown strategies should be rejected.
+	_, err := selectSiacoinOutputs(outputsFromValues(values...), types.NewCurrency64(10), modules.CoinSelectionStrategy(-1))
+	if err != errUnknownCoinSelectionStrategy {
+		t.Error("expecting errUnknownCoinSelectionStrategy, got", err)
+	}
+}
+
+// TestFundSiacoinsWithStrategy checks that transactions funded using each
+// coin selection strategy are accepted by the transaction pool.
+func TestFundSiacoinsWithStrategy(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester("TestFundSiacoinsWithStrategy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	// Mine a few more blocks so that the wallet has several outputs.
+	for i := 0; i < 3; i++ {
+		_, err = wt.miner.AddBlock()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, strategy := range []modules.CoinSelectionStrategy{modules.LargestFirst, modules.SmallestFirst, modules.MinimizeChange} {
+		b := wt.wallet.StartTransaction()
+		amount := types.SiacoinPrecision.Mul64(1e3)
+		err = b.FundSiacoinsWithStrategy(amount, strategy)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b.AddSiacoinOutput(types.SiacoinOutput{Value: amount})
+		txnSet, err := b.Sign(true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = wt.tpool.AcceptTransactionSet(txnSet)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// An unknown strategy should be rejected.
+	b := wt.wallet.StartTransaction()
+	err = b.FundSiacoinsWithStrategy(types.SiacoinPrecision, modules.CoinSelectionStrategy(-1))
+	if err != errUnknownCoinSelectionStrategy {
+		t.Error("expecting errUnknownCoinSelectionStrategy, got", err)
+	}
+}
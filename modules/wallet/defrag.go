@@ -0,0 +1,149 @@
+package wallet
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/encoding"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+var (
+	errDefragNotProfitable = errors.New("consolidating the wallet's outputs would cost more in fees than it saves")
+	errDefragTooFewInputs  = errors.New("defrag requires at least two inputs")
+)
+
+// defragInputSize returns the approximate number of bytes that spending an
+// output with the provided unlock conditions adds to a transaction, including
+// the signatures.
+func defragInputSize(uc types.UnlockConditions) uint64 {
+	sci := types.SiacoinInput{UnlockConditions: uc}
+	sig := types.TransactionSignature{
+		CoveredFields: types.FullCoveredFields,
+		Signature:     make([]byte, crypto.SignatureSize),
+	}
+	return uint64(len(encoding.Marshal(sci))) + uc.SignaturesRequired*uint64(len(encoding.Marshal(sig)))
+}
+
+// Defrag consolidates up to 'maxInputs' of the smallest confirmed siacoin
+// outputs of the wallet into a single output sent to an address of the
+// wallet. The miner fee is subtracted from the consolidated output. Outputs
+// that are worth less than the fee required to spend them are skipped, and if
+// fewer than two outputs remain, errDefragNotProfitable is returned. The
+// transaction is submitted to the transaction pool and is also returned.
+func (w *Wallet) Defrag(maxInputs int) (types.Transaction, error) {
+	if err := w.tg.Add(); err != nil {
+		return types.Transaction{}, err
+	}
+	defer w.tg.Done()
+	if maxInputs < 2 {
+		return types.Transaction{}, errDefragTooFewInputs
+	}
+
+	txn, spentScoids, err := w.managedCreateDefragTransaction(maxInputs)
+	if err != nil {
+		return types.Transaction{}, err
+	}
+	err = w.tpool.AcceptTransactionSet([]types.Transaction{txn})
+	if err != nil {
+		// Return the outputs to the wallet so that they can be spent by
+		// other transactions.
+		w.mu.Lock()
+		for _, scoid := range spentScoids {
+			delete(w.spentOutputs, types.OutputID(scoid))
+		}
+		w.mu.Unlock()
+		return types.Transaction{}, err
+	}
+	return txn, nil
+}
+
+// managedCreateDefragTransaction creates and signs a transaction consolidating
+// up to 'maxInputs' of the smallest outputs of the wallet, marking the spent
+// outputs as spent.
+func (w *Wallet) managedCreateDefragTransaction(maxInputs int) (types.Transaction, []types.SiacoinOutputID, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.unlocked {
+		return types.Transaction{}, nil, modules.ErrLockedWallet
+	}
+
+	// Collect the confirmed outputs that are available for spending, smallest
+	// first.
+	var so sortedOutputs
+	allowedHeight := w.consensusSetHeight - RespendTimeout
+	if w.consensusSetHeight < RespendTimeout {
+		allowedHeight = 0
+	}
+	for scoid, sco := range w.siacoinOutputs {
+		if w.spentOutputs[types.OutputID(scoid)] > allowedHeight {
+			continue
+		}
+		if w.consensusSetHeight < w.keys[sco.UnlockHash].UnlockConditions.Timelock {
+			continue
+		}
+		so.ids = append(so.ids, scoid)
+		so.outputs = append(so.outputs, sco)
+	}
+	sort.Sort(so)
+
+	// Add inputs, skipping any output that is worth less than the fee
+	// required to spend it. The transaction is kept within the size limit of
+	// the transaction pool.
+	var txn types.Transaction
+	var fund types.Currency
+	var spentScoids []types.SiacoinOutputID
+	txnSize := uint64(len(encoding.Marshal(txn))) + 200 // space for an output and a fee
+	for i := range so.ids {
+		if len(txn.SiacoinInputs) >= maxInputs {
+			break
+		}
+		uc := w.keys[so.outputs[i].UnlockHash].UnlockConditions
+		inputSize := defragInputSize(uc)
+		if so.outputs[i].Value.Cmp(w.estimateFee(inputSize)) <= 0 {
+			continue
+		}
+		if txnSize+inputSize > modules.TransactionSizeLimit {
+			break
+		}
+		txn.SiacoinInputs = append(txn.SiacoinInputs, types.SiacoinInput{
+			ParentID:         so.ids[i],
+			UnlockConditions: uc,
+		})
+		spentScoids = append(spentScoids, so.ids[i])
+		fund = fund.Add(so.outputs[i].Value)
+		txnSize += inputSize
+	}
+	if len(txn.SiacoinInputs) < 2 {
+		return types.Transaction{}, nil, errDefragNotProfitable
+	}
+	fee := w.estimateFee(txnSize)
+	if fee.Cmp(fund) >= 0 {
+		return types.Transaction{}, nil, errDefragNotProfitable
+	}
+
+	// Send the consolidated funds to a new address of the wallet.
+	uc, err := w.nextPrimarySeedAddress()
+	if err != nil {
+		return types.Transaction{}, nil, err
+	}
+	txn.MinerFees = []types.Currency{fee}
+	txn.SiacoinOutputs = []types.SiacoinOutput{{
+		Value:      fund.Sub(fee),
+		UnlockHash: uc.UnlockHash(),
+	}}
+	for _, sci := range txn.SiacoinInputs {
+		_, err := addSignatures(&txn, types.FullCoveredFields, sci.UnlockConditions, crypto.Hash(sci.ParentID), w.keys[sci.UnlockConditions.UnlockHash()])
+		if err != nil {
+			return types.Transaction{}, nil, err
+		}
+	}
+
+	// Mark all outputs that were spent as spent.
+	for _, scoid := range spentScoids {
+		w.spentOutputs[types.OutputID(scoid)] = w.consensusSetHeight
+	}
+	return txn, spentScoids, nil
+}
@@ -0,0 +1,75 @@
+package wallet
+
+import (
+	"testing"
+
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestDefrag checks that Defrag consolidates the outputs of a fragmented
+// wallet.
+func TestDefrag(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester("TestDefrag")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	// Fragment the wallet by sending many small outputs to itself.
+	for i := 0; i < 10; i++ {
+		uc, err := wt.wallet.NextAddress()
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = wt.wallet.SendSiacoins(types.SiacoinPrecision.Mul64(1e3), uc.UnlockHash())
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, err = wt.miner.AddBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	wt.wallet.mu.RLock()
+	numOutputs := len(wt.wallet.siacoinOutputs)
+	wt.wallet.mu.RUnlock()
+	balance, _, _ := wt.wallet.ConfirmedBalance()
+
+	// Defrag the wallet and mine the transaction.
+	txn, err := wt.wallet.Defrag(5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(txn.SiacoinInputs) != 5 {
+		t.Fatal("defrag transaction has the wrong number of inputs:", len(txn.SiacoinInputs))
+	}
+	_, err = wt.miner.AddBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	wt.wallet.mu.RLock()
+	numOutputs2 := len(wt.wallet.siacoinOutputs)
+	wt.wallet.mu.RUnlock()
+	// The new block adds one miner payout, the defrag replaces five outputs
+	// with one.
+	if numOutputs2 != numOutputs+1-4 {
+		t.Error("defrag did not reduce the number of outputs:", numOutputs, numOutputs2)
+	}
+
+	// The balance should have increased by the block reward, minus the fee
+	// of the defrag transaction.
+	balance2, _, _ := wt.wallet.ConfirmedBalance()
+	expected := balance.Add(types.CalculateCoinbase(wt.cs.Height() - types.MaturityDelay)).Sub(txn.MinerFees[0])
+	if balance2.Cmp(expected) != 0 {
+		t.Error("unexpected balance after defrag:", balance2, expected)
+	}
+
+	// Defrag should reject fewer than two inputs.
+	_, err = wt.wallet.Defrag(1)
+	if err != errDefragTooFewInputs {
+		t.Error("expecting errDefragTooFewInputs, got", err)
+	}
+}
@@ -43,27 +43,20 @@ func (w *Wallet) checkMasterKey(masterKey crypto.TwofishKey) error {
 
 // initEncryption checks that the provided encryption key is the valid
 // encryption key for the wallet. If encryption has not yet been established
-// for the wallet, an encryption key is created.
-func (w *Wallet) initEncryption(masterKey crypto.TwofishKey) (modules.Seed, error) {
+// for the wallet, an encryption key is created. The provided seed becomes the
+// primary seed of the wallet, with 'progress' addresses already in use.
+func (w *Wallet) initEncryption(masterKey crypto.TwofishKey, seed modules.Seed, progress uint64) (modules.Seed, error) {
 	// Check if the wallet encryption key has already been set.
 	if len(w.persist.EncryptionVerification) != 0 {
 		return modules.Seed{}, errReencrypt
 	}
 
-	// Create a random seed and use it to generate the seed file for the
-	// wallet.
-	var seed modules.Seed
-	_, err := rand.Read(seed[:])
-	if err != nil {
-		return modules.Seed{}, err
-	}
-
 	// If the input key is blank, use the seed to create the master key.
 	// Otherwise, use the input key.
 	if masterKey == (crypto.TwofishKey{}) {
 		masterKey = crypto.TwofishKey(crypto.HashObject(seed))
 	}
-	err = w.createSeed(masterKey, seed)
+	err := w.createSeed(masterKey, seed, progress)
 	if err != nil {
 		return modules.Seed{}, err
 	}
@@ -206,7 +199,51 @@ func (w *Wallet) Encrypt(masterKey crypto.TwofishKey) (modules.Seed, error) {
 	defer w.tg.Done()
 	w.mu.Lock()
 	defer w.mu.Unlock()
-	return w.initEncryption(masterKey)
+
+	// Create a random seed to use as the primary seed of the wallet.
+	var seed modules.Seed
+	_, err := rand.Read(seed[:])
+	if err != nil {
+		return modules.Seed{}, err
+	}
+	return w.initEncryption(masterKey, seed, 0)
+}
+
+// InitFromSeed encrypts the wallet using the input key, using the input seed
+// as the primary seed of the wallet. Before the wallet is encrypted, the
+// blockchain is scanned for addresses generated by the seed, so that all
+// addresses that have been used previously will be tracked by the wallet once
+// it is unlocked. If the key is blank, then the hash of the seed will be used
+// as the key.
+//
+// Like Encrypt, InitFromSeed can only be called on a wallet that has not been
+// encrypted yet.
+func (w *Wallet) InitFromSeed(masterKey crypto.TwofishKey, seed modules.Seed) error {
+	if err := w.tg.Add(); err != nil {
+		return err
+	}
+	defer w.tg.Done()
+
+	if w.Encrypted() {
+		return errReencrypt
+	}
+
+	// Scan the blockchain for addresses belonging to the seed. The scan is
+	// performed without holding the wallet lock, as it may take a while.
+	s := newSeedScanner(seed)
+	err := s.scan(w.cs)
+	if err != nil {
+		return err
+	}
+	var progress uint64
+	if s.seenAny {
+		progress = s.largestIndexSeen + 1
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	_, err = w.initEncryption(masterKey, seed, progress)
+	return err
 }
 
 // Unlocked indicates whether the wallet is locked or unlocked.
@@ -246,3 +246,48 @@ func TestLock(t *testing.T) {
 		t.Error("balance should increase after a block was mined")
 	}
 }
+
+// TestSignLockedWallet checks that a locked wallet refuses to sign
+// transactions, and that signing works again after unlocking the wallet.
+func TestSignLockedWallet(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester("TestSignLockedWallet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	// Fund a transaction while the wallet is unlocked.
+	b := wt.wallet.StartTransaction()
+	err = b.FundSiacoins(types.SiacoinPrecision)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.AddMinerFee(types.SiacoinPrecision)
+
+	// Signing should fail while the wallet is locked.
+	err = wt.wallet.Lock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = b.Sign(true)
+	if err != modules.ErrLockedWallet {
+		t.Fatal("expecting ErrLockedWallet, got", err)
+	}
+
+	// After unlocking, the transaction can be signed and accepted.
+	err = wt.wallet.Unlock(wt.walletMasterKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	txnSet, err := b.Sign(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = wt.tpool.AcceptTransactionSet(txnSet)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,101 @@
+package wallet
+
+import (
+	"github.com/NebulousLabs/Sia/encoding"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+const (
+	// feeHistoryDepth is the number of recent blocks that the wallet keeps
+	// statistics for when estimating fees.
+	feeHistoryDepth = 12
+
+	// feeHistoryMinimum is the number of blocks that need to be in the fee
+	// history before the wallet will base an estimate on the history. With
+	// fewer blocks, the minimum fee is recommended.
+	feeHistoryMinimum = 6
+)
+
+type (
+	// blockFeeStats contains the fee information of a single block.
+	// feeTxnSize is the combined size of the transactions in the block that
+	// paid miner fees, blockSize is the size of the whole block.
+	blockFeeStats struct {
+		fees       types.Currency
+		feeTxnSize uint64
+		blockSize  uint64
+	}
+)
+
+// computeBlockFeeStats returns the fee statistics of a block.
+func computeBlockFeeStats(b types.Block) blockFeeStats {
+	stats := blockFeeStats{
+		blockSize: uint64(len(encoding.Marshal(b))),
+	}
+	for _, txn := range b.Transactions {
+		if len(txn.MinerFees) == 0 {
+			continue
+		}
+		for _, fee := range txn.MinerFees {
+			stats.fees = stats.fees.Add(fee)
+		}
+		stats.feeTxnSize += uint64(len(encoding.Marshal(txn)))
+	}
+	return stats
+}
+
+// updateFeeHistory updates the fee statistics of the recent blocks using a
+// consensus change.
+func (w *Wallet) updateFeeHistory(cc modules.ConsensusChange) {
+	for range cc.RevertedBlocks {
+		if len(w.recentBlockFees) > 0 {
+			w.recentBlockFees = w.recentBlockFees[:len(w.recentBlockFees)-1]
+		}
+	}
+	for _, block := range cc.AppliedBlocks {
+		w.recentBlockFees = append(w.recentBlockFees, computeBlockFeeStats(block))
+	}
+	if len(w.recentBlockFees) > feeHistoryDepth {
+		w.recentBlockFees = w.recentBlockFees[len(w.recentBlockFees)-feeHistoryDepth:]
+	}
+}
+
+// EstimateFee returns a recommended miner fee for a transaction of size
+// 'txnSize' bytes. If recent blocks have been at least half full, the fee
+// density paid by the transactions in those blocks is used. Otherwise, or if
+// there is not enough history, the minimum fee recommended by the transaction
+// pool is used.
+func (w *Wallet) EstimateFee(txnSize uint64) types.Currency {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.estimateFee(txnSize)
+}
+
+// estimateFee returns a recommended miner fee for a transaction of size
+// 'txnSize' bytes. The wallet lock must be held by the caller.
+func (w *Wallet) estimateFee(txnSize uint64) types.Currency {
+	minDensity, _ := w.tpool.FeeEstimation()
+	if len(w.recentBlockFees) < feeHistoryMinimum {
+		return minDensity.Mul64(txnSize)
+	}
+
+	var fees types.Currency
+	var feeTxnSize, blockSize uint64
+	for _, stats := range w.recentBlockFees {
+		fees = fees.Add(stats.fees)
+		feeTxnSize += stats.feeTxnSize
+		blockSize += stats.blockSize
+	}
+
+	// If recent blocks have had plenty of space remaining, there is no
+	// competition for block space and the minimum fee is sufficient.
+	if blockSize < uint64(len(w.recentBlockFees))*types.BlockSizeLimit/2 || feeTxnSize == 0 {
+		return minDensity.Mul64(txnSize)
+	}
+	density := fees.Div64(feeTxnSize)
+	if density.Cmp(minDensity) < 0 {
+		density = minDensity
+	}
+	return density.Mul64(txnSize)
+}
@@ -0,0 +1,65 @@
+package wallet
+
+import (
+	"testing"
+
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestEstimateFee probes the EstimateFee method of the wallet.
+func TestEstimateFee(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester("TestEstimateFee")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	// The wallet tester has only mined empty blocks, so the minimum fee
+	// should be recommended.
+	minDensity, _ := wt.tpool.FeeEstimation()
+	if wt.wallet.EstimateFee(1e3).Cmp(minDensity.Mul64(1e3)) != 0 {
+		t.Error("expecting the minimum fee when blocks are empty")
+	}
+
+	// Replace the fee history with full blocks that paid a high fee density.
+	highDensity := minDensity.Mul64(20)
+	wt.wallet.mu.Lock()
+	wt.wallet.recentBlockFees = nil
+	for i := 0; i < feeHistoryDepth; i++ {
+		wt.wallet.recentBlockFees = append(wt.wallet.recentBlockFees, blockFeeStats{
+			fees:       highDensity.Mul64(types.BlockSizeLimit),
+			feeTxnSize: types.BlockSizeLimit,
+			blockSize:  types.BlockSizeLimit,
+		})
+	}
+	wt.wallet.mu.Unlock()
+	if wt.wallet.EstimateFee(1e3).Cmp(highDensity.Mul64(1e3)) != 0 {
+		t.Error("fee estimate does not reflect the fee density of full blocks")
+	}
+
+	// With too little history, the minimum fee should be recommended.
+	wt.wallet.mu.Lock()
+	wt.wallet.recentBlockFees = wt.wallet.recentBlockFees[:feeHistoryMinimum-1]
+	wt.wallet.mu.Unlock()
+	if wt.wallet.EstimateFee(1e3).Cmp(minDensity.Mul64(1e3)) != 0 {
+		t.Error("expecting the minimum fee when there is insufficient history")
+	}
+
+	// Mining blocks should extend the history, and the history should not
+	// exceed feeHistoryDepth.
+	for i := 0; i < feeHistoryDepth; i++ {
+		_, err = wt.miner.AddBlock()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	wt.wallet.mu.RLock()
+	historyLen := len(wt.wallet.recentBlockFees)
+	wt.wallet.mu.RUnlock()
+	if historyLen != feeHistoryDepth {
+		t.Error("fee history has the wrong length:", historyLen)
+	}
+}
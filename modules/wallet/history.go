@@ -0,0 +1,73 @@
+package wallet
+
+import (
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// transactionRecord summarizes the effect of a processed transaction on the
+// balance of the wallet.
+func transactionRecord(pt modules.ProcessedTransaction) modules.TransactionRecord {
+	tr := modules.TransactionRecord{
+		TransactionID:         pt.TransactionID,
+		ConfirmationHeight:    pt.ConfirmationHeight,
+		ConfirmationTimestamp: pt.ConfirmationTimestamp,
+	}
+	funded := false
+	for _, input := range pt.Inputs {
+		if !input.WalletAddress {
+			continue
+		}
+		funded = true
+		switch input.FundType {
+		case types.SpecifierSiacoinInput:
+			tr.SiacoinOutflow = tr.SiacoinOutflow.Add(input.Value)
+		case types.SpecifierSiafundInput:
+			tr.SiafundOutflow = tr.SiafundOutflow.Add(input.Value)
+		}
+	}
+	for _, output := range pt.Outputs {
+		if output.FundType == types.SpecifierMinerFee {
+			tr.MinerFees = tr.MinerFees.Add(output.Value)
+			continue
+		}
+		if !output.WalletAddress {
+			continue
+		}
+		switch output.FundType {
+		case types.SpecifierSiacoinOutput, types.SpecifierMinerPayout, types.SpecifierClaimOutput:
+			tr.SiacoinInflow = tr.SiacoinInflow.Add(output.Value)
+		case types.SpecifierSiafundOutput:
+			tr.SiafundInflow = tr.SiafundInflow.Add(output.Value)
+		}
+	}
+	// Fees are only paid by the wallet if the wallet contributed inputs.
+	if !funded {
+		tr.MinerFees = types.ZeroCurrency
+	}
+	return tr
+}
+
+// TransactionHistory returns a record of every transaction confirmed in the
+// range [startHeight, endHeight], including the net change to the wallet's
+// siacoin and siafund balances and the fees paid. The history is built from
+// the wallet's consensus subscription, and therefore does not contain
+// transactions from reverted blocks.
+func (w *Wallet) TransactionHistory(startHeight, endHeight types.BlockHeight) ([]modules.TransactionRecord, error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if startHeight > w.consensusSetHeight || startHeight > endHeight {
+		return nil, errOutOfBounds
+	}
+	var history []modules.TransactionRecord
+	for _, pt := range w.processedTransactions {
+		if pt.ConfirmationHeight > endHeight {
+			break
+		}
+		if pt.ConfirmationHeight >= startHeight {
+			history = append(history, transactionRecord(pt))
+		}
+	}
+	return history, nil
+}
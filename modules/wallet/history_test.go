@@ -0,0 +1,89 @@
+package wallet
+
+import (
+	"testing"
+
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestIntegrationTransactionHistory sends and receives several transactions
+// and checks the net balance changes recorded in the transaction history.
+func TestIntegrationTransactionHistory(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester("TestIntegrationTransactionHistory")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	_, err = wt.wallet.TransactionHistory(5, 4)
+	if err != errOutOfBounds {
+		t.Fatal("expecting errOutOfBounds, got", err)
+	}
+
+	// Send coins to an external address and to the wallet itself.
+	wt.wallet.mu.RLock()
+	startHeight := wt.wallet.consensusSetHeight + 1
+	wt.wallet.mu.RUnlock()
+	fee := types.SiacoinPrecision.Mul64(10)
+	sent := types.SiacoinPrecision.Mul64(100)
+	txns, err := wt.wallet.SendSiacoins(sent, types.UnlockHash{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	externalID := txns[len(txns)-1].ID()
+	uc, err := wt.wallet.NextAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	txns, err = wt.wallet.SendSiacoins(sent, uc.UnlockHash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	internalID := txns[len(txns)-1].ID()
+	b, err := wt.miner.AddBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	history, err := wt.wallet.TransactionHistory(startHeight, startHeight)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checked := 0
+	for _, tr := range history {
+		if tr.ConfirmationHeight != startHeight {
+			t.Error("transaction recorded at the wrong height")
+		}
+		switch tr.TransactionID {
+		case externalID:
+			// The wallet lost the amount sent plus the fee.
+			if tr.SiacoinOutflow.Sub(tr.SiacoinInflow).Cmp(sent.Add(fee)) != 0 || tr.MinerFees.Cmp(fee) != 0 {
+				t.Error("external send recorded incorrectly:", tr)
+			}
+		case internalID:
+			// The wallet only lost the fee.
+			if tr.SiacoinOutflow.Sub(tr.SiacoinInflow).Cmp(fee) != 0 || tr.MinerFees.Cmp(fee) != 0 {
+				t.Error("internal send recorded incorrectly:", tr)
+			}
+		case types.TransactionID(b.ID()):
+			// The wallet received the miner payout.
+			if tr.SiacoinInflow.Cmp(b.MinerPayouts[0].Value) != 0 || !tr.SiacoinOutflow.IsZero() || !tr.MinerFees.IsZero() {
+				t.Error("miner payout recorded incorrectly:", tr)
+			}
+		default:
+			// The parent transactions used to create exact outputs do not
+			// change the balance of the wallet.
+			if tr.SiacoinInflow.Cmp(tr.SiacoinOutflow) != 0 || !tr.MinerFees.IsZero() {
+				t.Error("parent transaction recorded incorrectly:", tr)
+			}
+			continue
+		}
+		checked++
+	}
+	if checked != 3 {
+		t.Fatal("history is missing transactions, found", checked)
+	}
+}
@@ -0,0 +1,73 @@
+package wallet
+
+import (
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// labelTransaction returns a copy of a processed transaction with the labels
+// of the related addresses filled in. The inputs and outputs are copied so
+// that the wallet's transaction history is not modified.
+func (w *Wallet) labelTransaction(pt modules.ProcessedTransaction) modules.ProcessedTransaction {
+	if len(w.addressLabels) == 0 {
+		return pt
+	}
+	inputs := make([]modules.ProcessedInput, len(pt.Inputs))
+	copy(inputs, pt.Inputs)
+	for i := range inputs {
+		inputs[i].RelatedAddressLabel = w.addressLabels[inputs[i].RelatedAddress]
+	}
+	outputs := make([]modules.ProcessedOutput, len(pt.Outputs))
+	copy(outputs, pt.Outputs)
+	for i := range outputs {
+		outputs[i].RelatedAddressLabel = w.addressLabels[outputs[i].RelatedAddress]
+	}
+	pt.Inputs = inputs
+	pt.Outputs = outputs
+	return pt
+}
+
+// SetAddressLabel attaches a label to an address, replacing any existing
+// label. The address does not need to belong to the wallet, which allows
+// external payees to be labeled as well. An empty label removes the label
+// from the address.
+func (w *Wallet) SetAddressLabel(uh types.UnlockHash, label string) {
+	if err := w.tg.Add(); err != nil {
+		return
+	}
+	defer w.tg.Done()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if label == "" {
+		delete(w.addressLabels, uh)
+	} else {
+		w.addressLabels[uh] = label
+	}
+
+	// Rebuild the persisted list of labels, preserving the order in which
+	// the addresses were first labeled.
+	var labels []AddressLabel
+	for _, al := range w.persist.AddressLabels {
+		if al.UnlockHash == uh {
+			continue
+		}
+		labels = append(labels, al)
+	}
+	if label != "" {
+		labels = append(labels, AddressLabel{UnlockHash: uh, Label: label})
+	}
+	w.persist.AddressLabels = labels
+	err := w.saveSettingsSync()
+	if err != nil {
+		w.log.Println("ERROR: could not save address label:", err)
+	}
+}
+
+// AddressLabel returns the label attached to an address, or an empty string
+// if the address has no label.
+func (w *Wallet) AddressLabel(uh types.UnlockHash) string {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.addressLabels[uh]
+}
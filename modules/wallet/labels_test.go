@@ -0,0 +1,90 @@
+package wallet
+
+import (
+	"testing"
+
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestAddressLabels checks that address labels appear in the transaction
+// history and persist across restarts.
+func TestAddressLabels(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester("TestAddressLabels")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	// Label an external payee and send it some coins.
+	payee := types.UnlockHash{4, 5, 6}
+	if wt.wallet.AddressLabel(payee) != "" {
+		t.Fatal("address should not have a label yet")
+	}
+	wt.wallet.SetAddressLabel(payee, "rent")
+	if label := wt.wallet.AddressLabel(payee); label != "rent" {
+		t.Fatal("wrong label returned:", label)
+	}
+	txns, err := wt.wallet.SendSiacoins(types.SiacoinPrecision.Mul64(10), payee)
+	if err != nil {
+		t.Fatal(err)
+	}
+	txid := txns[len(txns)-1].ID()
+
+	// The label should appear in the unconfirmed and confirmed history.
+	found := false
+	for _, pt := range wt.wallet.UnconfirmedTransactions() {
+		for _, output := range pt.Outputs {
+			if output.RelatedAddress == payee && output.RelatedAddressLabel == "rent" {
+				found = true
+			}
+		}
+	}
+	if !found {
+		t.Error("label does not appear in the unconfirmed transactions")
+	}
+	_, err = wt.miner.AddBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pt, exists := wt.wallet.Transaction(txid)
+	if !exists {
+		t.Fatal("transaction was not confirmed")
+	}
+	found = false
+	for _, output := range pt.Outputs {
+		if output.RelatedAddress == payee && output.RelatedAddressLabel == "rent" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("label does not appear in the confirmed transaction")
+	}
+
+	// Relabel the address, then restart the wallet and check that the label
+	// persisted.
+	wt.wallet.SetAddressLabel(payee, "landlord")
+	err = wt.wallet.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := New(wt.cs, wt.tpool, wt.wallet.persistDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wt.wallet = w
+	if label := w.AddressLabel(payee); label != "landlord" {
+		t.Error("label was not persisted:", label)
+	}
+
+	// Removing the label should also persist.
+	w.SetAddressLabel(payee, "")
+	if label := w.AddressLabel(payee); label != "" {
+		t.Error("label was not removed:", label)
+	}
+	if len(w.persist.AddressLabels) != 0 {
+		t.Error("removed label is still persisted")
+	}
+}
@@ -28,6 +28,19 @@ func (w *Wallet) ConfirmedBalance() (siacoinBalance types.Currency, siafundBalan
 	return
 }
 
+// ImmatureBalance returns the sum of the delayed siacoin outputs, such as
+// miner payouts, that pay out to the wallet but have not yet matured. The
+// outputs become part of the confirmed balance once they mature.
+func (w *Wallet) ImmatureBalance() (immature types.Currency) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, dsco := range w.delayedSiacoinOutputs {
+		immature = immature.Add(dsco.Value)
+	}
+	return immature
+}
+
 // UnconfirmedBalance returns the number of outgoing and incoming siacoins in
 // the unconfirmed transaction set. Refund outputs are included in this
 // reporting.
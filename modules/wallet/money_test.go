@@ -182,3 +182,64 @@ func TestIntegrationSortedOutputsSorting(t *testing.T) {
 		}
 	}
 }
+
+// TestIntegrationImmatureBalance checks that miner payouts are reported as
+// immature until they have crossed the maturity delay.
+func TestIntegrationImmatureBalance(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester("TestIntegrationImmatureBalance")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	// The wallet tester mined MaturityDelay+1 blocks, of which only the first
+	// has matured.
+	var expected types.Currency
+	for i := types.BlockHeight(2); i <= types.MaturityDelay+1; i++ {
+		expected = expected.Add(types.CalculateCoinbase(i))
+	}
+	if immature := wt.wallet.ImmatureBalance(); immature.Cmp(expected) != 0 {
+		t.Fatalf("expected immature balance %v, got %v", expected, immature)
+	}
+
+	// Mine a block and check that its payout is immature until MaturityDelay
+	// more blocks have been mined.
+	b, err := wt.miner.FindBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = wt.cs.AcceptBlock(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	payout := b.MinerPayouts[0].Value
+	payoutID := b.MinerPayoutID(0)
+	for i := types.BlockHeight(0); i < types.MaturityDelay; i++ {
+		wt.wallet.mu.Lock()
+		_, delayed := wt.wallet.delayedSiacoinOutputs[payoutID]
+		_, confirmed := wt.wallet.siacoinOutputs[payoutID]
+		wt.wallet.mu.Unlock()
+		if !delayed || confirmed {
+			t.Fatal("payout should be immature", i, "blocks after being mined")
+		}
+		if wt.wallet.ImmatureBalance().Cmp(payout) < 0 {
+			t.Fatal("immature balance does not include the payout")
+		}
+
+		b, _ := wt.miner.FindBlock()
+		err = wt.cs.AcceptBlock(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	wt.wallet.mu.Lock()
+	_, delayed := wt.wallet.delayedSiacoinOutputs[payoutID]
+	_, confirmed := wt.wallet.siacoinOutputs[payoutID]
+	wt.wallet.mu.Unlock()
+	if delayed || !confirmed {
+		t.Fatal("payout did not mature after MaturityDelay blocks")
+	}
+}
@@ -0,0 +1,106 @@
+package wallet
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+var (
+	errKeyIndexOutOfRange = errors.New("key index does not point to a public key of the unlock conditions")
+	errNoMatchingInputs   = errors.New("transaction has no inputs using the unlock conditions")
+	errUnknownSigningKey  = errors.New("wallet does not have the secret key for the requested public key")
+)
+
+// AddSignature adds the wallet's signature for the public key at 'keyIndex'
+// of 'uc' to every input of the transaction that uses 'uc'. The signatures
+// cover the whole transaction, so co-signers can sign independently and in any
+// order, as long as the rest of the transaction is not changed. Consensus will
+// only accept the transaction once 'uc.SignaturesRequired' signatures are
+// present.
+func (w *Wallet) AddSignature(txn types.Transaction, uc types.UnlockConditions, keyIndex int) (types.Transaction, error) {
+	if err := w.tg.Add(); err != nil {
+		return types.Transaction{}, err
+	}
+	defer w.tg.Done()
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if !w.unlocked {
+		return types.Transaction{}, modules.ErrLockedWallet
+	}
+	if keyIndex < 0 || keyIndex >= len(uc.PublicKeys) {
+		return types.Transaction{}, errKeyIndexOutOfRange
+	}
+
+	// The addresses of the wallet each have a single public key, find the
+	// address matching the requested public key to get the secret key.
+	spk := uc.PublicKeys[keyIndex]
+	if spk.Algorithm != types.SignatureEd25519 || len(spk.Key) != crypto.PublicKeySize {
+		return types.Transaction{}, errUnknownSigningKey
+	}
+	var pk crypto.PublicKey
+	copy(pk[:], spk.Key)
+	key, exists := w.keys[generateUnlockConditions(pk).UnlockHash()]
+	if !exists {
+		return types.Transaction{}, errUnknownSigningKey
+	}
+	var sk crypto.SecretKey
+	found := false
+	for _, secretKey := range key.SecretKeys {
+		pubKey := secretKey.PublicKey()
+		if bytes.Equal(pubKey[:], spk.Key) {
+			sk = secretKey
+			found = true
+			break
+		}
+	}
+	if !found {
+		return types.Transaction{}, errUnknownSigningKey
+	}
+
+	// Collect the parent ids of the inputs that use the unlock conditions.
+	uh := uc.UnlockHash()
+	var parentIDs []crypto.Hash
+	for _, sci := range txn.SiacoinInputs {
+		if sci.UnlockConditions.UnlockHash() == uh {
+			parentIDs = append(parentIDs, crypto.Hash(sci.ParentID))
+		}
+	}
+	for _, sfi := range txn.SiafundInputs {
+		if sfi.UnlockConditions.UnlockHash() == uh {
+			parentIDs = append(parentIDs, crypto.Hash(sfi.ParentID))
+		}
+	}
+	for _, fcr := range txn.FileContractRevisions {
+		if fcr.UnlockConditions.UnlockHash() == uh {
+			parentIDs = append(parentIDs, crypto.Hash(fcr.ParentID))
+		}
+	}
+	if len(parentIDs) == 0 {
+		return types.Transaction{}, errNoMatchingInputs
+	}
+
+	// Copy the signatures so that the input transaction is not modified, then
+	// add a signature for each input.
+	sigs := make([]types.TransactionSignature, len(txn.TransactionSignatures), len(txn.TransactionSignatures)+len(parentIDs))
+	copy(sigs, txn.TransactionSignatures)
+	txn.TransactionSignatures = sigs
+	for _, parentID := range parentIDs {
+		txn.TransactionSignatures = append(txn.TransactionSignatures, types.TransactionSignature{
+			ParentID:       parentID,
+			PublicKeyIndex: uint64(keyIndex),
+			CoveredFields:  types.CoveredFields{WholeTransaction: true},
+		})
+		sigIndex := len(txn.TransactionSignatures) - 1
+		encodedSig, err := crypto.SignHash(txn.SigHash(sigIndex), sk)
+		if err != nil {
+			return types.Transaction{}, err
+		}
+		txn.TransactionSignatures[sigIndex].Signature = encodedSig[:]
+	}
+	return txn, nil
+}
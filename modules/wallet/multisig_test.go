@@ -0,0 +1,128 @@
+package wallet
+
+import (
+	"testing"
+
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestIntegrationMultisig creates a 2-of-3 address using keys from two
+// wallets, and checks that spending from the address is only accepted once
+// both wallets have signed.
+func TestIntegrationMultisig(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt1, err := createWalletTester("TestIntegrationMultisig1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt1.closeWt()
+	wt2, err := createWalletTester("TestIntegrationMultisig2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt2.closeWt()
+
+	// Build the 2-of-3 unlock conditions from a key of each wallet and a key
+	// that belongs to neither.
+	uc1, err := wt1.wallet.NextAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	uc2, err := wt2.wallet.NextAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, pk3, err := crypto.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pks := []types.SiaPublicKey{
+		uc1.PublicKeys[0],
+		uc2.PublicKeys[0],
+		{Algorithm: types.SignatureEd25519, Key: pk3[:]},
+	}
+	uc, err := types.MultisigUnlockConditions(pks, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Fund the multisig address.
+	value := types.SiacoinPrecision.Mul64(100)
+	txns, err := wt1.wallet.SendSiacoins(value, uc.UnlockHash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = wt1.miner.AddBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var parentID types.SiacoinOutputID
+	fundTxn := txns[len(txns)-1]
+	for i, sco := range fundTxn.SiacoinOutputs {
+		if sco.UnlockHash == uc.UnlockHash() {
+			parentID = fundTxn.SiacoinOutputID(uint64(i))
+		}
+	}
+
+	// Create a transaction spending from the multisig address.
+	txn := types.Transaction{
+		SiacoinInputs: []types.SiacoinInput{{
+			ParentID:         parentID,
+			UnlockConditions: uc,
+		}},
+		SiacoinOutputs: []types.SiacoinOutput{{
+			Value:      value,
+			UnlockHash: uc1.UnlockHash(),
+		}},
+	}
+
+	// Wallets should only sign for their own keys.
+	_, err = wt1.wallet.AddSignature(txn, uc, 1)
+	if err != errUnknownSigningKey {
+		t.Fatal("expecting errUnknownSigningKey, got", err)
+	}
+	_, err = wt1.wallet.AddSignature(txn, uc, 3)
+	if err != errKeyIndexOutOfRange {
+		t.Fatal("expecting errKeyIndexOutOfRange, got", err)
+	}
+	_, err = wt1.wallet.AddSignature(types.Transaction{}, uc, 0)
+	if err != errNoMatchingInputs {
+		t.Fatal("expecting errNoMatchingInputs, got", err)
+	}
+
+	// One signature is not enough.
+	signed1, err := wt1.wallet.AddSignature(txn, uc, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(txn.TransactionSignatures) != 0 {
+		t.Fatal("AddSignature modified the input transaction")
+	}
+	err = wt1.tpool.AcceptTransactionSet([]types.Transaction{signed1})
+	if err == nil {
+		t.Fatal("transaction with one of two signatures was accepted")
+	}
+
+	// The second wallet co-signs, after which the transaction is valid.
+	signed2, err := wt2.wallet.AddSignature(signed1, uc, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(signed2.TransactionSignatures) != 2 {
+		t.Fatal("wrong number of signatures:", len(signed2.TransactionSignatures))
+	}
+	err = wt1.tpool.AcceptTransactionSet([]types.Transaction{signed2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = wt1.miner.AddBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, exists := wt1.wallet.Transaction(signed2.ID()); !exists {
+		t.Error("multisig transaction was not confirmed")
+	}
+}
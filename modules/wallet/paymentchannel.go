@@ -0,0 +1,230 @@
+package wallet
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+var (
+	errChannelBalanceDecrease = errors.New("payment channel balance cannot decrease")
+	errChannelClosed          = errors.New("payment channel has already been closed")
+	errChannelDuration        = errors.New("payment channel must stay open for at least one block")
+	errChannelOverdrawn       = errors.New("payment channel balance cannot exceed the channel capacity")
+	errNoChannelRevisions     = errors.New("payment channel has not been updated since it was opened")
+	errStaleChannelRevision   = errors.New("transaction does not contain the latest revision of the payment channel")
+)
+
+// A PaymentChannel is a unidirectional payment channel from the wallet to a
+// payee, built on top of a file contract with no file. The contract is
+// controlled by a 2-of-2 multisig of the payer and the payee. Sending money
+// down the channel is done off-chain by revising the outputs of the contract;
+// the payer signs every revision and hands it to the payee, who can
+// countersign and broadcast the most recent revision to close the channel.
+//
+// Revisions are only accepted before the contract's WindowStart. If the payee
+// never closes the channel, no storage proof is submitted and the missed proof
+// outputs of the latest confirmed contract are created at WindowEnd. For a
+// channel that was never closed, those outputs refund the whole channel to the
+// payer.
+type PaymentChannel struct {
+	ID               types.FileContractID
+	UnlockConditions types.UnlockConditions
+	Capacity         types.Currency
+
+	balance types.Currency
+
+	// closing is set once the closing transaction has been broadcast. The
+	// channel is only closed once that transaction has been confirmed.
+	closing       bool
+	payeeAddress  types.UnlockHash
+	refundAddress types.UnlockHash
+	revision      types.FileContractRevision
+
+	wallet *Wallet
+	mu     sync.Mutex
+}
+
+// channelOutputs returns the proof outputs of a payment channel that has sent
+// 'balance' to the payee.
+func channelOutputs(capacity, balance types.Currency, refundAddress, payeeAddress types.UnlockHash) []types.SiacoinOutput {
+	return []types.SiacoinOutput{
+		{Value: capacity.Sub(balance), UnlockHash: refundAddress},
+		{Value: balance, UnlockHash: payeeAddress},
+	}
+}
+
+// OpenPaymentChannel funds and broadcasts a file contract that serves as a
+// payment channel to the owner of 'payeeKey'. The payee is paid out to
+// 'payeeAddress'. 'funding' is the payout of the contract; the capacity of the
+// channel is the funding minus the siafund fee. The channel must be closed
+// within 'duration' blocks, after which the funds that have not been closed
+// out return to the wallet.
+func (w *Wallet) OpenPaymentChannel(payeeKey types.SiaPublicKey, payeeAddress types.UnlockHash, funding types.Currency, duration types.BlockHeight) (*PaymentChannel, error) {
+	if err := w.tg.Add(); err != nil {
+		return nil, err
+	}
+	defer w.tg.Done()
+	if duration == 0 {
+		return nil, errChannelDuration
+	}
+
+	// The payer key of the channel is a fresh key from the wallet, the
+	// address of which also receives the refund.
+	payerUC, err := w.NextAddress()
+	if err != nil {
+		return nil, err
+	}
+	uc, err := types.MultisigUnlockConditions([]types.SiaPublicKey{payerUC.PublicKeys[0], payeeKey}, 2)
+	if err != nil {
+		return nil, err
+	}
+
+	w.mu.RLock()
+	height := w.consensusSetHeight
+	w.mu.RUnlock()
+	capacity := types.PostTax(height, funding)
+	outputs := channelOutputs(capacity, types.ZeroCurrency, payerUC.UnlockHash(), payeeAddress)
+	fc := types.FileContract{
+		FileSize:    0,
+		WindowStart: height + duration,
+		// No storage proof is ever expected, so the proof window is kept as
+		// short as possible.
+		WindowEnd:          height + duration + 1,
+		Payout:             funding,
+		ValidProofOutputs:  outputs,
+		MissedProofOutputs: outputs,
+		UnlockHash:         uc.UnlockHash(),
+	}
+
+	// Fund the contract and submit it to the transaction pool.
+	txnBuilder := w.StartTransaction()
+	err = txnBuilder.FundSiacoins(funding)
+	if err != nil {
+		return nil, err
+	}
+	fcIndex := txnBuilder.AddFileContract(fc)
+	txnSet, err := txnBuilder.Sign(true)
+	if err != nil {
+		txnBuilder.Drop()
+		return nil, err
+	}
+	err = w.tpool.AcceptTransactionSet(txnSet)
+	if err != nil {
+		txnBuilder.Drop()
+		return nil, err
+	}
+
+	id := txnSet[len(txnSet)-1].FileContractID(fcIndex)
+	return &PaymentChannel{
+		ID:               id,
+		UnlockConditions: uc,
+		Capacity:         capacity,
+
+		payeeAddress:  payeeAddress,
+		refundAddress: payerUC.UnlockHash(),
+		revision: types.FileContractRevision{
+			ParentID:              id,
+			UnlockConditions:      uc,
+			NewRevisionNumber:     fc.RevisionNumber,
+			NewFileSize:           fc.FileSize,
+			NewFileMerkleRoot:     fc.FileMerkleRoot,
+			NewWindowStart:        fc.WindowStart,
+			NewWindowEnd:          fc.WindowEnd,
+			NewValidProofOutputs:  fc.ValidProofOutputs,
+			NewMissedProofOutputs: fc.MissedProofOutputs,
+			NewUnlockHash:         fc.UnlockHash,
+		},
+
+		wallet: w,
+	}, nil
+}
+
+// Balance returns the total amount that has been sent down the channel.
+func (pc *PaymentChannel) Balance() types.Currency {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	return pc.balance
+}
+
+// Expiration returns the height at which the contract of the channel expires.
+// The channel must be closed before the window of the contract opens;
+// otherwise the missed proof outputs are paid out at this height.
+func (pc *PaymentChannel) Expiration() types.BlockHeight {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	return pc.revision.NewWindowEnd
+}
+
+// Update sets the total amount sent down the channel to 'newBalance'. A
+// revision of the contract paying 'newBalance' to the payee is created and
+// signed by the payer. The returned transaction should be given to the payee,
+// who needs to add their own signature before the revision can be broadcast.
+// The balance of a channel can only increase.
+func (pc *PaymentChannel) Update(newBalance types.Currency) (types.Transaction, error) {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	if pc.closing {
+		return types.Transaction{}, errChannelClosed
+	}
+	if newBalance.Cmp(pc.balance) < 0 {
+		return types.Transaction{}, errChannelBalanceDecrease
+	}
+	if newBalance.Cmp(pc.Capacity) > 0 {
+		return types.Transaction{}, errChannelOverdrawn
+	}
+
+	// The valid and missed outputs are kept identical so that the payee
+	// receives the balance without needing to submit a storage proof.
+	rev := pc.revision
+	rev.NewRevisionNumber++
+	rev.NewValidProofOutputs = channelOutputs(pc.Capacity, newBalance, pc.refundAddress, pc.payeeAddress)
+	rev.NewMissedProofOutputs = channelOutputs(pc.Capacity, newBalance, pc.refundAddress, pc.payeeAddress)
+	txn, err := pc.wallet.AddSignature(types.Transaction{
+		FileContractRevisions: []types.FileContractRevision{rev},
+	}, pc.UnlockConditions, 0)
+	if err != nil {
+		return types.Transaction{}, err
+	}
+
+	pc.revision = rev
+	pc.balance = newBalance
+	return txn, nil
+}
+
+// closeConfirmed returns true if the latest revision of the channel has been
+// confirmed by the consensus set.
+func (pc *PaymentChannel) closeConfirmed() bool {
+	pc.wallet.mu.RLock()
+	defer pc.wallet.mu.RUnlock()
+	fc, exists := pc.wallet.fileContracts[pc.ID]
+	return exists && fc.RevisionNumber >= pc.revision.NewRevisionNumber
+}
+
+// Close broadcasts the latest revision of the channel, settling the balance
+// on-chain. 'txn' must be the transaction returned by the most recent call to
+// Update, countersigned by the payee. No further updates are allowed once Close
+// has been called. Until the revision is confirmed, Close can be called again
+// to re-broadcast it, for example if it was dropped from the transaction pool.
+func (pc *PaymentChannel) Close(txn types.Transaction) error {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	if pc.closing && pc.closeConfirmed() {
+		return errChannelClosed
+	}
+	if pc.revision.NewRevisionNumber == 0 {
+		return errNoChannelRevisions
+	}
+	if len(txn.FileContractRevisions) != 1 || txn.FileContractRevisions[0].ParentID != pc.ID || txn.FileContractRevisions[0].NewRevisionNumber != pc.revision.NewRevisionNumber {
+		return errStaleChannelRevision
+	}
+
+	err := pc.wallet.tpool.AcceptTransactionSet([]types.Transaction{txn})
+	if err != nil && err != modules.ErrDuplicateTransactionSet {
+		return err
+	}
+	pc.closing = true
+	return nil
+}
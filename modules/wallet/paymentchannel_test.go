@@ -0,0 +1,152 @@
+package wallet
+
+import (
+	"testing"
+
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestIntegrationPaymentChannel opens a payment channel, sends several
+// payments down it off-chain, and checks that closing the channel pays the
+// final balance to the payee.
+func TestIntegrationPaymentChannel(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester("TestIntegrationPaymentChannel")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+	payee, payeeUC, err := wt.createSecondWallet("TestIntegrationPaymentChannel")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer payee.Close()
+
+	// Open the channel.
+	pc, err := wt.wallet.OpenPaymentChannel(payeeUC.PublicKeys[0], payeeUC.UnlockHash(), types.SiacoinPrecision.Mul64(100), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = wt.miner.AddBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Perform several updates, having the payee countersign each revision.
+	var revisions []types.Transaction
+	for _, amount := range []uint64{10, 25, 40} {
+		txn, err := pc.Update(types.SiacoinPrecision.Mul64(amount))
+		if err != nil {
+			t.Fatal(err)
+		}
+		txn, err = payee.AddSignature(txn, pc.UnlockConditions, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		revisions = append(revisions, txn)
+	}
+	if pc.Balance().Cmp(types.SiacoinPrecision.Mul64(40)) != 0 {
+		t.Fatal("channel has the wrong balance:", pc.Balance())
+	}
+	_, err = pc.Update(types.SiacoinPrecision.Mul64(30))
+	if err != errChannelBalanceDecrease {
+		t.Fatal("expected errChannelBalanceDecrease, got", err)
+	}
+	_, err = pc.Update(pc.Capacity.Add(types.NewCurrency64(1)))
+	if err != errChannelOverdrawn {
+		t.Fatal("expected errChannelOverdrawn, got", err)
+	}
+
+	// Only the latest revision can be used to close the channel.
+	err = pc.Close(revisions[0])
+	if err != errStaleChannelRevision {
+		t.Fatal("expected errStaleChannelRevision, got", err)
+	}
+	err = pc.Close(revisions[len(revisions)-1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = pc.Update(types.SiacoinPrecision.Mul64(50))
+	if err != errChannelClosed {
+		t.Fatal("expected errChannelClosed, got", err)
+	}
+
+	// The closing transaction can be re-broadcast until it is confirmed, for
+	// example after it was dropped from the transaction pool.
+	wt.tpool.PurgeTransactionPool()
+	err = pc.Close(revisions[len(revisions)-1])
+	if err != nil {
+		t.Fatal("could not re-broadcast the closing transaction:", err)
+	}
+	_, err = wt.miner.AddBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = pc.Close(revisions[len(revisions)-1])
+	if err != errChannelClosed {
+		t.Fatal("expected errChannelClosed after the closing transaction was confirmed, got", err)
+	}
+
+	// Mine until the contract outputs have matured and check that the payee
+	// received the final balance.
+	for wt.cs.Height() <= pc.Expiration()+types.MaturityDelay {
+		_, err = wt.miner.AddBlock()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	balance, _, _ := payee.ConfirmedBalance()
+	if balance.Cmp(types.SiacoinPrecision.Mul64(40)) != 0 {
+		t.Fatal("payee received the wrong amount:", balance)
+	}
+}
+
+// TestIntegrationPaymentChannelTimeout checks that a payment channel that is
+// never closed refunds the payer once the contract expires.
+func TestIntegrationPaymentChannelTimeout(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester("TestIntegrationPaymentChannelTimeout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+	payee, payeeUC, err := wt.createSecondWallet("TestIntegrationPaymentChannelTimeout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer payee.Close()
+
+	pc, err := wt.wallet.OpenPaymentChannel(payeeUC.PublicKeys[0], payeeUC.UnlockHash(), types.SiacoinPrecision.Mul64(100), 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = pc.Update(types.SiacoinPrecision.Mul64(10))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Mine past the expiration without closing the channel.
+	for wt.cs.Height() <= pc.Expiration()+types.MaturityDelay {
+		_, err = wt.miner.AddBlock()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	balance, _, _ := payee.ConfirmedBalance()
+	if !balance.IsZero() {
+		t.Fatal("payee was paid from a channel that was never closed:", balance)
+	}
+	wt.wallet.mu.RLock()
+	refund, exists := wt.wallet.siacoinOutputs[pc.ID.StorageProofOutputID(types.ProofMissed, 0)]
+	wt.wallet.mu.RUnlock()
+	if !exists {
+		t.Fatal("payer did not receive the refund of the channel")
+	}
+	if refund.Value.Cmp(pc.Capacity) != 0 {
+		t.Fatal("payer refund does not match the channel capacity:", refund.Value, pc.Capacity)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/NebulousLabs/Sia/crypto"
 	"github.com/NebulousLabs/Sia/modules"
 	"github.com/NebulousLabs/Sia/persist"
+	"github.com/NebulousLabs/Sia/types"
 )
 
 const (
@@ -36,6 +37,12 @@ type SpendableKeyFile struct {
 	SpendableKey           crypto.Ciphertext
 }
 
+// AddressLabel is a label attached to an address by the user.
+type AddressLabel struct {
+	UnlockHash types.UnlockHash
+	Label      string
+}
+
 // WalletPersist contains all data that persists on disk during wallet
 // operation.
 type WalletPersist struct {
@@ -61,6 +68,14 @@ type WalletPersist struct {
 	// UnseededKeys are list of spendable keys that were not generated by a
 	// random seed.
 	UnseededKeys []SpendableKeyFile
+
+	// WatchedAddresses are addresses that the wallet tracks but is not able
+	// to spend from.
+	WatchedAddresses []types.UnlockHash
+
+	// AddressLabels are the labels that the user has attached to wallet
+	// addresses and to external payees.
+	AddressLabels []AddressLabel
 }
 
 // loadSettings reads the wallet's settings from the wallet's settings file,
@@ -124,6 +139,12 @@ func (w *Wallet) initPersist() error {
 	if err != nil {
 		return err
 	}
+	for _, uh := range w.persist.WatchedAddresses {
+		w.watchedAddresses[uh] = struct{}{}
+	}
+	for _, al := range w.persist.AddressLabels {
+		w.addressLabels[al.UnlockHash] = al.Label
+	}
 	return nil
 }
 
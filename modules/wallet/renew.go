@@ -0,0 +1,114 @@
+package wallet
+
+import (
+	"errors"
+
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+var (
+	errRenewalUnsignable   = errors.New("file contract cannot be revised by the wallet")
+	errRenewalWindowEnd    = errors.New("renewed file contract must end after the original file contract")
+	errRenewalWindowOpen   = errors.New("cannot renew a file contract after its proof window has opened")
+	errUnknownFileContract = errors.New("file contract is not known to the wallet")
+)
+
+// updateFileContracts uses a consensus change to update the set of open file
+// contracts that pay out to the wallet. A revision is reported as the removal
+// of the old contract followed by the addition of the revised contract.
+func (w *Wallet) updateFileContracts(cc modules.ConsensusChange) {
+	for _, diff := range cc.FileContractDiffs {
+		if !w.fileContractIsRelevant(diff.FileContract) {
+			continue
+		}
+		if diff.Direction == modules.DiffApply {
+			w.fileContracts[diff.ID] = diff.FileContract
+		} else {
+			delete(w.fileContracts, diff.ID)
+		}
+	}
+}
+
+// fileContractIsRelevant returns true if any of the outputs of the file
+// contract pay out to the wallet.
+func (w *Wallet) fileContractIsRelevant(fc types.FileContract) bool {
+	for _, sco := range fc.ValidProofOutputs {
+		if _, exists := w.keys[sco.UnlockHash]; exists {
+			return true
+		}
+	}
+	for _, sco := range fc.MissedProofOutputs {
+		if _, exists := w.keys[sco.UnlockHash]; exists {
+			return true
+		}
+	}
+	return false
+}
+
+// RenewContract renews the file contract 'oldID' so that it continues until
+// 'newWindowEnd'. The renewal is a revision of the old contract that keeps the
+// same file and outputs, carrying over the whole remaining balance, and
+// shifts the proof window so that it ends at 'newWindowEnd'. No new funds are
+// needed, and the data stays covered by the contract for the whole renewal.
+// The contract must be revisable by the wallet and its proof window must not
+// have opened yet. The signed revision is submitted to the transaction pool.
+func (w *Wallet) RenewContract(oldID types.FileContractID, newWindowEnd types.BlockHeight) (types.Transaction, error) {
+	if err := w.tg.Add(); err != nil {
+		return types.Transaction{}, err
+	}
+	defer w.tg.Done()
+
+	w.mu.Lock()
+	oldFC, exists := w.fileContracts[oldID]
+	height := w.consensusSetHeight
+	if !exists {
+		w.mu.Unlock()
+		return types.Transaction{}, errUnknownFileContract
+	}
+	if height >= oldFC.WindowStart {
+		w.mu.Unlock()
+		return types.Transaction{}, errRenewalWindowOpen
+	}
+	if newWindowEnd <= oldFC.WindowEnd {
+		w.mu.Unlock()
+		return types.Transaction{}, errRenewalWindowEnd
+	}
+	if !w.unlocked {
+		w.mu.Unlock()
+		return types.Transaction{}, modules.ErrLockedWallet
+	}
+	key, exists := w.keys[oldFC.UnlockHash]
+	if !exists {
+		w.mu.Unlock()
+		return types.Transaction{}, errRenewalUnsignable
+	}
+
+	extension := newWindowEnd - oldFC.WindowEnd
+	txn := types.Transaction{
+		FileContractRevisions: []types.FileContractRevision{{
+			ParentID:              oldID,
+			UnlockConditions:      key.UnlockConditions,
+			NewRevisionNumber:     oldFC.RevisionNumber + 1,
+			NewFileSize:           oldFC.FileSize,
+			NewFileMerkleRoot:     oldFC.FileMerkleRoot,
+			NewWindowStart:        oldFC.WindowStart + extension,
+			NewWindowEnd:          newWindowEnd,
+			NewValidProofOutputs:  append([]types.SiacoinOutput(nil), oldFC.ValidProofOutputs...),
+			NewMissedProofOutputs: append([]types.SiacoinOutput(nil), oldFC.MissedProofOutputs...),
+			NewUnlockHash:         oldFC.UnlockHash,
+		}},
+	}
+	_, err := addSignatures(&txn, types.CoveredFields{WholeTransaction: true}, key.UnlockConditions, crypto.Hash(oldID), key)
+	w.mu.Unlock()
+	if err != nil {
+		return types.Transaction{}, err
+	}
+
+	err = w.tpool.AcceptTransactionSet([]types.Transaction{txn})
+	if err != nil {
+		return types.Transaction{}, err
+	}
+	return txn, nil
+}
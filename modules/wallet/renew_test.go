@@ -0,0 +1,147 @@
+package wallet
+
+import (
+	"testing"
+
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestIntegrationRenewContract creates a file contract that pays out to the
+// wallet, renews it before its window opens, checks that the renewal carries
+// over the balance of the contract without spending wallet funds, and checks
+// that renewing is rejected once the window has opened.
+func TestIntegrationRenewContract(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester("TestIntegrationRenewContract")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	// Create a file contract paying out to the wallet.
+	uc, err := wt.wallet.NextAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := crypto.RandBytes(4096)
+	if err != nil {
+		t.Fatal(err)
+	}
+	height := wt.cs.Height()
+	payout := types.SiacoinPrecision.Mul64(100)
+	outputs := []types.SiacoinOutput{{Value: types.PostTax(height, payout), UnlockHash: uc.UnlockHash()}}
+	fc := types.FileContract{
+		FileSize:           uint64(len(data)),
+		FileMerkleRoot:     crypto.MerkleRoot(data),
+		WindowStart:        height + 5,
+		WindowEnd:          height + 10,
+		Payout:             payout,
+		ValidProofOutputs:  outputs,
+		MissedProofOutputs: outputs,
+		UnlockHash:         uc.UnlockHash(),
+	}
+	txnBuilder := wt.wallet.StartTransaction()
+	err = txnBuilder.FundSiacoins(payout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fcIndex := txnBuilder.AddFileContract(fc)
+	txnSet, err := txnBuilder.Sign(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = wt.tpool.AcceptTransactionSet(txnSet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = wt.miner.AddBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldID := txnSet[len(txnSet)-1].FileContractID(fcIndex)
+
+	// Renewing an unknown contract or shortening the contract should fail.
+	_, err = wt.wallet.RenewContract(types.FileContractID{}, fc.WindowEnd+10)
+	if err != errUnknownFileContract {
+		t.Fatal("expected errUnknownFileContract, got", err)
+	}
+	_, err = wt.wallet.RenewContract(oldID, fc.WindowEnd)
+	if err != errRenewalWindowEnd {
+		t.Fatal("expected errRenewalWindowEnd, got", err)
+	}
+
+	// Renew the contract while it is still open. The renewal should not
+	// spend any of the wallet's outputs.
+	balanceBefore, _, _ := wt.wallet.ConfirmedBalance()
+	txn, err := wt.wallet.RenewContract(oldID, fc.WindowEnd+10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(txn.FileContractRevisions) != 1 || len(txn.SiacoinInputs) != 0 || len(txn.FileContracts) != 0 {
+		t.Fatal("renewal transaction should contain only a revision of the old contract")
+	}
+	outgoing, _ := wt.wallet.UnconfirmedBalance()
+	if !outgoing.IsZero() {
+		t.Error("renewal should not spend any siacoins, spent", outgoing)
+	}
+	balanceAfter, _, _ := wt.wallet.ConfirmedBalance()
+	if balanceAfter.Cmp(balanceBefore) != 0 {
+		t.Error("wallet balance changed after renewal:", balanceBefore, balanceAfter)
+	}
+	_, err = wt.miner.AddBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The wallet should track the renewed contract under the old id, with
+	// the same file and balance but a later window.
+	wt.wallet.mu.RLock()
+	newFC, exists := wt.wallet.fileContracts[oldID]
+	wt.wallet.mu.RUnlock()
+	if !exists {
+		t.Fatal("wallet is not tracking the renewed contract")
+	}
+	if newFC.FileMerkleRoot != fc.FileMerkleRoot || newFC.FileSize != fc.FileSize {
+		t.Error("renewed contract does not cover the same file")
+	}
+	if newFC.WindowStart != fc.WindowStart+10 || newFC.WindowEnd != fc.WindowEnd+10 {
+		t.Error("renewed contract has the wrong window:", newFC.WindowStart, newFC.WindowEnd)
+	}
+	if newFC.Payout.Cmp(fc.Payout) != 0 || newFC.ValidProofOutputs[0].Value.Cmp(outputs[0].Value) != 0 || newFC.MissedProofOutputs[0].Value.Cmp(outputs[0].Value) != 0 {
+		t.Error("renewed contract does not carry over the remaining balance")
+	}
+	if newFC.RevisionNumber != fc.RevisionNumber+1 {
+		t.Error("renewed contract has the wrong revision number:", newFC.RevisionNumber)
+	}
+
+	// The renewed contract is still open once the original window would
+	// have opened, and can be renewed again. Once the window of the renewed
+	// contract has opened, it can no longer be renewed.
+	for wt.cs.Height() < fc.WindowStart {
+		_, err = wt.miner.AddBlock()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, err = wt.wallet.RenewContract(oldID, fc.WindowEnd+20)
+	if err != nil {
+		t.Fatal("renewed contract could not be renewed again:", err)
+	}
+	_, err = wt.miner.AddBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for wt.cs.Height() < fc.WindowStart+20 {
+		_, err = wt.miner.AddBlock()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, err = wt.wallet.RenewContract(oldID, fc.WindowEnd+30)
+	if err != errRenewalWindowOpen {
+		t.Fatal("expected errRenewalWindowOpen, got", err)
+	}
+}
@@ -0,0 +1,97 @@
+package wallet
+
+import (
+	"errors"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// resetConsensusState discards all of the state that the wallet has derived
+// from the consensus set, leaving the keys, watched addresses, and labels of
+// the wallet intact.
+func (w *Wallet) resetConsensusState() {
+	w.consensusSetHeight = 0
+	w.siafundPool = types.Currency{}
+
+	w.siacoinOutputs = make(map[types.SiacoinOutputID]types.SiacoinOutput)
+	w.siafundOutputs = make(map[types.SiafundOutputID]types.SiafundOutput)
+	w.delayedSiacoinOutputs = make(map[types.SiacoinOutputID]types.SiacoinOutput)
+
+	w.processedTransactions = nil
+	w.processedTransactionMap = make(map[types.TransactionID]*modules.ProcessedTransaction)
+
+	w.historicOutputs = make(map[types.OutputID]types.Currency)
+	w.historicClaimStarts = make(map[types.SiafundOutputID]types.Currency)
+
+	w.watchedSiacoinOutputs = make(map[types.SiacoinOutputID]types.SiacoinOutput)
+	w.watchedSiafundOutputs = make(map[types.SiafundOutputID]types.SiafundOutput)
+
+	w.fileContracts = make(map[types.FileContractID]types.FileContract)
+
+	w.recentBlockFees = nil
+}
+
+// Rescan rebuilds the wallet's outputs, balance, and transaction history by
+// replaying the blockchain from the genesis block. Before replaying, the
+// blockchain is scanned for addresses of the primary seed that the wallet
+// has not generated yet, so that their outputs are recovered as well. Updates
+// from the consensus set are paused for the duration of the rescan.
+func (w *Wallet) Rescan() error {
+	if err := w.tg.Add(); err != nil {
+		return err
+	}
+	defer w.tg.Done()
+
+	w.mu.RLock()
+	unlocked := w.unlocked
+	seed := w.primarySeed
+	w.mu.RUnlock()
+	if !unlocked {
+		return modules.ErrLockedWallet
+	}
+
+	// Scan the blockchain for addresses belonging to the primary seed. The
+	// scan is performed without holding the wallet lock, as it may take a
+	// while.
+	s := newSeedScanner(seed)
+	err := s.scan(w.cs)
+	if err != nil {
+		return err
+	}
+
+	// Stop receiving consensus changes and discard the current state.
+	w.cs.Unsubscribe(w)
+	err = func() error {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		w.subscribed = false
+
+		if s.seenAny && s.largestIndexSeen+1 > w.persist.PrimarySeedProgress {
+			w.persist.PrimarySeedProgress = s.largestIndexSeen + 1
+			for i := uint64(0); i < w.persist.PrimarySeedProgress+modules.WalletSeedPreloadDepth; i++ {
+				spendableKey := generateSpendableKey(seed, i)
+				w.keys[spendableKey.UnlockConditions.UnlockHash()] = spendableKey
+			}
+			err := w.saveSettingsSync()
+			if err != nil {
+				return err
+			}
+		}
+		w.resetConsensusState()
+		return nil
+	}()
+	if err != nil {
+		return err
+	}
+
+	// Replay the blockchain.
+	err = w.cs.ConsensusSetSubscribe(w, modules.ConsensusChangeBeginning)
+	if err != nil {
+		return errors.New("wallet subscription failed: " + err.Error())
+	}
+	w.mu.Lock()
+	w.subscribed = true
+	w.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,78 @@
+package wallet
+
+import (
+	"testing"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestRescan corrupts the state of a wallet and checks that a rescan
+// restores its balance, including coins sent to an address of the primary
+// seed that the wallet had not generated yet.
+func TestRescan(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester("TestRescan")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	// Send coins to an address of the primary seed beyond the preloaded
+	// addresses.
+	wt.wallet.mu.RLock()
+	index := wt.wallet.persist.PrimarySeedProgress + modules.WalletSeedPreloadDepth + 10
+	uh := generateSpendableKey(wt.wallet.primarySeed, index).UnlockConditions.UnlockHash()
+	wt.wallet.mu.RUnlock()
+	sendAmount := types.NewCurrency64(5e9)
+	_, err = wt.wallet.SendSiacoins(sendAmount, uh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = wt.miner.AddBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	balance, _, _ := wt.wallet.ConfirmedBalance()
+	height := wt.wallet.consensusSetHeight
+
+	// Corrupt the wallet state.
+	wt.wallet.mu.Lock()
+	wt.wallet.resetConsensusState()
+	wt.wallet.consensusSetHeight = 3
+	wt.wallet.mu.Unlock()
+	corrupted, _, _ := wt.wallet.ConfirmedBalance()
+	if !corrupted.IsZero() {
+		t.Fatal("wallet state was not corrupted")
+	}
+
+	err = wt.wallet.Rescan()
+	if err != nil {
+		t.Fatal(err)
+	}
+	recovered, _, _ := wt.wallet.ConfirmedBalance()
+	if recovered.Cmp(balance.Add(sendAmount)) != 0 {
+		t.Fatalf("expected balance %v after rescan, got %v", balance.Add(sendAmount), recovered)
+	}
+	wt.wallet.mu.RLock()
+	if wt.wallet.consensusSetHeight != height {
+		t.Error("wallet height was not restored:", wt.wallet.consensusSetHeight, height)
+	}
+	if wt.wallet.persist.PrimarySeedProgress <= index {
+		t.Error("primary seed progress was not advanced past the used address")
+	}
+	wt.wallet.mu.RUnlock()
+
+	// The wallet keeps following the consensus set after the rescan.
+	_, err = wt.miner.AddBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	wt.wallet.mu.RLock()
+	if wt.wallet.consensusSetHeight != height+1 {
+		t.Error("wallet did not receive updates after the rescan")
+	}
+	wt.wallet.mu.RUnlock()
+}
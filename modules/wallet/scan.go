@@ -0,0 +1,108 @@
+package wallet
+
+import (
+	"github.com/NebulousLabs/Sia/build"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+var (
+	// numInitialKeys is the number of keys generated by the seedScanner
+	// before scanning the blockchain for the first time.
+	numInitialKeys = func() uint64 {
+		switch build.Release {
+		case "dev":
+			return 10e3
+		case "standard":
+			return 100e3
+		case "testing":
+			return 1e3
+		default:
+			panic("unrecognized build.Release")
+		}
+	}()
+
+	// maxScanKeys is the number of keys the seedScanner is willing to
+	// generate before giving up on finding the end of the used addresses.
+	maxScanKeys = uint64(10e6)
+)
+
+// A seedScanner scans the blockchain for addresses that belong to a given
+// seed, and tracks the largest index of an address that has been seen.
+type seedScanner struct {
+	seed             modules.Seed
+	keys             map[types.UnlockHash]uint64 // map to index
+	largestIndexSeen uint64
+	seenAny          bool
+}
+
+// numKeys returns the number of keys that the seedScanner has generated.
+func (s *seedScanner) numKeys() uint64 {
+	return uint64(len(s.keys))
+}
+
+// generateKeys generates n additional keys from the seedScanner's seed.
+func (s *seedScanner) generateKeys(n uint64) {
+	initialProgress := s.numKeys()
+	for i := initialProgress; i < initialProgress+n; i++ {
+		uc := generateSpendableKey(s.seed, i).UnlockConditions
+		s.keys[uc.UnlockHash()] = i
+	}
+}
+
+// markSeen records that the address with the provided unlock hash has
+// appeared on the blockchain.
+func (s *seedScanner) markSeen(uh types.UnlockHash) {
+	index, exists := s.keys[uh]
+	if !exists {
+		return
+	}
+	if !s.seenAny || index > s.largestIndexSeen {
+		s.largestIndexSeen = index
+	}
+	s.seenAny = true
+}
+
+// ProcessConsensusChange scans the consensus change for addresses that
+// belong to the seedScanner's seed.
+func (s *seedScanner) ProcessConsensusChange(cc modules.ConsensusChange) {
+	for _, diff := range cc.SiacoinOutputDiffs {
+		s.markSeen(diff.SiacoinOutput.UnlockHash)
+	}
+	for _, diff := range cc.SiafundOutputDiffs {
+		s.markSeen(diff.SiafundOutput.UnlockHash)
+	}
+	for _, diff := range cc.DelayedSiacoinOutputDiffs {
+		s.markSeen(diff.SiacoinOutput.UnlockHash)
+	}
+}
+
+// scan subscribes the seedScanner to the consensus set, generating more keys
+// and rescanning until the number of unused keys following the largest index
+// seen is at least numInitialKeys.
+func (s *seedScanner) scan(cs modules.ConsensusSet) error {
+	numKeys := numInitialKeys
+	for s.numKeys() < maxScanKeys {
+		s.generateKeys(numKeys)
+		err := cs.ConsensusSetSubscribe(s, modules.ConsensusChangeBeginning)
+		if err != nil {
+			return err
+		}
+		cs.Unsubscribe(s)
+		if !s.seenAny || s.largestIndexSeen+numInitialKeys <= s.numKeys() {
+			return nil
+		}
+		// Double the number of keys generated each iteration to keep the
+		// number of rescans low.
+		numKeys *= 2
+	}
+	return nil
+}
+
+// newSeedScanner returns a seedScanner for the provided seed.
+func newSeedScanner(seed modules.Seed) *seedScanner {
+	return &seedScanner{
+		seed: seed,
+		keys: make(map[types.UnlockHash]uint64),
+	}
+}
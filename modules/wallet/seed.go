@@ -157,18 +157,19 @@ func (w *Wallet) recoverSeed(masterKey crypto.TwofishKey, seed modules.Seed) err
 
 // createSeed creates a wallet seed and encrypts it using a key derived from
 // the master key, then addds it to the wallet as the primary seed, while
-// making a disk backup.
-func (w *Wallet) createSeed(masterKey crypto.TwofishKey, seed modules.Seed) error {
+// making a disk backup. 'progress' is the number of addresses of the seed that
+// are already in use.
+func (w *Wallet) createSeed(masterKey crypto.TwofishKey, seed modules.Seed, progress uint64) error {
 	seedFile, err := w.encryptAndSaveSeedFile(masterKey, seed)
 	if err != nil {
 		return err
 	}
 	w.primarySeed = seed
 	w.persist.PrimarySeedFile = seedFile
-	w.persist.PrimarySeedProgress = 0
+	w.persist.PrimarySeedProgress = progress
 	// The wallet preloads keys to prevent confusion for people using the same
 	// seed/wallet file in multiple places.
-	for i := uint64(0); i < modules.WalletSeedPreloadDepth; i++ {
+	for i := uint64(0); i < progress+modules.WalletSeedPreloadDepth; i++ {
 		spendableKey := generateSpendableKey(seed, i)
 		w.keys[spendableKey.UnlockConditions.UnlockHash()] = spendableKey
 	}
@@ -173,3 +173,70 @@ func TestLoadSeed(t *testing.T) {
 		t.Error("AllSeeds returned the wrong seed")
 	}
 }
+
+// TestInitFromSeed checks that a wallet initialized from an existing seed
+// recovers all of the addresses that have been used by the seed.
+func TestInitFromSeed(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester("TestInitFromSeed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+	seed, _, err := wt.wallet.PrimarySeed()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Fund several addresses derived from the seed, including addresses
+	// beyond the preload depth of the wallet.
+	for _, index := range []uint64{3, modules.WalletSeedPreloadDepth + 10, 2 * modules.WalletSeedPreloadDepth} {
+		uh := generateSpendableKey(seed, index).UnlockConditions.UnlockHash()
+		_, err = wt.wallet.SendSiacoins(types.SiacoinPrecision.Mul64(100), uh)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, err = wt.miner.AddBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The original wallet only tracks the addresses within its preload depth,
+	// and therefore is not aware of the coins sent to the other two.
+	siacoinBal, _, _ := wt.wallet.ConfirmedBalance()
+	siacoinBal = siacoinBal.Add(types.SiacoinPrecision.Mul64(200))
+
+	// Recreate the wallet from the seed, all of the coins should be found.
+	dir := filepath.Join(build.TempDir(modules.WalletDir, "TestInitFromSeed - 0"), modules.WalletDir)
+	w, err := New(wt.cs, wt.tpool, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = w.InitFromSeed(crypto.TwofishKey{}, seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = w.Unlock(crypto.TwofishKey(crypto.HashObject(seed)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	siacoinBal2, _, _ := w.ConfirmedBalance()
+	if siacoinBal2.Cmp(siacoinBal) != 0 {
+		t.Error("recovered wallet has a different balance:", siacoinBal2, siacoinBal)
+	}
+	_, progress, err := w.PrimarySeed()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if progress <= 2*modules.WalletSeedPreloadDepth {
+		t.Error("recovered wallet did not account for the used addresses:", progress)
+	}
+
+	// The wallet cannot be initialized twice.
+	err = w.InitFromSeed(crypto.TwofishKey{}, seed)
+	if err != errReencrypt {
+		t.Error("expecting errReencrypt, got", err)
+	}
+}
@@ -0,0 +1,131 @@
+package wallet
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+var (
+	errInputIndexOutOfRange = errors.New("input index does not point to an input of the transaction")
+	errForeignInput         = errors.New("input is not controlled by the wallet")
+)
+
+// partialCoveredFields returns covered fields that cover the provided inputs
+// and every other field of the transaction except the remaining inputs and the
+// signatures. Counterparties can then add and sign their own inputs without
+// invalidating the signatures.
+func partialCoveredFields(txn types.Transaction, siacoinInputs, siafundInputs []uint64) types.CoveredFields {
+	cf := types.CoveredFields{
+		SiacoinInputs: siacoinInputs,
+		SiafundInputs: siafundInputs,
+	}
+	for i := range txn.MinerFees {
+		cf.MinerFees = append(cf.MinerFees, uint64(i))
+	}
+	for i := range txn.SiacoinOutputs {
+		cf.SiacoinOutputs = append(cf.SiacoinOutputs, uint64(i))
+	}
+	for i := range txn.FileContracts {
+		cf.FileContracts = append(cf.FileContracts, uint64(i))
+	}
+	for i := range txn.FileContractRevisions {
+		cf.FileContractRevisions = append(cf.FileContractRevisions, uint64(i))
+	}
+	for i := range txn.StorageProofs {
+		cf.StorageProofs = append(cf.StorageProofs, uint64(i))
+	}
+	for i := range txn.SiafundOutputs {
+		cf.SiafundOutputs = append(cf.SiafundOutputs, uint64(i))
+	}
+	for i := range txn.ArbitraryData {
+		cf.ArbitraryData = append(cf.ArbitraryData, uint64(i))
+	}
+	return cf
+}
+
+// sortedUniqueIndices checks that the indices point into a list of length
+// 'max', and returns them sorted and without duplicates.
+func sortedUniqueIndices(indices []int, max int) ([]uint64, error) {
+	seen := make(map[int]struct{})
+	var sorted []int
+	for _, i := range indices {
+		if i < 0 || i >= max {
+			return nil, errInputIndexOutOfRange
+		}
+		if _, exists := seen[i]; exists {
+			continue
+		}
+		seen[i] = struct{}{}
+		sorted = append(sorted, i)
+	}
+	sort.Ints(sorted)
+	var us []uint64
+	for _, i := range sorted {
+		us = append(us, uint64(i))
+	}
+	return us, nil
+}
+
+// SignTransactionInputs signs only the designated siacoin and siafund inputs
+// of a transaction, leaving the remaining inputs to be signed by
+// counterparties. Every designated input must be controlled by the wallet.
+// The signatures cover the signed inputs and all outputs, fees and other
+// fields of the transaction, but not the other inputs or any signatures.
+func (w *Wallet) SignTransactionInputs(txn types.Transaction, siacoinInputs []int, siafundInputs []int) (types.Transaction, error) {
+	if err := w.tg.Add(); err != nil {
+		return types.Transaction{}, err
+	}
+	defer w.tg.Done()
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if !w.unlocked {
+		return types.Transaction{}, modules.ErrLockedWallet
+	}
+	scIndices, err := sortedUniqueIndices(siacoinInputs, len(txn.SiacoinInputs))
+	if err != nil {
+		return types.Transaction{}, err
+	}
+	sfIndices, err := sortedUniqueIndices(siafundInputs, len(txn.SiafundInputs))
+	if err != nil {
+		return types.Transaction{}, err
+	}
+	for _, i := range scIndices {
+		if _, exists := w.keys[txn.SiacoinInputs[i].UnlockConditions.UnlockHash()]; !exists {
+			return types.Transaction{}, errForeignInput
+		}
+	}
+	for _, i := range sfIndices {
+		if _, exists := w.keys[txn.SiafundInputs[i].UnlockConditions.UnlockHash()]; !exists {
+			return types.Transaction{}, errForeignInput
+		}
+	}
+
+	// Copy the signatures so that the input transaction is not modified.
+	sigs := make([]types.TransactionSignature, len(txn.TransactionSignatures))
+	copy(sigs, txn.TransactionSignatures)
+	txn.TransactionSignatures = sigs
+
+	cf := partialCoveredFields(txn, scIndices, sfIndices)
+	for _, i := range scIndices {
+		input := txn.SiacoinInputs[i]
+		key := w.keys[input.UnlockConditions.UnlockHash()]
+		_, err := addSignatures(&txn, cf, input.UnlockConditions, crypto.Hash(input.ParentID), key)
+		if err != nil {
+			return types.Transaction{}, err
+		}
+	}
+	for _, i := range sfIndices {
+		input := txn.SiafundInputs[i]
+		key := w.keys[input.UnlockConditions.UnlockHash()]
+		_, err := addSignatures(&txn, cf, input.UnlockConditions, crypto.Hash(input.ParentID), key)
+		if err != nil {
+			return types.Transaction{}, err
+		}
+	}
+	return txn, nil
+}
@@ -0,0 +1,105 @@
+package wallet
+
+import (
+	"testing"
+
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestIntegrationSignTransactionInputs creates a transaction with inputs from
+// two wallets, has each wallet sign only its own inputs, and checks that the
+// combined transaction is accepted.
+func TestIntegrationSignTransactionInputs(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester("TestIntegrationSignTransactionInputs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	// Create a second wallet on the same consensus set and fund it.
+	w2, uc2, err := wt.createSecondWallet("TestIntegrationSignTransactionInputs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w2.Close()
+	value := types.SiacoinPrecision.Mul64(100)
+	_, err = wt.wallet.SendSiacoins(value, uc2.UnlockHash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = wt.miner.AddBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Find an output of each wallet.
+	var input1, input2 types.SiacoinInput
+	var value1 types.Currency
+	wt.wallet.mu.RLock()
+	for id, sco := range wt.wallet.siacoinOutputs {
+		if sco.Value.Cmp(value) > 0 {
+			input1 = types.SiacoinInput{ParentID: id, UnlockConditions: wt.wallet.keys[sco.UnlockHash].UnlockConditions}
+			value1 = sco.Value
+			break
+		}
+	}
+	wt.wallet.mu.RUnlock()
+	w2.mu.RLock()
+	for id := range w2.siacoinOutputs {
+		input2 = types.SiacoinInput{ParentID: id, UnlockConditions: uc2}
+	}
+	w2.mu.RUnlock()
+
+	// Build a transaction that spends both outputs.
+	txn := types.Transaction{
+		SiacoinInputs: []types.SiacoinInput{input1, input2},
+		SiacoinOutputs: []types.SiacoinOutput{{
+			Value: value1.Add(value),
+		}},
+	}
+
+	// Each wallet may only sign its own inputs.
+	_, err = wt.wallet.SignTransactionInputs(txn, []int{1}, nil)
+	if err != errForeignInput {
+		t.Fatal("expecting errForeignInput, got", err)
+	}
+	_, err = wt.wallet.SignTransactionInputs(txn, []int{2}, nil)
+	if err != errInputIndexOutOfRange {
+		t.Fatal("expecting errInputIndexOutOfRange, got", err)
+	}
+
+	// Sign the first input with the first wallet. The signature should only
+	// cover the first input, and the transaction is not yet valid.
+	signed, err := wt.wallet.SignTransactionInputs(txn, []int{0}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(signed.TransactionSignatures) != 1 {
+		t.Fatal("expecting one signature, got", len(signed.TransactionSignatures))
+	}
+	cf := signed.TransactionSignatures[0].CoveredFields
+	if cf.WholeTransaction || len(cf.SiacoinInputs) != 1 || cf.SiacoinInputs[0] != 0 || len(cf.SiacoinOutputs) != 1 {
+		t.Error("covered fields do not reflect the partial scope:", cf)
+	}
+	err = wt.tpool.AcceptTransactionSet([]types.Transaction{signed})
+	if err == nil {
+		t.Fatal("partially signed transaction was accepted")
+	}
+
+	// Sign the second input with the second wallet, the combined transaction
+	// should be accepted.
+	signed, err = w2.SignTransactionInputs(signed, []int{1}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(signed.TransactionSignatures) != 2 {
+		t.Fatal("expecting two signatures, got", len(signed.TransactionSignatures))
+	}
+	err = wt.tpool.AcceptTransactionSet([]types.Transaction{signed})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
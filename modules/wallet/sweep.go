@@ -0,0 +1,135 @@
+package wallet
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/encoding"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+var (
+	errSweepInsufficientKeys = errors.New("the provided key cannot satisfy the unlock conditions")
+	errSweepNoOutputs        = errors.New("no siacoin outputs were found for the address")
+	errSweepNotProfitable    = errors.New("sweeping the address would cost more in fees than the address holds")
+)
+
+// An addressScanner scans the blockchain for the unspent siacoin outputs of a
+// single address.
+type addressScanner struct {
+	address types.UnlockHash
+	outputs map[types.SiacoinOutputID]types.SiacoinOutput
+}
+
+// ProcessConsensusChange adds the outputs created for the scanner's address
+// and removes the outputs that were spent.
+func (s *addressScanner) ProcessConsensusChange(cc modules.ConsensusChange) {
+	for _, diff := range cc.SiacoinOutputDiffs {
+		if diff.SiacoinOutput.UnlockHash != s.address {
+			continue
+		}
+		if diff.Direction == modules.DiffApply {
+			s.outputs[diff.ID] = diff.SiacoinOutput
+		} else {
+			delete(s.outputs, diff.ID)
+		}
+	}
+}
+
+// SweepAddress moves all of the confirmed siacoins locked to the address of
+// the provided unlock conditions into the wallet, signing with the provided
+// key. The miner fee is subtracted from the swept funds. The transaction is
+// submitted to the transaction pool and is also returned.
+func (w *Wallet) SweepAddress(uc types.UnlockConditions, key crypto.SecretKey) (types.Transaction, error) {
+	if err := w.tg.Add(); err != nil {
+		return types.Transaction{}, err
+	}
+	defer w.tg.Done()
+
+	// Check that the key is able to spend from the address.
+	pk := key.PublicKey()
+	var keyFound bool
+	for _, spk := range uc.PublicKeys {
+		if spk.Algorithm == types.SignatureEd25519 && bytes.Equal(spk.Key, pk[:]) {
+			keyFound = true
+		}
+	}
+	if !keyFound || uc.SignaturesRequired != 1 {
+		return types.Transaction{}, errSweepInsufficientKeys
+	}
+
+	// Find the outputs of the address. The scan is performed without holding
+	// the wallet lock, as it may take a while.
+	s := &addressScanner{
+		address: uc.UnlockHash(),
+		outputs: make(map[types.SiacoinOutputID]types.SiacoinOutput),
+	}
+	err := w.cs.ConsensusSetSubscribe(s, modules.ConsensusChangeBeginning)
+	if err != nil {
+		return types.Transaction{}, err
+	}
+	w.cs.Unsubscribe(s)
+	if len(s.outputs) == 0 {
+		return types.Transaction{}, errSweepNoOutputs
+	}
+
+	txn, err := w.managedCreateSweepTransaction(uc, key, s.outputs)
+	if err != nil {
+		return types.Transaction{}, err
+	}
+	err = w.tpool.AcceptTransactionSet([]types.Transaction{txn})
+	if err != nil {
+		return types.Transaction{}, err
+	}
+	return txn, nil
+}
+
+// managedCreateSweepTransaction creates and signs a transaction spending the
+// provided outputs to a new address of the wallet.
+func (w *Wallet) managedCreateSweepTransaction(uc types.UnlockConditions, key crypto.SecretKey, outputs map[types.SiacoinOutputID]types.SiacoinOutput) (types.Transaction, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.unlocked {
+		return types.Transaction{}, modules.ErrLockedWallet
+	}
+
+	var txn types.Transaction
+	var fund types.Currency
+	for scoid, sco := range outputs {
+		txn.SiacoinInputs = append(txn.SiacoinInputs, types.SiacoinInput{
+			ParentID:         scoid,
+			UnlockConditions: uc,
+		})
+		fund = fund.Add(sco.Value)
+	}
+	txnSize := uint64(len(encoding.Marshal(txn))) + 200 // space for an output and a fee
+	txnSize += uint64(len(txn.SiacoinInputs)) * defragInputSize(uc)
+	fee := w.estimateFee(txnSize)
+	if fee.Cmp(fund) >= 0 {
+		return types.Transaction{}, errSweepNotProfitable
+	}
+
+	// Send the swept funds to a new address of the wallet.
+	dest, err := w.nextPrimarySeedAddress()
+	if err != nil {
+		return types.Transaction{}, err
+	}
+	txn.MinerFees = []types.Currency{fee}
+	txn.SiacoinOutputs = []types.SiacoinOutput{{
+		Value:      fund.Sub(fee),
+		UnlockHash: dest.UnlockHash(),
+	}}
+	sk := spendableKey{
+		UnlockConditions: uc,
+		SecretKeys:       []crypto.SecretKey{key},
+	}
+	for _, sci := range txn.SiacoinInputs {
+		_, err := addSignatures(&txn, types.FullCoveredFields, uc, crypto.Hash(sci.ParentID), sk)
+		if err != nil {
+			return types.Transaction{}, err
+		}
+	}
+	return txn, nil
+}
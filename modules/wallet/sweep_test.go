@@ -0,0 +1,86 @@
+package wallet
+
+import (
+	"testing"
+
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestIntegrationSweepAddress funds an address that does not belong to the
+// wallet and sweeps it into the wallet.
+func TestIntegrationSweepAddress(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester("TestIntegrationSweepAddress")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	// Fund an external address.
+	sk, pk, err := crypto.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	uc := generateUnlockConditions(pk)
+	amount := types.SiacoinPrecision.Mul64(100)
+	for i := 0; i < 2; i++ {
+		_, err = wt.wallet.SendSiacoins(amount, uc.UnlockHash())
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	b, _ := wt.miner.FindBlock()
+	err = wt.cs.AcceptBlock(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Sweeping with the wrong key should fail.
+	wrongSK, _, err := crypto.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = wt.wallet.SweepAddress(uc, wrongSK)
+	if err != errSweepInsufficientKeys {
+		t.Fatal("expected", errSweepInsufficientKeys, "got", err)
+	}
+
+	// Sweep the address into the wallet.
+	txn, err := wt.wallet.SweepAddress(uc, sk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(txn.SiacoinInputs) != 2 {
+		t.Fatal("expected the sweep to spend 2 outputs, got", len(txn.SiacoinInputs))
+	}
+	expected := amount.Mul64(2).Sub(txn.MinerFees[0])
+	_, incoming := wt.wallet.UnconfirmedBalance()
+	if incoming.Cmp(expected) != 0 {
+		t.Fatalf("expected %v incoming siacoins, got %v", expected, incoming)
+	}
+	balanceBefore, _, _ := wt.wallet.ConfirmedBalance()
+	b, _ = wt.miner.FindBlock()
+	err = wt.cs.AcceptBlock(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wt.wallet.mu.Lock()
+	sco, exists := wt.wallet.siacoinOutputs[txn.SiacoinOutputID(0)]
+	wt.wallet.mu.Unlock()
+	if !exists || sco.Value.Cmp(expected) != 0 {
+		t.Fatal("swept funds were not added to the wallet")
+	}
+	balanceAfter, _, _ := wt.wallet.ConfirmedBalance()
+	if balanceAfter.Cmp(balanceBefore.Add(expected)) < 0 {
+		t.Error("confirmed balance did not increase by the swept amount")
+	}
+
+	// The address is now empty.
+	_, err = wt.wallet.SweepAddress(uc, sk)
+	if err != errSweepNoOutputs {
+		t.Fatal("expected", errSweepNoOutputs, "got", err)
+	}
+}
@@ -3,7 +3,6 @@ package wallet
 import (
 	"bytes"
 	"errors"
-	"sort"
 
 	"github.com/NebulousLabs/Sia/crypto"
 	"github.com/NebulousLabs/Sia/encoding"
@@ -16,6 +15,11 @@ var (
 	// already added at least one successful signature to the transaction,
 	// meaning that future calls to Sign will result in an invalid transaction.
 	errBuilderAlreadySigned = errors.New("sign has already been called on this transaction builder, multiple calls can cause issues")
+
+	// errUnderfundedTransaction indicates that the siacoin inputs of a
+	// transaction do not cover the siacoin outputs, miner fees, and file
+	// contract payouts of the transaction.
+	errUnderfundedTransaction = errors.New("transaction inputs do not cover the outputs of the transaction")
 )
 
 // transactionBuilder allows transactions to be manually constructed, including
@@ -85,12 +89,22 @@ func addSignatures(txn *types.Transaction, cf types.CoveredFields, uc types.Unlo
 // FundSiacoins will add a siacoin input of exactly 'amount' to the
 // transaction. A parent transaction may be needed to achieve an input with the
 // correct value. The siacoin input will not be signed until 'Sign' is called
-// on the transaction builder.
+// on the transaction builder. The largest outputs of the wallet are used
+// first.
 func (tb *transactionBuilder) FundSiacoins(amount types.Currency) error {
+	return tb.FundSiacoinsWithStrategy(amount, modules.LargestFirst)
+}
+
+// FundSiacoinsWithStrategy will add a siacoin input of exactly 'amount' to the
+// transaction, using 'strategy' to select the outputs of the wallet that fund
+// the input. A parent transaction may be needed to achieve an input with the
+// correct value. The siacoin input will not be signed until 'Sign' is called
+// on the transaction builder.
+func (tb *transactionBuilder) FundSiacoinsWithStrategy(amount types.Currency, strategy modules.CoinSelectionStrategy) error {
 	tb.wallet.mu.Lock()
 	defer tb.wallet.mu.Unlock()
 
-	// Collect a value-sorted set of siacoin outputs.
+	// Collect the set of siacoin outputs.
 	var so sortedOutputs
 	for scoid, sco := range tb.wallet.siacoinOutputs {
 		so.ids = append(so.ids, scoid)
@@ -108,18 +122,15 @@ func (tb *transactionBuilder) FundSiacoins(amount types.Currency) error {
 			so.outputs = append(so.outputs, sco)
 		}
 	}
-	sort.Sort(sort.Reverse(so))
 
-	// Create and fund a parent transaction that will add the correct amount of
-	// siacoins to the transaction.
-	var fund types.Currency
+	// Filter out the outputs that cannot be spent right now.
+	//
 	// potentialFund tracks the balance of the wallet including outputs that
 	// have been spent in other unconfirmed transactions recently. This is to
 	// provide the user with a more useful error message in the event that they
 	// are overspending.
 	var potentialFund types.Currency
-	parentTxn := types.Transaction{}
-	var spentScoids []types.SiacoinOutputID
+	var spendable sortedOutputs
 	for i := range so.ids {
 		scoid := so.ids[i]
 		sco := so.outputs[i]
@@ -138,21 +149,36 @@ func (tb *transactionBuilder) FundSiacoins(amount types.Currency) error {
 		if tb.wallet.consensusSetHeight < outputUnlockConditions.Timelock {
 			continue
 		}
+		spendable.ids = append(spendable.ids, scoid)
+		spendable.outputs = append(spendable.outputs, sco)
+		potentialFund = potentialFund.Add(sco.Value)
+	}
+
+	// Select the outputs that will fund the transaction.
+	selected, err := selectSiacoinOutputs(spendable, amount, strategy)
+	if err != nil {
+		return err
+	}
+
+	// Create and fund a parent transaction that will add the correct amount of
+	// siacoins to the transaction.
+	var fund types.Currency
+	parentTxn := types.Transaction{}
+	var spentScoids []types.SiacoinOutputID
+	for _, i := range selected {
+		scoid := spendable.ids[i]
+		sco := spendable.outputs[i]
 
 		// Add a siacoin input for this output.
 		sci := types.SiacoinInput{
 			ParentID:         scoid,
-			UnlockConditions: outputUnlockConditions,
+			UnlockConditions: tb.wallet.keys[sco.UnlockHash].UnlockConditions,
 		}
 		parentTxn.SiacoinInputs = append(parentTxn.SiacoinInputs, sci)
 		spentScoids = append(spentScoids, scoid)
 
 		// Add the output to the total fund
 		fund = fund.Add(sco.Value)
-		potentialFund = potentialFund.Add(sco.Value)
-		if fund.Cmp(amount) >= 0 {
-			break
-		}
 	}
 	if potentialFund.Cmp(amount) >= 0 && fund.Cmp(amount) < 0 {
 		return modules.ErrIncompleteTransactions
@@ -173,17 +199,27 @@ func (tb *transactionBuilder) FundSiacoins(amount types.Currency) error {
 	}
 	parentTxn.SiacoinOutputs = append(parentTxn.SiacoinOutputs, exactOutput)
 
-	// Create a refund output if needed.
+	// Create a refund output if needed. A refund below the dust threshold
+	// would not be accepted by the transaction pool, so it is added to the
+	// miner fees instead.
 	if amount.Cmp(fund) != 0 {
-		refundUnlockConditions, err := tb.wallet.nextPrimarySeedAddress()
+		refundValue, err := fund.SubChecked(amount)
 		if err != nil {
 			return err
 		}
-		refundOutput := types.SiacoinOutput{
-			Value:      fund.Sub(amount),
-			UnlockHash: refundUnlockConditions.UnlockHash(),
+		if refundValue.Cmp(tb.wallet.dustThreshold) < 0 {
+			parentTxn.MinerFees = append(parentTxn.MinerFees, refundValue)
+		} else {
+			refundUnlockConditions, err := tb.wallet.nextPrimarySeedAddress()
+			if err != nil {
+				return err
+			}
+			refundOutput := types.SiacoinOutput{
+				Value:      refundValue,
+				UnlockHash: refundUnlockConditions.UnlockHash(),
+			}
+			parentTxn.SiacoinOutputs = append(parentTxn.SiacoinOutputs, refundOutput)
 		}
-		parentTxn.SiacoinOutputs = append(parentTxn.SiacoinOutputs, refundOutput)
 	}
 
 	// Sign all of the inputs to the parent trancstion.
@@ -286,12 +322,16 @@ func (tb *transactionBuilder) FundSiafunds(amount types.Currency) error {
 
 	// Create a refund output if needed.
 	if amount.Cmp(fund) != 0 {
+		refundValue, err := fund.SubChecked(amount)
+		if err != nil {
+			return err
+		}
 		refundUnlockConditions, err := tb.wallet.nextPrimarySeedAddress()
 		if err != nil {
 			return err
 		}
 		refundOutput := types.SiafundOutput{
-			Value:      fund.Sub(amount),
+			Value:      refundValue,
 			UnlockHash: refundUnlockConditions.UnlockHash(),
 		}
 		parentTxn.SiafundOutputs = append(parentTxn.SiafundOutputs, refundOutput)
@@ -354,6 +394,57 @@ func (tb *transactionBuilder) AddSiacoinOutput(output types.SiacoinOutput) uint6
 	return uint64(len(tb.transaction.SiacoinOutputs) - 1)
 }
 
+// AddSiacoinOutputs adds a batch of siacoin outputs to the transaction,
+// returning the indices of the siacoin outputs within the transaction. If the
+// values of all siacoin inputs of the transaction are known to the builder,
+// the inputs must cover the siacoin outputs, miner fees, and file contract
+// payouts of the transaction after the outputs are added. Otherwise, no
+// outputs are added and an error is returned.
+func (tb *transactionBuilder) AddSiacoinOutputs(outputs []types.SiacoinOutput) ([]uint64, error) {
+	tb.wallet.mu.RLock()
+	inputSum, known := tb.siacoinInputSum()
+	tb.wallet.mu.RUnlock()
+	if known {
+		outputSum := tb.transaction.SiacoinOutputSum()
+		for _, sco := range outputs {
+			outputSum = outputSum.Add(sco.Value)
+		}
+		if inputSum.Cmp(outputSum) < 0 {
+			return nil, errUnderfundedTransaction
+		}
+	}
+
+	indices := make([]uint64, 0, len(outputs))
+	for _, sco := range outputs {
+		indices = append(indices, tb.AddSiacoinOutput(sco))
+	}
+	return indices, nil
+}
+
+// siacoinInputSum returns the total value of the siacoin inputs of the
+// transaction. The value of an input is determined using the parents of the
+// transaction and the outputs of the wallet. If the value of any input cannot
+// be determined, false is returned.
+func (tb *transactionBuilder) siacoinInputSum() (sum types.Currency, known bool) {
+	parentOutputs := make(map[types.SiacoinOutputID]types.SiacoinOutput)
+	for _, parent := range tb.parents {
+		for i, sco := range parent.SiacoinOutputs {
+			parentOutputs[parent.SiacoinOutputID(uint64(i))] = sco
+		}
+	}
+	for _, sci := range tb.transaction.SiacoinInputs {
+		sco, exists := parentOutputs[sci.ParentID]
+		if !exists {
+			sco, exists = tb.wallet.siacoinOutputs[sci.ParentID]
+		}
+		if !exists {
+			return types.ZeroCurrency, false
+		}
+		sum = sum.Add(sco.Value)
+	}
+	return sum, true
+}
+
 // AddFileContract adds a file contract to the transaction, returning the index
 // of the file contract within the transaction.
 func (tb *transactionBuilder) AddFileContract(fc types.FileContract) uint64 {
@@ -445,7 +536,8 @@ func (tb *transactionBuilder) Drop() {
 // more fields to be added.
 //
 // Sign should not be called more than once. If, for some reason, there is an
-// error while calling Sign, the builder should be dropped.
+// error while calling Sign, the builder should be dropped. Sign returns
+// modules.ErrLockedWallet if the wallet is locked.
 func (tb *transactionBuilder) Sign(wholeTransaction bool) ([]types.Transaction, error) {
 	if tb.signed {
 		return nil, errBuilderAlreadySigned
@@ -491,9 +583,13 @@ func (tb *transactionBuilder) Sign(wholeTransaction bool) ([]types.Transaction,
 	}
 
 	// For each siacoin input in the transaction that we added, provide a
-	// signature.
+	// signature. The secret keys are wiped while the wallet is locked, so
+	// signing is not possible.
 	tb.wallet.mu.Lock()
 	defer tb.wallet.mu.Unlock()
+	if !tb.wallet.unlocked {
+		return nil, modules.ErrLockedWallet
+	}
 	for _, inputIndex := range tb.siacoinInputs {
 		input := tb.transaction.SiacoinInputs[inputIndex]
 		key := tb.wallet.keys[input.UnlockConditions.UnlockHash()]
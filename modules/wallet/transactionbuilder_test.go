@@ -449,3 +449,122 @@ func TestParallelBuilders(t *testing.T) {
 		t.Fatal("did not get the expected ending balance", expected, endingSCConfirmed, startingSCConfirmed)
 	}
 }
+
+// TestAddSiacoinOutputs checks that a batch of outputs can be added to a
+// transaction, and that underfunded batches are rejected.
+func TestAddSiacoinOutputs(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester("TestAddSiacoinOutputs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	// Fund a transaction with 100 SC.
+	b := wt.wallet.StartTransaction()
+	funding := types.SiacoinPrecision.Mul64(100)
+	err = b.FundSiacoins(funding)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Add a batch of outputs that exceeds the funding.
+	var outputs []types.SiacoinOutput
+	for i := 0; i < 11; i++ {
+		outputs = append(outputs, types.SiacoinOutput{
+			Value:      types.SiacoinPrecision.Mul64(10),
+			UnlockHash: types.UnlockHash{byte(i)},
+		})
+	}
+	_, err = b.AddSiacoinOutputs(outputs)
+	if err != errUnderfundedTransaction {
+		t.Fatal("expecting errUnderfundedTransaction, got", err)
+	}
+	txn, _ := b.View()
+	if len(txn.SiacoinOutputs) != 0 {
+		t.Fatal("outputs were added despite the transaction being underfunded")
+	}
+
+	// Add a batch of outputs that exactly matches the funding.
+	indices, err := b.AddSiacoinOutputs(outputs[:10])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, index := range indices {
+		if index != uint64(i) {
+			t.Error("wrong index returned for output", i, index)
+		}
+	}
+
+	// Sign the transaction and submit it to the transaction pool.
+	txnSet, err := b.Sign(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = wt.tpool.AcceptTransactionSet(txnSet)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestFundSiacoinsDustRefund checks that a refund below the dust threshold is
+// paid as a miner fee instead of creating a change output, and that the
+// resulting transaction set is accepted.
+func TestFundSiacoinsDustRefund(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester("TestFundSiacoinsDustRefund")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	// Use a threshold larger than any output of the wallet, so that the
+	// whole refund is dust.
+	wt.wallet.mu.Lock()
+	wt.wallet.dustThreshold = types.SiacoinPrecision.Mul64(1e9)
+	wt.wallet.mu.Unlock()
+
+	amount := types.SiacoinPrecision.Mul64(10)
+	b := wt.wallet.StartTransaction()
+	err = b.FundSiacoins(amount)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.AddSiacoinOutput(types.SiacoinOutput{Value: amount})
+	txnSet, err := b.Sign(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The parent transaction should only create the output funding the
+	// transaction, and pay the rest of its inputs as miner fees.
+	parent := txnSet[0]
+	if len(parent.SiacoinOutputs) != 1 || parent.SiacoinOutputs[0].Value.Cmp(amount) != 0 {
+		t.Fatal("parent transaction has unexpected siacoin outputs:", parent.SiacoinOutputs)
+	}
+	var inputSum types.Currency
+	wt.wallet.mu.RLock()
+	for _, sci := range parent.SiacoinInputs {
+		inputSum = inputSum.Add(wt.wallet.siacoinOutputs[sci.ParentID].Value)
+	}
+	wt.wallet.mu.RUnlock()
+	if len(parent.MinerFees) != 1 || parent.MinerFees[0].Cmp(inputSum.Sub(amount)) != 0 {
+		t.Fatal("dust refund was not paid as a miner fee:", parent.MinerFees)
+	}
+
+	err = wt.tpool.AcceptTransactionSet(txnSet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = wt.miner.AddBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(wt.tpool.TransactionList()) != 0 {
+		t.Fatal("transaction set was not mined")
+	}
+}
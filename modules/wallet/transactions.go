@@ -8,8 +8,9 @@ import (
 )
 
 var (
-	errOutOfBounds      = errors.New("requesting transactions at unknown confirmation heights")
-	errNoHistoryForAddr = errors.New("no history found for provided address")
+	errOutOfBounds        = errors.New("requesting transactions at unknown confirmation heights")
+	errNoHistoryForAddr   = errors.New("no history found for provided address")
+	errUnknownTransaction = errors.New("transaction is not known to the wallet")
 )
 
 // AddressTransactions returns all of the wallet transactions associated with a
@@ -33,7 +34,7 @@ func (w *Wallet) AddressTransactions(uh types.UnlockHash) (pts []modules.Process
 			}
 		}
 		if relevant {
-			pts = append(pts, pt)
+			pts = append(pts, w.labelTransaction(pt))
 		}
 	}
 	return pts
@@ -62,7 +63,7 @@ func (w *Wallet) AddressUnconfirmedTransactions(uh types.UnlockHash) (pts []modu
 			}
 		}
 		if relevant {
-			pts = append(pts, pt)
+			pts = append(pts, w.labelTransaction(pt))
 		}
 	}
 	return pts
@@ -77,7 +78,29 @@ func (w *Wallet) Transaction(txid types.TransactionID) (modules.ProcessedTransac
 	if !exists {
 		return modules.ProcessedTransaction{}, exists
 	}
-	return *pt, exists
+	return w.labelTransaction(*pt), exists
+}
+
+// TransactionStatus returns the number of confirmations that a transaction
+// has, and whether the transaction has been confirmed at all. Confirmations
+// are counted as the current height minus the height the transaction was
+// confirmed at, and are 0 for transactions that are still unconfirmed. If a
+// block containing the transaction is reverted, the transaction reverts to
+// unconfirmed.
+func (w *Wallet) TransactionStatus(txid types.TransactionID) (confirmations types.BlockHeight, confirmed bool, err error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	pt, exists := w.processedTransactionMap[txid]
+	if exists {
+		return w.consensusSetHeight - pt.ConfirmationHeight, true, nil
+	}
+	for _, upt := range w.unconfirmedProcessedTransactions {
+		if upt.TransactionID == txid {
+			return 0, false, nil
+		}
+	}
+	return 0, false, errUnknownTransaction
 }
 
 // Transactions returns all transactions relevant to the wallet that were
@@ -98,7 +121,7 @@ func (w *Wallet) Transactions(startHeight, endHeight types.BlockHeight) (pts []m
 			break
 		}
 		if pt.ConfirmationHeight >= startHeight {
-			pts = append(pts, pt)
+			pts = append(pts, w.labelTransaction(pt))
 		}
 	}
 	return pts, nil
@@ -109,5 +132,12 @@ func (w *Wallet) Transactions(startHeight, endHeight types.BlockHeight) (pts []m
 func (w *Wallet) UnconfirmedTransactions() []modules.ProcessedTransaction {
 	w.mu.Lock()
 	defer w.mu.Unlock()
-	return w.unconfirmedProcessedTransactions
+	if len(w.unconfirmedProcessedTransactions) == 0 {
+		return w.unconfirmedProcessedTransactions
+	}
+	pts := make([]modules.ProcessedTransaction, 0, len(w.unconfirmedProcessedTransactions))
+	for _, pt := range w.unconfirmedProcessedTransactions {
+		pts = append(pts, w.labelTransaction(pt))
+	}
+	return pts
 }
@@ -2,7 +2,9 @@ package wallet
 
 import (
 	"testing"
+	"time"
 
+	"github.com/NebulousLabs/Sia/modules"
 	"github.com/NebulousLabs/Sia/types"
 )
 
@@ -131,3 +133,97 @@ func TestIntegrationAddressTransactions(t *testing.T) {
 		t.Error("addresses unconfirmed transactions should be empty")
 	}
 }
+
+// TestIntegrationTransactionStatus checks that the confirmation status of a
+// transaction is tracked, and that the transaction reverts to unconfirmed
+// when the block containing it is reverted.
+func TestIntegrationTransactionStatus(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester("TestIntegrationTransactionStatus")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	// Unknown transactions should return an error.
+	_, _, err = wt.wallet.TransactionStatus(types.TransactionID{})
+	if err != errUnknownTransaction {
+		t.Fatal("expecting errUnknownTransaction, got", err)
+	}
+
+	// Send a transaction, it should be unconfirmed.
+	forkPoint := wt.cs.CurrentBlock()
+	height := wt.cs.Height()
+	txns, err := wt.wallet.SendSiacoins(types.NewCurrency64(5000), types.UnlockHash{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	txid := txns[len(txns)-1].ID()
+	confirmations, confirmed, err := wt.wallet.TransactionStatus(txid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if confirmed || confirmations != 0 {
+		t.Fatal("transaction should be unconfirmed:", confirmed, confirmations)
+	}
+
+	// Mine two blocks, the transaction should be confirmed.
+	for i := 0; i < 2; i++ {
+		_, err = wt.miner.AddBlock()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	confirmations, confirmed, err = wt.wallet.TransactionStatus(txid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !confirmed || confirmations != 1 {
+		t.Fatal("transaction should have 1 confirmation:", confirmed, confirmations)
+	}
+
+	// Build a heavier chain from the fork point that does not contain the
+	// transaction, reverting the block that confirmed it.
+	parent := forkPoint
+	for i := 0; i < 3; i++ {
+		height++
+		target, _ := wt.cs.ChildTarget(parent.ID())
+		b := types.Block{
+			ParentID:  parent.ID(),
+			Timestamp: types.CurrentTimestamp(),
+			MinerPayouts: []types.SiacoinOutput{{
+				Value: types.CalculateCoinbase(height),
+			}},
+		}
+		solved := false
+		for !solved {
+			b, solved = wt.miner.SolveBlock(b, target)
+		}
+		err = wt.cs.AcceptBlock(b)
+		if err != nil && err != modules.ErrNonExtendingBlock {
+			t.Fatal(err)
+		}
+		parent = b
+	}
+	if wt.cs.CurrentBlock().ID() != parent.ID() {
+		t.Fatal("fork did not become the current chain")
+	}
+
+	// The transaction should be resubmitted to the transaction pool and be
+	// unconfirmed again. Resubmission happens in a goroutine.
+	for i := 0; i < 50; i++ {
+		confirmations, confirmed, err = wt.wallet.TransactionStatus(txid)
+		if err == nil {
+			break
+		}
+		time.Sleep(time.Millisecond * 100)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	if confirmed || confirmations != 0 {
+		t.Fatal("transaction should be unconfirmed after the reorg:", confirmed, confirmations)
+	}
+}
@@ -51,6 +51,23 @@ func (w *Wallet) updateConfirmedSet(cc modules.ConsensusChange) {
 			delete(w.siafundOutputs, diff.ID)
 		}
 	}
+	for _, diff := range cc.DelayedSiacoinOutputDiffs {
+		// Verify that the diff is relevant to the wallet.
+		_, exists := w.keys[diff.SiacoinOutput.UnlockHash]
+		if !exists {
+			continue
+		}
+
+		// Delayed outputs are removed when they mature, at which point they
+		// appear in the SiacoinOutputDiffs. The output may not be tracked if
+		// the wallet was created after the output was added, so a missing
+		// output is not an error.
+		if diff.Direction == modules.DiffApply {
+			w.delayedSiacoinOutputs[diff.ID] = diff.SiacoinOutput
+		} else {
+			delete(w.delayedSiacoinOutputs, diff.ID)
+		}
+	}
 	for _, diff := range cc.SiafundPoolDiffs {
 		if diff.Direction == modules.DiffApply {
 			w.siafundPool = diff.Adjusted
@@ -61,8 +78,10 @@ func (w *Wallet) updateConfirmedSet(cc modules.ConsensusChange) {
 }
 
 // revertHistory reverts any transaction history that was destroyed by reverted
-// blocks in the consensus change.
-func (w *Wallet) revertHistory(cc modules.ConsensusChange) {
+// blocks in the consensus change. The reverted transactions that were relevant
+// to the wallet are returned in the order that they originally appeared in
+// the blockchain.
+func (w *Wallet) revertHistory(cc modules.ConsensusChange) (reverted []types.Transaction) {
 	for _, block := range cc.RevertedBlocks {
 		// Remove any transactions that have been reverted.
 		for i := len(block.Transactions) - 1; i >= 0; i-- {
@@ -75,6 +94,7 @@ func (w *Wallet) revertHistory(cc modules.ConsensusChange) {
 			if len(w.processedTransactions) > 0 && txid == w.processedTransactions[len(w.processedTransactions)-1].TransactionID {
 				w.processedTransactions = w.processedTransactions[:len(w.processedTransactions)-1]
 				delete(w.processedTransactionMap, txid)
+				reverted = append(reverted, txn)
 			}
 		}
 
@@ -89,6 +109,31 @@ func (w *Wallet) revertHistory(cc modules.ConsensusChange) {
 		}
 		w.consensusSetHeight--
 	}
+
+	// Blocks and transactions were reverted newest first, reverse the list so
+	// that parents come before their children.
+	for i, j := 0, len(reverted)-1; i < j; i, j = i+1, j-1 {
+		reverted[i], reverted[j] = reverted[j], reverted[i]
+	}
+	return reverted
+}
+
+// threadedResubmitTransactions submits transactions that were reverted from
+// the blockchain back to the transaction pool, so that they return to being
+// unconfirmed instead of disappearing from the wallet. Transactions that are
+// no longer valid are rejected by the transaction pool and are dropped.
+func (w *Wallet) threadedResubmitTransactions(txns []types.Transaction) {
+	if err := w.tg.Add(); err != nil {
+		return
+	}
+	defer w.tg.Done()
+
+	// The transactions are submitted one at a time so that a single invalid
+	// transaction does not prevent the rest from being resubmitted. The
+	// transaction pool will merge children with their parents.
+	for _, txn := range txns {
+		w.tpool.AcceptTransactionSet([]types.Transaction{txn}) // Error is not checked.
+	}
 }
 
 // applyHistory applies any transaction history that was introduced by the
@@ -219,8 +264,18 @@ func (w *Wallet) ProcessConsensusChange(cc modules.ConsensusChange) {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 	w.updateConfirmedSet(cc)
-	w.revertHistory(cc)
+	w.updateWatchedSet(cc, w.watchedAddresses)
+	w.updateFileContracts(cc)
+	reverted := w.revertHistory(cc)
 	w.applyHistory(cc)
+	w.updateFeeHistory(cc)
+
+	// Transactions in reverted blocks are no longer confirmed. Resubmit them
+	// to the transaction pool in a goroutine, as the consensus set is locked
+	// while the consensus change is being processed.
+	if len(reverted) > 0 {
+		go w.threadedResubmitTransactions(reverted)
+	}
 }
 
 // ReceiveUpdatedUnconfirmedTransactions updates the wallet's unconfirmed
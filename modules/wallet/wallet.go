@@ -77,6 +77,10 @@ type Wallet struct {
 	siafundOutputs map[types.SiafundOutputID]types.SiafundOutput
 	spentOutputs   map[types.OutputID]types.BlockHeight
 
+	// delayedSiacoinOutputs are the delayed outputs, such as miner payouts,
+	// that pay out to the wallet but have not yet reached maturity.
+	delayedSiacoinOutputs map[types.SiacoinOutputID]types.SiacoinOutput
+
 	// The following fields are kept to track transaction history.
 	// processedTransactions are stored in chronological order, and have a map for
 	// constant time random access. The set of full transactions is kept as
@@ -98,6 +102,31 @@ type Wallet struct {
 	historicOutputs     map[types.OutputID]types.Currency
 	historicClaimStarts map[types.SiafundOutputID]types.Currency
 
+	// watchedAddresses are addresses that the wallet tracks without being
+	// able to spend from them. The outputs sent to watched addresses are kept
+	// separate from the spendable outputs of the wallet, so that they are
+	// never used to fund transactions.
+	watchedAddresses      map[types.UnlockHash]struct{}
+	watchedSiacoinOutputs map[types.SiacoinOutputID]types.SiacoinOutput
+	watchedSiafundOutputs map[types.SiafundOutputID]types.SiafundOutput
+
+	// addressLabels are the labels that the user has attached to addresses,
+	// mirroring the labels in the persist object.
+	addressLabels map[types.UnlockHash]string
+
+	// fileContracts are the open file contracts that pay out to the wallet,
+	// and are used to renew contracts.
+	fileContracts map[types.FileContractID]types.FileContract
+
+	// recentBlockFees tracks the miner fees and sizes of the most recent
+	// blocks, and is used to estimate fees for new transactions.
+	recentBlockFees []blockFeeStats
+
+	// dustThreshold is the smallest value of a change output created by the
+	// wallet. Smaller change is paid as a miner fee instead. It is
+	// modules.DustThreshold outside of tests.
+	dustThreshold types.Currency
+
 	persistDir string
 	log        *persist.Logger
 	mu         sync.RWMutex
@@ -129,11 +158,23 @@ func New(cs modules.ConsensusSet, tpool modules.TransactionPool, persistDir stri
 		siafundOutputs: make(map[types.SiafundOutputID]types.SiafundOutput),
 		spentOutputs:   make(map[types.OutputID]types.BlockHeight),
 
+		delayedSiacoinOutputs: make(map[types.SiacoinOutputID]types.SiacoinOutput),
+
 		processedTransactionMap: make(map[types.TransactionID]*modules.ProcessedTransaction),
 
 		historicOutputs:     make(map[types.OutputID]types.Currency),
 		historicClaimStarts: make(map[types.SiafundOutputID]types.Currency),
 
+		watchedAddresses:      make(map[types.UnlockHash]struct{}),
+		watchedSiacoinOutputs: make(map[types.SiacoinOutputID]types.SiacoinOutput),
+		watchedSiafundOutputs: make(map[types.SiafundOutputID]types.SiafundOutput),
+
+		addressLabels: make(map[types.UnlockHash]string),
+
+		fileContracts: make(map[types.FileContractID]types.FileContract),
+
+		dustThreshold: modules.DustThreshold,
+
 		persistDir: persistDir,
 	}
 	err := w.initPersist()
@@ -130,6 +130,29 @@ func createBlankWalletTester(name string) (*walletTester, error) {
 	return wt, nil
 }
 
+// createSecondWallet creates a second, empty wallet on the consensus set of the
+// wallet tester, returning the wallet and an address belonging to it.
+func (wt *walletTester) createSecondWallet(name string) (*Wallet, types.UnlockConditions, error) {
+	dir := filepath.Join(build.TempDir(modules.WalletDir, name+" - second"), modules.WalletDir)
+	w, err := New(wt.cs, wt.tpool, dir)
+	if err != nil {
+		return nil, types.UnlockConditions{}, err
+	}
+	seed, err := w.Encrypt(crypto.TwofishKey{})
+	if err != nil {
+		return nil, types.UnlockConditions{}, err
+	}
+	err = w.Unlock(crypto.TwofishKey(crypto.HashObject(seed)))
+	if err != nil {
+		return nil, types.UnlockConditions{}, err
+	}
+	uc, err := w.NextAddress()
+	if err != nil {
+		return nil, types.UnlockConditions{}, err
+	}
+	return w, uc, nil
+}
+
 // closeWt closes all of the modules in the wallet tester.
 func (wt *walletTester) closeWt() {
 	errs := []error{
@@ -0,0 +1,127 @@
+package wallet
+
+import (
+	"errors"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+var (
+	errAlreadyWatching = errors.New("the wallet is already watching that address")
+	errSpendableWatch  = errors.New("the wallet can already spend from that address")
+)
+
+// A watchScanner scans the blockchain for the outputs of an address that is
+// being added to the set of watched addresses, recording them in the wallet.
+type watchScanner struct {
+	addrs map[types.UnlockHash]struct{}
+	w     *Wallet
+}
+
+// ProcessConsensusChange records the outputs of the scanned address that are
+// created or destroyed by the consensus change.
+func (s *watchScanner) ProcessConsensusChange(cc modules.ConsensusChange) {
+	s.w.mu.Lock()
+	s.w.updateWatchedSet(cc, s.addrs)
+	s.w.mu.Unlock()
+}
+
+// updateWatchedSet uses a consensus change to update the set of outputs sent
+// to the provided watched addresses.
+func (w *Wallet) updateWatchedSet(cc modules.ConsensusChange, addrs map[types.UnlockHash]struct{}) {
+	for _, diff := range cc.SiacoinOutputDiffs {
+		if _, exists := addrs[diff.SiacoinOutput.UnlockHash]; !exists {
+			continue
+		}
+		if diff.Direction == modules.DiffApply {
+			w.watchedSiacoinOutputs[diff.ID] = diff.SiacoinOutput
+		} else {
+			delete(w.watchedSiacoinOutputs, diff.ID)
+		}
+	}
+	for _, diff := range cc.SiafundOutputDiffs {
+		if _, exists := addrs[diff.SiafundOutput.UnlockHash]; !exists {
+			continue
+		}
+		if diff.Direction == modules.DiffApply {
+			w.watchedSiafundOutputs[diff.ID] = diff.SiafundOutput
+		} else {
+			delete(w.watchedSiafundOutputs, diff.ID)
+		}
+	}
+}
+
+// AddWatchAddress adds an address to the set of addresses watched by the
+// wallet. The wallet tracks the outputs sent to watched addresses, but will
+// never use them to fund transactions. The blockchain is scanned for the
+// outputs that the address already holds.
+func (w *Wallet) AddWatchAddress(uh types.UnlockHash) error {
+	if err := w.tg.Add(); err != nil {
+		return err
+	}
+	defer w.tg.Done()
+	checkAddress := func() error {
+		if _, exists := w.watchedAddresses[uh]; exists {
+			return errAlreadyWatching
+		}
+		if _, exists := w.keys[uh]; exists {
+			return errSpendableWatch
+		}
+		return nil
+	}
+	w.mu.RLock()
+	err := checkAddress()
+	w.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	// Scan the blockchain for the existing outputs of the address. The scanner
+	// stays subscribed until the address has been added to the watched
+	// addresses, so that no consensus change is missed in between. Changes
+	// received by both the scanner and the wallet are applied twice, which
+	// has no effect.
+	s := &watchScanner{
+		addrs: map[types.UnlockHash]struct{}{uh: {}},
+		w:     w,
+	}
+	err = w.cs.ConsensusSetSubscribe(s, modules.ConsensusChangeBeginning)
+	if err != nil {
+		return err
+	}
+	defer w.cs.Unsubscribe(s)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := checkAddress(); err != nil {
+		return err
+	}
+	w.watchedAddresses[uh] = struct{}{}
+	w.persist.WatchedAddresses = append(w.persist.WatchedAddresses, uh)
+	return w.saveSettingsSync()
+}
+
+// WatchedAddresses returns the addresses watched by the wallet.
+func (w *Wallet) WatchedAddresses() []types.UnlockHash {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	addrs := make([]types.UnlockHash, len(w.persist.WatchedAddresses))
+	copy(addrs, w.persist.WatchedAddresses)
+	return addrs
+}
+
+// WatchedBalance returns the confirmed balance of the addresses watched by the
+// wallet. The balance is not included in the balance returned by
+// ConfirmedBalance, and cannot be spent by the wallet.
+func (w *Wallet) WatchedBalance() (siacoinBalance types.Currency, siafundBalance types.Currency) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	for _, sco := range w.watchedSiacoinOutputs {
+		siacoinBalance = siacoinBalance.Add(sco.Value)
+	}
+	for _, sfo := range w.watchedSiafundOutputs {
+		siafundBalance = siafundBalance.Add(sfo.Value)
+	}
+	return siacoinBalance, siafundBalance
+}
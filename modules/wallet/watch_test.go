@@ -0,0 +1,131 @@
+package wallet
+
+import (
+	"testing"
+
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestWatchAddress checks that the wallet tracks outputs sent to watched
+// addresses without considering them spendable.
+func TestWatchAddress(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester("TestWatchAddress")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	// Watch an address that the wallet does not control.
+	watched := types.UnlockHash{1, 2, 3}
+	err = wt.wallet.AddWatchAddress(watched)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = wt.wallet.AddWatchAddress(watched); err != errAlreadyWatching {
+		t.Error("expecting errAlreadyWatching, got", err)
+	}
+	uc, err := wt.wallet.NextAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = wt.wallet.AddWatchAddress(uc.UnlockHash()); err != errSpendableWatch {
+		t.Error("expecting errSpendableWatch, got", err)
+	}
+	if addrs := wt.wallet.WatchedAddresses(); len(addrs) != 1 || addrs[0] != watched {
+		t.Error("watched addresses are incorrect:", addrs)
+	}
+
+	// Send coins to the watched address.
+	amount := types.SiacoinPrecision.Mul64(100)
+	_, err = wt.wallet.SendSiacoins(amount, watched)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = wt.miner.AddBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	watchedBal, _ := wt.wallet.WatchedBalance()
+	if watchedBal.Cmp(amount) != 0 {
+		t.Error("watched balance is incorrect:", watchedBal)
+	}
+
+	// The watched outputs should not be spendable.
+	wt.wallet.mu.RLock()
+	for _, sco := range wt.wallet.siacoinOutputs {
+		if sco.UnlockHash == watched {
+			t.Error("output of watched address is considered spendable")
+		}
+	}
+	wt.wallet.mu.RUnlock()
+
+	// The watched addresses should persist.
+	w2, err := New(wt.cs, wt.tpool, wt.wallet.persistDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if addrs := w2.WatchedAddresses(); len(addrs) != 1 || addrs[0] != watched {
+		t.Error("watched addresses were not persisted:", addrs)
+	}
+}
+
+// TestWatchFundedAddress checks that watching an address that already holds
+// outputs reports the existing balance of the address.
+func TestWatchFundedAddress(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester("TestWatchFundedAddress")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	// Fund an address that the wallet does not control.
+	watched := types.UnlockHash{4, 5, 6}
+	amount := types.SiacoinPrecision.Mul64(100)
+	_, err = wt.wallet.SendSiacoins(amount, watched)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = wt.miner.AddBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = wt.wallet.AddWatchAddress(watched)
+	if err != nil {
+		t.Fatal(err)
+	}
+	watchedBal, _ := wt.wallet.WatchedBalance()
+	if watchedBal.Cmp(amount) != 0 {
+		t.Fatal("watched balance does not include the existing outputs:", watchedBal)
+	}
+
+	// Outputs created after the address was added are tracked as well.
+	_, err = wt.wallet.SendSiacoins(amount, watched)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = wt.miner.AddBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	watchedBal, _ = wt.wallet.WatchedBalance()
+	if watchedBal.Cmp(amount.Mul64(2)) != 0 {
+		t.Fatal("watched balance is incorrect:", watchedBal)
+	}
+
+	// A wallet that rescans from the beginning reports the same balance.
+	err = wt.wallet.Rescan()
+	if err != nil {
+		t.Fatal(err)
+	}
+	watchedBal, _ = wt.wallet.WatchedBalance()
+	if watchedBal.Cmp(amount.Mul64(2)) != 0 {
+		t.Fatal("watched balance changed after a rescan:", watchedBal)
+	}
+}
@@ -80,58 +80,16 @@ func parsePeriod(period string) (string, error) {
 // units. The unit used will be the largest unit that results in a value
 // greater than 1. The value is rounded to 4 significant digits.
 func currencyUnits(c types.Currency) string {
-	pico := types.SiacoinPrecision.Div64(1e12)
-	if c.Cmp(pico) < 0 {
-		return c.String() + " H"
-	}
-
-	// iterate until we find a unit greater than c
-	mag := pico
-	unit := ""
-	for _, unit = range []string{"pS", "nS", "uS", "mS", "SC", "KS", "MS", "GS", "TS"} {
-		if c.Cmp(mag.Mul64(1e3)) < 0 {
-			break
-		} else if unit != "TS" {
-			// don't want to perform this multiply on the last iter; that
-			// would give us 1.235 TS instead of 1235 TS
-			mag = mag.Mul64(1e3)
-		}
-	}
-
-	num := new(big.Rat).SetInt(c.Big())
-	denom := new(big.Rat).SetInt(mag.Big())
-	res, _ := new(big.Rat).Mul(num, denom.Inv(denom)).Float64()
-
-	return fmt.Sprintf("%.4g %s", res, unit)
+	return c.HumanString()
 }
 
 // parseCurrency converts a siacoin amount to base units.
 func parseCurrency(amount string) (string, error) {
-	units := []string{"pS", "nS", "uS", "mS", "SC", "KS", "MS", "GS", "TS"}
-	for i, unit := range units {
-		if strings.HasSuffix(amount, unit) {
-			// scan into big.Rat
-			r, ok := new(big.Rat).SetString(strings.TrimSuffix(amount, unit))
-			if !ok {
-				return "", errors.New("malformed amount")
-			}
-			// convert units
-			exp := 24 + 3*(int64(i)-4)
-			mag := new(big.Int).Exp(big.NewInt(10), big.NewInt(exp), nil)
-			r.Mul(r, new(big.Rat).SetInt(mag))
-			// r must be an integer at this point
-			if !r.IsInt() {
-				return "", errors.New("non-integer number of hastings")
-			}
-			return r.RatString(), nil
-		}
-	}
-	// check for hastings separately
-	if strings.HasSuffix(amount, "H") {
-		return strings.TrimSuffix(amount, "H"), nil
+	c, err := types.ParseCurrency(amount)
+	if err != nil {
+		return "", errors.New(err.Error() + "; run 'wallet --help' for a list of units")
 	}
-
-	return "", errors.New("amount is missing units; run 'wallet --help' for a list of units")
+	return c.String(), nil
 }
 
 // yesNo returns "Yes" if b is true, and "No" if b is false.
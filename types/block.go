@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math/big"
 
 	"github.com/NebulousLabs/Sia/crypto"
 	"github.com/NebulousLabs/Sia/encoding"
@@ -84,13 +85,17 @@ func (h BlockHeader) ID() BlockID {
 // CalculateSubsidy takes a block and a height and determines the block
 // subsidy.
 func (b Block) CalculateSubsidy(height BlockHeight) Currency {
-	subsidy := CalculateCoinbase(height)
+	return CalculateCoinbase(height).Add(b.CalculateMinerFees())
+}
+
+// CalculateMinerFees returns the sum of the miner fees of every transaction in
+// the block.
+func (b Block) CalculateMinerFees() Currency {
+	fees := ZeroCurrency
 	for _, txn := range b.Transactions {
-		for _, fee := range txn.MinerFees {
-			subsidy = subsidy.Add(fee)
-		}
+		fees = fees.Add(txn.TotalMinerFees())
 	}
-	return subsidy
+	return fees
 }
 
 // Header returns the header of a block.
@@ -134,24 +139,49 @@ func (b Block) MinerPayoutID(i uint64) SiacoinOutputID {
 	))
 }
 
+// Work returns the amount of work represented by the block's ID, which is the
+// difficulty of a target equal to the ID. A lower ID represents more work.
+// Unlike the target of the block, the work varies with every nonce, which
+// makes it useful for weighting mining shares.
+func (b Block) Work() *big.Int {
+	return Target(b.ID()).Difficulty().Big()
+}
+
 // MarshalSia implements the encoding.SiaMarshaler interface.
 func (b Block) MarshalSia(w io.Writer) error {
-	w.Write(b.ParentID[:])
-	w.Write(b.Nonce[:])
-	w.Write(encoding.EncUint64(uint64(b.Timestamp)))
+	header := make([]byte, 0, 48)
+	header = append(header, b.ParentID[:]...)
+	header = append(header, b.Nonce[:]...)
+	header = append(header, encoding.EncUint64(uint64(b.Timestamp))...)
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
 	return encoding.NewEncoder(w).EncodeAll(b.MinerPayouts, b.Transactions)
 }
 
 // UnmarshalSia implements the encoding.SiaUnmarshaler interface.
 func (b *Block) UnmarshalSia(r io.Reader) error {
-	io.ReadFull(r, b.ParentID[:])
-	io.ReadFull(r, b.Nonce[:])
+	if _, err := io.ReadFull(r, b.ParentID[:]); err != nil {
+		return err
+	}
+	if _, err := io.ReadFull(r, b.Nonce[:]); err != nil {
+		return err
+	}
 	tsBytes := make([]byte, 8)
-	io.ReadFull(r, tsBytes)
+	if _, err := io.ReadFull(r, tsBytes); err != nil {
+		return err
+	}
 	b.Timestamp = Timestamp(encoding.DecUint64(tsBytes))
 	return encoding.NewDecoder(r).DecodeAll(&b.MinerPayouts, &b.Transactions)
 }
 
+// DecodeBlock reads a single encoded block from r. Malformed or truncated
+// input results in an error.
+func DecodeBlock(r io.Reader) (b Block, err error) {
+	err = encoding.NewDecoder(r).Decode(&b)
+	return b, err
+}
+
 // MarshalJSON marshales a block id as a hex string.
 func (bid BlockID) MarshalJSON() ([]byte, error) {
 	return json.Marshal(bid.String())
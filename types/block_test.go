@@ -1,6 +1,7 @@
 package types
 
 import (
+	"bytes"
 	"testing"
 
 	"github.com/NebulousLabs/Sia/crypto"
@@ -232,6 +233,44 @@ func TestBlockMinerPayoutID(t *testing.T) {
 	}
 }
 
+// TestBlockWork probes the Work method of the block type.
+func TestBlockWork(t *testing.T) {
+	// Compare the work of blocks with a range of nonces. A block with a lower
+	// ID should never represent less work.
+	var blocks []Block
+	for i := 0; i < 50; i++ {
+		var b Block
+		b.Nonce[0] = byte(i)
+		blocks = append(blocks, b)
+	}
+	lowest, highest := blocks[0], blocks[0]
+	for _, b1 := range blocks {
+		id1 := b1.ID()
+		for _, b2 := range blocks {
+			id2 := b2.ID()
+			if bytes.Compare(id1[:], id2[:]) < 0 && b1.Work().Cmp(b2.Work()) < 0 {
+				t.Fatal("block with a lower id has less work")
+			}
+		}
+		lowestID, highestID := lowest.ID(), highest.ID()
+		if bytes.Compare(id1[:], lowestID[:]) < 0 {
+			lowest = b1
+		}
+		if bytes.Compare(id1[:], highestID[:]) > 0 {
+			highest = b1
+		}
+	}
+	if lowest.Work().Cmp(highest.Work()) <= 0 {
+		t.Error("block with the lowest id should have more work than the block with the highest id")
+	}
+
+	// The work should match the difficulty of a target equal to the ID.
+	id := lowest.ID()
+	if lowest.Work().Cmp(Target(id).Difficulty().Big()) != 0 {
+		t.Error("block work does not match the difficulty of its id")
+	}
+}
+
 // TestBlockEncodes probes the MarshalSia and UnmarshalSia methods of the
 // Block type.
 func TestBlockEncoding(t *testing.T) {
@@ -252,3 +291,62 @@ func TestBlockEncoding(t *testing.T) {
 		t.Fatal("block changed after encode/decode:", b, decB)
 	}
 }
+
+// TestBlockEncodingDeterminism checks that decoding and re-encoding a block
+// produces identical bytes and an identical ID.
+func TestBlockEncodingDeterminism(t *testing.T) {
+	b := Block{
+		ParentID:  BlockID{1, 2, 3},
+		Nonce:     BlockNonce{4, 5, 6},
+		Timestamp: 7,
+		MinerPayouts: []SiacoinOutput{
+			{Value: CalculateCoinbase(0), UnlockHash: UnlockHash{8}},
+		},
+		Transactions: []Transaction{{
+			SiacoinOutputs: []SiacoinOutput{{Value: NewCurrency64(9)}},
+			FileContracts:  []FileContract{{Payout: NewCurrency64(10)}},
+			MinerFees:      []Currency{NewCurrency64(11)},
+			ArbitraryData:  [][]byte{[]byte("arbitrary data")},
+		}},
+	}
+	encB := encoding.Marshal(b)
+	decB, err := DecodeBlock(bytes.NewReader(encB))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(encoding.Marshal(decB), encB) {
+		t.Fatal("block encoding changed after decode/encode")
+	}
+	if decB.ID() != b.ID() {
+		t.Fatal("block id changed after decode/encode")
+	}
+}
+
+// TestDecodeBlockTruncated feeds every truncation of an encoded block to
+// DecodeBlock, checking that an error is returned instead of a panic.
+func TestDecodeBlockTruncated(t *testing.T) {
+	b := Block{
+		MinerPayouts: []SiacoinOutput{{Value: CalculateCoinbase(0)}},
+		Transactions: []Transaction{{
+			SiacoinOutputs: []SiacoinOutput{{Value: NewCurrency64(1)}},
+			MinerFees:      []Currency{NewCurrency64(2)},
+		}},
+	}
+	encB := encoding.Marshal(b)
+	for i := 0; i < len(encB); i++ {
+		_, err := DecodeBlock(bytes.NewReader(encB[:i]))
+		if err == nil {
+			t.Fatal("truncated block of length", i, "was decoded without error")
+		}
+	}
+
+	// Corrupt the length prefix of the miner payouts.
+	corrupt := append([]byte(nil), encB...)
+	for i := 48; i < 56; i++ {
+		corrupt[i] = 0xFF
+	}
+	_, err := DecodeBlock(bytes.NewReader(corrupt))
+	if err == nil {
+		t.Fatal("block with a corrupt length prefix was decoded without error")
+	}
+}
@@ -39,6 +39,18 @@ var (
 	// The GenesisID is used in many places. Calculating it once saves lots of
 	// redundant computation.
 	GenesisID BlockID
+
+	// MaxArbitraryDataSize is the maximum combined size of the arbitrary
+	// data in a transaction, preventing a single transaction from filling
+	// most of a block with arbitrary bytes. The limit is only enforced
+	// starting at ArbitraryDataHardforkHeight.
+	MaxArbitraryDataSize = uint64(500e3)
+
+	// The hardfork heights are the heights of the first blocks that must
+	// follow the corresponding new consensus rules.
+	ArbitraryDataHardforkHeight        BlockHeight
+	DuplicateTransactionHardforkHeight BlockHeight
+	StrictTimestampHardforkHeight      BlockHeight
 )
 
 // init checks which build constant is in place and initializes the variables
@@ -63,6 +75,10 @@ func init() {
 
 		MinimumCoinbase = 30e3
 
+		ArbitraryDataHardforkHeight = 10e3
+		DuplicateTransactionHardforkHeight = 10e3
+		StrictTimestampHardforkHeight = 10e3
+
 		GenesisSiafundAllocation = []SiafundOutput{
 			{
 				Value:      NewCurrency64(2000),
@@ -97,6 +113,16 @@ func init() {
 
 		MinimumCoinbase = 299990 // Minimum coinbase is hit after 10 blocks to make testing minimum-coinbase code easier.
 
+		// Hardforks activate after a few blocks, so that most tests run
+		// under the new rules while the old rules can still be tested. The
+		// strict timestamp rule makes every block advance the median
+		// timestamp, and tests mine blocks much faster than one per second,
+		// so it activates later to keep the timestamps of long test chains
+		// from running into the future threshold.
+		ArbitraryDataHardforkHeight = 10
+		DuplicateTransactionHardforkHeight = 10
+		StrictTimestampHardforkHeight = 1e3
+
 		GenesisSiafundAllocation = []SiafundOutput{
 			{
 				Value:      NewCurrency64(2000),
@@ -174,6 +200,30 @@ func init() {
 		// or less permanently settles around 2%.
 		MinimumCoinbase = 30e3
 
+		// HARDFORK 140,000
+		//
+		// Transactions were originally allowed to carry any amount of
+		// arbitrary data that fit in a block. Starting at block 140,000 the
+		// combined size of the arbitrary data in a transaction is limited to
+		// MaxArbitraryDataSize.
+		ArbitraryDataHardforkHeight = 140e3
+
+		// HARDFORK 140,000
+		//
+		// Originally, a transaction that spends no outputs could appear in a
+		// block more than once. Starting at block 140,000 every transaction
+		// in a block must be unique.
+		DuplicateTransactionHardforkHeight = 140e3
+
+		// HARDFORK 135,000
+		//
+		// Originally, a block's timestamp only needed to be at least the
+		// median timestamp of the previous MedianTimestampWindow blocks,
+		// allowing miners to stall the median by reusing it. Starting at
+		// block 135,000 the timestamp must be strictly greater than the
+		// median.
+		StrictTimestampHardforkHeight = 135e3
+
 		GenesisSiafundAllocation = []SiafundOutput{
 			{
 				Value:      NewCurrency64(2),
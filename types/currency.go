@@ -14,6 +14,7 @@ import (
 	"io"
 	"math"
 	"math/big"
+	"strings"
 
 	"github.com/NebulousLabs/Sia/build"
 	"github.com/NebulousLabs/Sia/encoding"
@@ -41,6 +42,15 @@ var (
 	// ErrUint64Overflow is the error that is returned if converting to a
 	// unit64 would cause an overflow.
 	ErrUint64Overflow = errors.New("cannot return the uint64 of this currency - result is an overflow")
+
+	errMalformedCurrency    = errors.New("malformed currency amount")
+	errMissingCurrencyUnits = errors.New("currency amount is missing units")
+	errFractionalHastings   = errors.New("currency amount has a non-integer number of hastings")
+
+	// currencyUnits are the human-readable units of siacoins, in increasing
+	// order of magnitude. Each unit is 1000 times larger than the previous
+	// unit, and "SC" is equal to SiacoinPrecision.
+	currencyUnits = []string{"pS", "nS", "uS", "mS", "SC", "KS", "MS", "GS", "TS"}
 )
 
 // NewCurrency creates a Currency value from a big.Int. Undefined behavior
@@ -171,6 +181,17 @@ func (x Currency) Sub(y Currency) (c Currency) {
 	return
 }
 
+// SubChecked returns a new Currency value c = x - y. Unlike Sub, an error is
+// returned instead of triggering a critical error when x < y, which makes
+// SubChecked suitable for values that can be influenced by users.
+func (x Currency) SubChecked(y Currency) (c Currency, err error) {
+	if x.Cmp(y) < 0 {
+		return ZeroCurrency, ErrNegativeCurrency
+	}
+	c.i.Sub(&x.i, &y.i)
+	return c, nil
+}
+
 // Uint64 converts a Currency to a uint64. An error is returned because this
 // function is sometimes called on values that can be determined by users -
 // rather than have all user-facing points do input checking, the input
@@ -229,6 +250,85 @@ func (c Currency) String() string {
 	return c.i.String()
 }
 
+// HumanString returns the Currency as a string with human-readable units. The
+// unit used will be the largest unit that results in a value greater than 1,
+// and the value is rounded to 4 significant digits. Values smaller than a
+// picosiacoin are displayed in hastings.
+func (c Currency) HumanString() string {
+	pico := SiacoinPrecision.Div64(1e12)
+	if c.Cmp(pico) < 0 {
+		return c.String() + " H"
+	}
+
+	// Iterate until a unit greater than c is found.
+	mag := pico
+	unit := ""
+	for _, unit = range currencyUnits {
+		if c.Cmp(mag.Mul64(1e3)) < 0 {
+			break
+		} else if unit != currencyUnits[len(currencyUnits)-1] {
+			// Don't perform the multiply on the last iteration, that would
+			// give 1.235 TS instead of 1235 TS.
+			mag = mag.Mul64(1e3)
+		}
+	}
+
+	num := new(big.Rat).SetInt(c.Big())
+	denom := new(big.Rat).SetInt(mag.Big())
+	res, _ := new(big.Rat).Mul(num, denom.Inv(denom)).Float64()
+	return fmt.Sprintf("%.4g %s", res, unit)
+}
+
+// ParseCurrency converts a human-readable amount of siacoins, such as "1.5 KS"
+// or "250SC", to a Currency. Whitespace around the amount and between the
+// amount and the unit is ignored. Amounts in hastings use the unit "H" and
+// must be integers.
+func ParseCurrency(s string) (Currency, error) {
+	s = strings.TrimSpace(s)
+	for i, unit := range currencyUnits {
+		if !strings.HasSuffix(s, unit) {
+			continue
+		}
+		r, err := parseCurrencyAmount(strings.TrimSuffix(s, unit))
+		if err != nil {
+			return Currency{}, err
+		}
+		// SC is the unit at index 4, and is 10^24 hastings.
+		exp := 24 + 3*(int64(i)-4)
+		mag := new(big.Int).Exp(big.NewInt(10), big.NewInt(exp), nil)
+		r.Mul(r, new(big.Rat).SetInt(mag))
+		if !r.IsInt() {
+			return Currency{}, errFractionalHastings
+		}
+		return NewCurrency(r.Num()), nil
+	}
+	if strings.HasSuffix(s, "H") {
+		r, err := parseCurrencyAmount(strings.TrimSuffix(s, "H"))
+		if err != nil {
+			return Currency{}, err
+		}
+		if !r.IsInt() {
+			return Currency{}, errFractionalHastings
+		}
+		return NewCurrency(r.Num()), nil
+	}
+	return Currency{}, errMissingCurrencyUnits
+}
+
+// parseCurrencyAmount parses the numeric part of a human-readable currency
+// amount. Only non-negative decimal numbers are accepted.
+func parseCurrencyAmount(s string) (*big.Rat, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || strings.ContainsAny(s, "/eE+-") {
+		return nil, errMalformedCurrency
+	}
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return nil, errMalformedCurrency
+	}
+	return r, nil
+}
+
 // Scan implements the fmt.Scanner interface, allowing Currency values to be
 // scanned from text.
 func (c *Currency) Scan(s fmt.ScanState, ch rune) error {
@@ -2,6 +2,7 @@ package types
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"math"
 	"math/big"
@@ -190,6 +191,49 @@ func TestCurrencyMarshalJSON(t *testing.T) {
 	}
 }
 
+// TestCurrencyJSONRoundTrip checks that Currency values survive a round trip
+// through encoding/json, including values that do not fit in an int64, and
+// that invalid inputs are rejected.
+func TestCurrencyJSONRoundTrip(t *testing.T) {
+	huge, _ := new(big.Int).SetString("123456789012345678901234567890123456789", 10)
+	values := []Currency{
+		ZeroCurrency,
+		NewCurrency64(1),
+		NewCurrency64(math.MaxUint64),
+		NewCurrency(huge),
+		SiacoinPrecision.Mul64(1e9),
+	}
+	for _, c := range values {
+		js, err := json.Marshal(c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(js) != `"`+c.String()+`"` {
+			t.Error("currency was not marshalled as a decimal string:", string(js))
+		}
+		var decoded Currency
+		err = json.Unmarshal(js, &decoded)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if decoded.Cmp(c) != 0 {
+			t.Error("currency did not survive the round trip:", c, decoded)
+		}
+	}
+
+	// Invalid inputs should be rejected.
+	for _, js := range []string{`"-1"`, `"-123456789012345678901234567890"`, `"1.5"`, `"abc"`, `"12 SC"`, `""`} {
+		var c Currency
+		err := json.Unmarshal([]byte(js), &c)
+		if err == nil {
+			t.Error("invalid currency was accepted:", js)
+		}
+		if c.i.Sign() < 0 {
+			t.Error("negative currency decoded from", js)
+		}
+	}
+}
+
 // TestCurrencyMarshalSia probes the MarshalSia and UnmarshalSia functions of
 // the currency type.
 func TestCurrencyMarshalSia(t *testing.T) {
@@ -353,3 +397,108 @@ func TestCurrencyUint64(t *testing.T) {
 		t.Error("result is not being zeroed in the event of an error")
 	}
 }
+
+// TestParseCurrency probes the ParseCurrency function.
+func TestParseCurrency(t *testing.T) {
+	tests := []struct {
+		in  string
+		out string
+		err error
+	}{
+		{"250 SC", "250000000000000000000000000", nil},
+		{"250SC", "250000000000000000000000000", nil},
+		{"  1.5   KS ", "1500000000000000000000000000", nil},
+		{"0.001 SC", "1000000000000000000000", nil},
+		{"1 pS", "1000000000000", nil},
+		{"1 mS", "1000000000000000000000", nil},
+		{"1 MS", "1000000000000000000000000000000", nil},
+		{"2 TS", "2000000000000000000000000000000000000", nil},
+		{"0 SC", "0", nil},
+		{"12345 H", "12345", nil},
+		{"1.5 H", "", errFractionalHastings},
+		{"0.0000000000001 pS", "", errFractionalHastings},
+		{"-1 SC", "", errMalformedCurrency},
+		{"1/2 SC", "", errMalformedCurrency},
+		{"1e3 SC", "", errMalformedCurrency},
+		{"abc SC", "", errMalformedCurrency},
+		{"SC", "", errMalformedCurrency},
+		{"5 uSC", "", errMalformedCurrency},
+		{"5 XS", "", errMissingCurrencyUnits},
+		{"5 sc", "", errMissingCurrencyUnits},
+		{"5", "", errMissingCurrencyUnits},
+		{"", "", errMissingCurrencyUnits},
+	}
+	for _, test := range tests {
+		c, err := ParseCurrency(test.in)
+		if err != test.err {
+			t.Errorf("ParseCurrency(%q): expected error %v, got %v", test.in, test.err, err)
+			continue
+		}
+		if err == nil && c.String() != test.out {
+			t.Errorf("ParseCurrency(%q): expected %v, got %v", test.in, test.out, c)
+		}
+	}
+}
+
+// TestCurrencyHumanString probes the HumanString method of the currency type,
+// and checks that human-readable strings can be parsed back.
+func TestCurrencyHumanString(t *testing.T) {
+	tests := []struct {
+		in, out string
+		exact   bool // whether the output parses back to exactly the input
+	}{
+		{"1", "1 H", true},
+		{"100000000000", "100000000000 H", true},
+		{"1000000000000", "1 pS", true},
+		{"1234560000000", "1.235 pS", false},
+		{"1000000000000000000000000", "1 SC", true},
+		{"1500000000000000000000000000", "1.5 KS", true},
+		{"1234560000000000000000000000000000000000", "1235 TS", false},
+	}
+	for _, test := range tests {
+		i, _ := new(big.Int).SetString(test.in, 10)
+		c := NewCurrency(i)
+		out := c.HumanString()
+		if out != test.out {
+			t.Errorf("HumanString(%v): expected %v, got %v", test.in, test.out, out)
+		}
+		parsed, err := ParseCurrency(out)
+		if err != nil {
+			t.Errorf("could not parse the output of HumanString(%v): %v", test.in, err)
+		} else if test.exact && parsed.Cmp(c) != 0 {
+			t.Errorf("HumanString(%v) did not parse back to the same value: %v", test.in, parsed)
+		}
+	}
+}
+
+// TestCurrencySubChecked probes the SubChecked function of the currency type
+// around the underflow boundary.
+func TestCurrencySubChecked(t *testing.T) {
+	c5 := NewCurrency64(5)
+	c6 := NewCurrency64(6)
+
+	diff, err := c6.SubChecked(c5)
+	if err != nil || diff.Cmp(NewCurrency64(1)) != 0 {
+		t.Error("6 - 5 should be 1:", diff, err)
+	}
+	diff, err = c5.SubChecked(c5)
+	if err != nil || !diff.IsZero() {
+		t.Error("5 - 5 should be 0:", diff, err)
+	}
+	diff, err = c5.SubChecked(c6)
+	if err != ErrNegativeCurrency {
+		t.Error("expecting ErrNegativeCurrency:", err)
+	}
+	if !diff.IsZero() {
+		t.Error("underflow should return zero:", diff)
+	}
+	diff, err = ZeroCurrency.SubChecked(NewCurrency64(1))
+	if err != ErrNegativeCurrency || !diff.IsZero() {
+		t.Error("0 - 1 should underflow:", diff, err)
+	}
+
+	// The operands should not be modified.
+	if c5.Cmp(NewCurrency64(5)) != 0 || c6.Cmp(NewCurrency64(6)) != 0 {
+		t.Error("SubChecked modified its operands")
+	}
+}
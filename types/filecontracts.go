@@ -108,6 +108,36 @@ func (fcid FileContractID) StorageProofOutputID(proofStatus ProofStatus, i uint6
 	))
 }
 
+// VerifyStorageProof checks that the segment and hash set of a storage proof
+// prove that the segment at 'segmentIndex' is a part of the data with Merkle
+// root 'merkleRoot', where the data is 'numSegments' segments long. This is
+// the same check that the consensus set performs when accepting a storage
+// proof, and allows hosts to check their proofs before submitting them.
+//
+// The final segment of the data may be shorter than crypto.SegmentSize, in
+// which case the unused bytes of the segment must be zero.
+func VerifyStorageProof(sp StorageProof, merkleRoot crypto.Hash, segmentIndex uint64, numSegments uint64) bool {
+	if segmentIndex >= numSegments {
+		return false
+	}
+	if segmentIndex != numSegments-1 {
+		return crypto.VerifySegment(sp.Segment[:], sp.HashSet, numSegments, segmentIndex, merkleRoot)
+	}
+
+	// The length of the final segment is unknown. Try every length that
+	// leaves only zeros unused.
+	minLen := crypto.SegmentSize
+	for minLen > 1 && sp.Segment[minLen-1] == 0 {
+		minLen--
+	}
+	for segmentLen := minLen; segmentLen <= crypto.SegmentSize; segmentLen++ {
+		if crypto.VerifySegment(sp.Segment[:segmentLen], sp.HashSet, numSegments, segmentIndex, merkleRoot) {
+			return true
+		}
+	}
+	return false
+}
+
 // PostTax returns the amount of currency remaining in a file contract payout
 // after tax.
 func PostTax(height BlockHeight, payout Currency) Currency {
@@ -2,6 +2,8 @@ package types
 
 import (
 	"testing"
+
+	"github.com/NebulousLabs/Sia/crypto"
 )
 
 // TestFileContractTax probes the Tax function.
@@ -37,3 +39,66 @@ func TestTax(t *testing.T) {
 		}
 	}
 }
+
+// TestVerifyStorageProof checks that VerifyStorageProof accepts valid storage
+// proofs and rejects proofs that have been tampered with.
+func TestVerifyStorageProof(t *testing.T) {
+	// Create data whose final segment is not a full segment.
+	data, err := crypto.RandBytes(crypto.SegmentSize*10 + 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := crypto.MerkleRoot(data)
+	numSegments := crypto.CalculateLeaves(uint64(len(data)))
+	buildProof := func(index uint64) StorageProof {
+		base, hashSet := crypto.MerkleProof(data, index)
+		sp := StorageProof{HashSet: hashSet}
+		copy(sp.Segment[:], base)
+		return sp
+	}
+
+	// Check valid proofs for a middle segment and for the final segment.
+	sp := buildProof(3)
+	if !VerifyStorageProof(sp, root, 3, numSegments) {
+		t.Fatal("valid storage proof was rejected")
+	}
+	finalSP := buildProof(numSegments - 1)
+	if !VerifyStorageProof(finalSP, root, numSegments-1, numSegments) {
+		t.Fatal("valid storage proof of the final segment was rejected")
+	}
+
+	// Check tampered variants of the proofs.
+	tampered := sp
+	tampered.Segment[0]++
+	if VerifyStorageProof(tampered, root, 3, numSegments) {
+		t.Error("proof with a tampered segment was accepted")
+	}
+	tampered = sp
+	tampered.HashSet = append([]crypto.Hash(nil), sp.HashSet...)
+	tampered.HashSet[0][0]++
+	if VerifyStorageProof(tampered, root, 3, numSegments) {
+		t.Error("proof with a tampered hash set was accepted")
+	}
+	tampered = sp
+	tampered.HashSet = sp.HashSet[:len(sp.HashSet)-1]
+	if VerifyStorageProof(tampered, root, 3, numSegments) {
+		t.Error("proof with a truncated hash set was accepted")
+	}
+	tampered = finalSP
+	tampered.Segment[crypto.SegmentSize-1] = 1
+	if VerifyStorageProof(tampered, root, numSegments-1, numSegments) {
+		t.Error("final segment proof with nonzero padding was accepted")
+	}
+	if VerifyStorageProof(sp, root, 4, numSegments) {
+		t.Error("proof was accepted for the wrong segment index")
+	}
+	if VerifyStorageProof(sp, root, 3, numSegments*2) {
+		t.Error("proof was accepted for the wrong number of segments")
+	}
+	if VerifyStorageProof(sp, crypto.Hash{}, 3, numSegments) {
+		t.Error("proof was accepted for the wrong Merkle root")
+	}
+	if VerifyStorageProof(sp, root, numSegments, numSegments) {
+		t.Error("proof was accepted for an out of range segment index")
+	}
+}
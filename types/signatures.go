@@ -25,7 +25,9 @@ var (
 	ErrEntropyKey                = errors.New("transaction tries to sign an entproy public key")
 	ErrFrivilousSignature        = errors.New("transaction contains a frivilous siganture")
 	ErrInvalidPubKeyIndex        = errors.New("transaction contains a signature that points to a nonexistent public key")
+	ErrInvalidSignatureThreshold = errors.New("number of required signatures must be between 1 and the number of public keys")
 	ErrInvalidUnlockHashChecksum = errors.New("provided unlock hash has an invalid checksum")
+	ErrMissingRevisionSignature  = errors.New("transaction has a file contract revision with missing signatures")
 	ErrMissingSignatures         = errors.New("transaction has inputs with missing signatures")
 	ErrPrematureSignature        = errors.New("timelock on signature has not expired")
 	ErrPublicKeyOveruse          = errors.New("public key was used multiple times while signing transaction")
@@ -122,9 +124,25 @@ type (
 		possibleKeys        []SiaPublicKey
 		usedKeys            map[uint64]struct{}
 		index               int
+		revision            bool
 	}
 )
 
+// MultisigUnlockConditions returns UnlockConditions that require
+// 'signaturesRequired' of the provided public keys to sign, commonly called
+// m-of-n unlock conditions.
+func MultisigUnlockConditions(pks []SiaPublicKey, signaturesRequired uint64) (UnlockConditions, error) {
+	if signaturesRequired == 0 || signaturesRequired > uint64(len(pks)) {
+		return UnlockConditions{}, ErrInvalidSignatureThreshold
+	}
+	keys := make([]SiaPublicKey, len(pks))
+	copy(keys, pks)
+	return UnlockConditions{
+		PublicKeys:         keys,
+		SignaturesRequired: signaturesRequired,
+	}, nil
+}
+
 // UnlockHash calculates the root hash of a Merkle tree of the
 // UnlockConditions object. The leaves of this tree are formed by taking the
 // hash of the timelock, the hash of the public keys (one leaf each), and the
@@ -305,6 +323,7 @@ func (t *Transaction) validSignatures(currentHeight BlockHeight) error {
 			possibleKeys:        revision.UnlockConditions.PublicKeys,
 			usedKeys:            make(map[uint64]struct{}),
 			index:               i,
+			revision:            true,
 		}
 	}
 	for i, input := range t.SiafundInputs {
@@ -381,11 +400,21 @@ func (t *Transaction) validSignatures(currentHeight BlockHeight) error {
 		inSig.remainingSignatures--
 	}
 
-	// Check that all inputs have been sufficiently signed.
+	// Check that all inputs have been sufficiently signed. Missing input
+	// signatures are reported before missing revision signatures so that the
+	// error does not depend on the iteration order of the map.
+	var missingRevisionSignature bool
 	for _, reqSigs := range sigMap {
-		if reqSigs.remainingSignatures != 0 {
+		if reqSigs.remainingSignatures == 0 {
+			continue
+		}
+		if !reqSigs.revision {
 			return ErrMissingSignatures
 		}
+		missingRevisionSignature = true
+	}
+	if missingRevisionSignature {
+		return ErrMissingRevisionSignature
 	}
 
 	return nil
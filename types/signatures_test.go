@@ -345,6 +345,17 @@ func TestTransactionValidSignatures(t *testing.T) {
 	}
 	txn.TransactionSignatures[0] = tmpTxn0
 
+	// Try to revise a file contract when not every required signature is
+	// available.
+	sigs := txn.TransactionSignatures
+	txn.TransactionSignatures = append([]TransactionSignature{}, sigs[:4]...)
+	txn.TransactionSignatures = append(txn.TransactionSignatures, sigs[5])
+	err = txn.validSignatures(10)
+	if err != ErrMissingRevisionSignature {
+		t.Error(err)
+	}
+	txn.TransactionSignatures = sigs
+
 	// Try to spend a transaction when not every required signature is
 	// available.
 	txn.TransactionSignatures = txn.TransactionSignatures[1:]
@@ -366,3 +377,32 @@ func TestSiaPublicKeyString(t *testing.T) {
 		t.Error("got wrong value for spk.String():", spk.String())
 	}
 }
+
+// TestMultisigUnlockConditions probes the MultisigUnlockConditions function.
+func TestMultisigUnlockConditions(t *testing.T) {
+	pks := []SiaPublicKey{
+		{Algorithm: SignatureEd25519, Key: []byte{1}},
+		{Algorithm: SignatureEd25519, Key: []byte{2}},
+		{Algorithm: SignatureEd25519, Key: []byte{3}},
+	}
+	uc, err := MultisigUnlockConditions(pks, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if uc.SignaturesRequired != 2 || len(uc.PublicKeys) != 3 || uc.Timelock != 0 {
+		t.Error("unlock conditions were constructed incorrectly:", uc)
+	}
+	pks[0].Key = []byte{4}
+	if uc.PublicKeys[0].Key[0] != 1 {
+		t.Error("unlock conditions share memory with the input keys")
+	}
+
+	_, err = MultisigUnlockConditions(pks, 0)
+	if err != ErrInvalidSignatureThreshold {
+		t.Error("expecting ErrInvalidSignatureThreshold, got", err)
+	}
+	_, err = MultisigUnlockConditions(pks, 4)
+	if err != ErrInvalidSignatureThreshold {
+		t.Error("expecting ErrInvalidSignatureThreshold, got", err)
+	}
+}
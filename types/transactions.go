@@ -130,7 +130,11 @@ type (
 )
 
 // ID returns the id of a transaction, which is taken by marshalling all of the
-// fields except for the signatures and taking the hash of the result.
+// fields except for the signatures and taking the hash of the result. The IDs
+// of the outputs and file contracts created by the transaction are derived
+// from the same fields, so modifying any field other than the signatures
+// changes the transaction ID and all of the derived IDs. Signatures can be
+// added without changing any IDs.
 func (t Transaction) ID() TransactionID {
 	return TransactionID(crypto.HashAll(
 		t.SiacoinInputs,
@@ -222,10 +226,16 @@ func (t Transaction) SiacoinOutputSum() (sum Currency) {
 	}
 
 	// Add the miner fees.
+	sum = sum.Add(t.TotalMinerFees())
+
+	return
+}
+
+// TotalMinerFees returns the sum of the miner fees in the transaction.
+func (t Transaction) TotalMinerFees() (sum Currency) {
 	for _, fee := range t.MinerFees {
 		sum = sum.Add(fee)
 	}
-
 	return
 }
 
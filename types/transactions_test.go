@@ -45,6 +45,36 @@ func TestIDs(t *testing.T) {
 	}
 }
 
+// TestTransactionIDStability checks that modifying the arbitrary data of a
+// transaction changes the transaction ID and the derived output IDs, while
+// modifying the signatures does not.
+func TestTransactionIDStability(t *testing.T) {
+	txn := Transaction{
+		SiacoinOutputs: []SiacoinOutput{{Value: NewCurrency64(1)}},
+		ArbitraryData:  [][]byte{[]byte("data")},
+	}
+	id := txn.ID()
+	scoid := txn.SiacoinOutputID(0)
+	if txn.ID() != id || txn.SiacoinOutputID(0) != scoid {
+		t.Fatal("ids are not deterministic")
+	}
+
+	// Adding a signature should not change the ids.
+	txn.TransactionSignatures = []TransactionSignature{{Signature: []byte("sig")}}
+	if txn.ID() != id || txn.SiacoinOutputID(0) != scoid {
+		t.Error("adding a signature changed the ids")
+	}
+
+	// Modifying the arbitrary data should change the ids.
+	txn.ArbitraryData[0] = []byte("different data")
+	if txn.ID() == id {
+		t.Error("modifying the arbitrary data did not change the transaction id")
+	}
+	if txn.SiacoinOutputID(0) == scoid {
+		t.Error("modifying the arbitrary data did not change the siacoin output id")
+	}
+}
+
 // TestTransactionSiacoinOutputSum probes the SiacoinOutputSum method of the
 // Transaction type.
 func TestTransactionSiacoinOutputSum(t *testing.T) {
@@ -66,4 +96,13 @@ func TestTransactionSiacoinOutputSum(t *testing.T) {
 	if txn.SiacoinOutputSum().Cmp(NewCurrency64(654321)) != 0 {
 		t.Error("wrong siacoin output sum was calculated, got:", txn.SiacoinOutputSum())
 	}
+
+	if txn.TotalMinerFees().Cmp(NewCurrency64(650000)) != 0 {
+		t.Error("wrong miner fee total was calculated, got:", txn.TotalMinerFees())
+	}
+
+	// An empty transaction has no outputs.
+	if !(Transaction{}).SiacoinOutputSum().IsZero() {
+		t.Error("empty transaction should have a zero siacoin output sum")
+	}
 }
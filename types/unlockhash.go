@@ -82,6 +82,14 @@ func (uh *UnlockHash) LoadString(strUH string) error {
 	return nil
 }
 
+// ParseUnlockHash parses a hex representation (including checksum) of an
+// unlock hash, as produced by String. An error is returned if the string is
+// invalid or fails the checksum, which protects against mistyped addresses.
+func ParseUnlockHash(s string) (uh UnlockHash, err error) {
+	err = uh.LoadString(s)
+	return uh, err
+}
+
 // Len implements the Len method of sort.Interface.
 func (uhs UnlockHashSlice) Len() int {
 	return len(uhs)
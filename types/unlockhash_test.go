@@ -103,6 +103,40 @@ func TestUnlockHashStringMarshalling(t *testing.T) {
 	}
 }
 
+// TestParseUnlockHash checks that ParseUnlockHash recovers the unlock hash
+// produced by String, and rejects every single-character corruption.
+func TestParseUnlockHash(t *testing.T) {
+	uh := UnlockConditions{SignaturesRequired: 1}.UnlockHash()
+	str := uh.String()
+	parsed, err := ParseUnlockHash(str)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed != uh {
+		t.Fatal("parsed unlock hash does not match the original")
+	}
+
+	// Replace each character with a different hex digit.
+	for i := range str {
+		corrupt := []byte(str)
+		if corrupt[i] == '0' {
+			corrupt[i] = '1'
+		} else {
+			corrupt[i] = '0'
+		}
+		_, err := ParseUnlockHash(string(corrupt))
+		if err != ErrInvalidUnlockHashChecksum {
+			t.Fatalf("corrupting character %v: expected %v, got %v", i, ErrInvalidUnlockHashChecksum, err)
+		}
+	}
+
+	// Strings of the wrong length should be rejected.
+	_, err = ParseUnlockHash(str[1:])
+	if err != ErrUnlockHashWrongLen {
+		t.Error("expected", ErrUnlockHashWrongLen, "got", err)
+	}
+}
+
 // TestUnlockHashSliceSorting checks that the sort method correctly sorts
 // unlock hash slices.
 func TestUnlockHashSliceSorting(t *testing.T) {
@@ -12,15 +12,18 @@ import (
 )
 
 var (
+	ErrArbitraryDataTooLarge            = errors.New("transaction has too much arbitrary data")
 	ErrDoubleSpend                      = errors.New("transaction uses a parent object twice")
 	ErrFileContractWindowEndViolation   = errors.New("file contract window must end at least one block after it starts")
 	ErrFileContractWindowStartViolation = errors.New("file contract window must start in the future")
 	ErrFileContractOutputSumViolation   = errors.New("file contract has invalid output sums")
+	ErrInvalidFileContractPayouts       = errors.New("file contract proof outputs do not sum to the payout minus tax")
 	ErrNonZeroClaimStart                = errors.New("transaction has a siafund output with a non-zero siafund claim")
 	ErrNonZeroRevision                  = errors.New("new file contract has a nonzero revision number")
 	ErrStorageProofWithOutputs          = errors.New("transaction has both a storage proof and other outputs")
 	ErrTimelockNotSatisfied             = errors.New("timelock has not been met")
 	ErrTransactionTooLarge              = errors.New("transaction is too large to fit in a block")
+	ErrZeroFileContractPayout           = errors.New("file contract cannot have a zero payout")
 	ErrZeroMinerFee                     = errors.New("transaction has a zero value miner fee")
 	ErrZeroOutput                       = errors.New("transaction cannot have an output or payout that has zero value")
 	ErrZeroRevision                     = errors.New("transaction has a file contract revision with RevisionNumber=0")
@@ -60,10 +63,10 @@ func (t Transaction) correctFileContracts(currentHeight BlockHeight) error {
 		}
 		outputPortion := PostTax(currentHeight, fc.Payout)
 		if validProofOutputSum.Cmp(outputPortion) != 0 {
-			return ErrFileContractOutputSumViolation
+			return ErrInvalidFileContractPayouts
 		}
 		if missedProofOutputSum.Cmp(outputPortion) != 0 {
-			return ErrFileContractOutputSumViolation
+			return ErrInvalidFileContractPayouts
 		}
 	}
 	return nil
@@ -119,6 +122,23 @@ func (t Transaction) fitsInABlock() error {
 	return nil
 }
 
+// arbitraryDataWithinLimit checks that the combined size of the arbitrary
+// data of the transaction does not exceed MaxArbitraryDataSize. The limit does
+// not apply before ArbitraryDataHardforkHeight.
+func (t Transaction) arbitraryDataWithinLimit(currentHeight BlockHeight) error {
+	if currentHeight < ArbitraryDataHardforkHeight {
+		return nil
+	}
+	var size uint64
+	for _, arb := range t.ArbitraryData {
+		size += uint64(len(arb))
+	}
+	if size > MaxArbitraryDataSize {
+		return ErrArbitraryDataTooLarge
+	}
+	return nil
+}
+
 // followsMinimumValues checks that all outputs adhere to the rules for the
 // minimum allowed value (generally 1).
 func (t Transaction) followsMinimumValues() error {
@@ -129,7 +149,7 @@ func (t Transaction) followsMinimumValues() error {
 	}
 	for _, fc := range t.FileContracts {
 		if fc.Payout.IsZero() {
-			return ErrZeroOutput
+			return ErrZeroFileContractPayout
 		}
 	}
 	for _, sfo := range t.SiafundOutputs {
@@ -270,6 +290,10 @@ func (t Transaction) StandaloneValid(currentHeight BlockHeight) (err error) {
 	if err != nil {
 		return
 	}
+	err = t.arbitraryDataWithinLimit(currentHeight)
+	if err != nil {
+		return
+	}
 	err = t.followsStorageProofRules()
 	if err != nil {
 		return
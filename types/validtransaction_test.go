@@ -56,24 +56,24 @@ func TestTransactionCorrectFileContracts(t *testing.T) {
 	// Attempt under and over output sums.
 	txn.FileContracts[0].ValidProofOutputs[0].Value = NewCurrency64(69e3)
 	err = txn.correctFileContracts(30)
-	if err != ErrFileContractOutputSumViolation {
+	if err != ErrInvalidFileContractPayouts {
 		t.Error(err)
 	}
 	txn.FileContracts[0].ValidProofOutputs[0].Value = NewCurrency64(71e3)
 	err = txn.correctFileContracts(30)
-	if err != ErrFileContractOutputSumViolation {
+	if err != ErrInvalidFileContractPayouts {
 		t.Error(err)
 	}
 	txn.FileContracts[0].ValidProofOutputs[0].Value = NewCurrency64(70e3)
 
 	txn.FileContracts[0].MissedProofOutputs[0].Value = NewCurrency64(69e3)
 	err = txn.correctFileContracts(30)
-	if err != ErrFileContractOutputSumViolation {
+	if err != ErrInvalidFileContractPayouts {
 		t.Error(err)
 	}
 	txn.FileContracts[0].MissedProofOutputs[0].Value = NewCurrency64(71e3)
 	err = txn.correctFileContracts(30)
-	if err != ErrFileContractOutputSumViolation {
+	if err != ErrInvalidFileContractPayouts {
 		t.Error(err)
 	}
 	txn.FileContracts[0].MissedProofOutputs[0].Value = NewCurrency64(70e3)
@@ -97,6 +97,46 @@ func TestTransactionCorrectFileContracts(t *testing.T) {
 	}
 }
 
+// TestFileContractPayoutsOffByOne checks that the proof outputs of a file
+// contract must sum to exactly the payout minus the tax.
+func TestFileContractPayoutsOffByOne(t *testing.T) {
+	payout := NewCurrency64(1e9)
+	postTax := PostTax(30, payout)
+	txn := Transaction{
+		FileContracts: []FileContract{{
+			WindowStart:        35,
+			WindowEnd:          40,
+			Payout:             payout,
+			ValidProofOutputs:  []SiacoinOutput{{Value: postTax}},
+			MissedProofOutputs: []SiacoinOutput{{Value: postTax}},
+		}},
+	}
+	if postTax.Add(Tax(30, payout)).Cmp(payout) != 0 {
+		t.Fatal("payout does not equal the outputs plus tax")
+	}
+	err := txn.correctFileContracts(30)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Shift the outputs by one in either direction.
+	for _, value := range []Currency{postTax.Add(NewCurrency64(1)), postTax.Sub(NewCurrency64(1))} {
+		txn.FileContracts[0].ValidProofOutputs[0].Value = value
+		err = txn.correctFileContracts(30)
+		if err != ErrInvalidFileContractPayouts {
+			t.Error("expected ErrInvalidFileContractPayouts, got", err)
+		}
+		txn.FileContracts[0].ValidProofOutputs[0].Value = postTax
+
+		txn.FileContracts[0].MissedProofOutputs[0].Value = value
+		err = txn.correctFileContracts(30)
+		if err != ErrInvalidFileContractPayouts {
+			t.Error("expected ErrInvalidFileContractPayouts, got", err)
+		}
+		txn.FileContracts[0].MissedProofOutputs[0].Value = postTax
+	}
+}
+
 // TestCorrectFileContractRevisions probes the correctFileContractRevisions
 // method of the Transaction type.
 func TestCorrectFileContractRevisions(t *testing.T) {
@@ -178,7 +218,7 @@ func TestTransactionFollowsMinimumValues(t *testing.T) {
 	txn.SiacoinOutputs[0].Value = NewCurrency64(1)
 	txn.FileContracts[0].Payout = ZeroCurrency
 	err = txn.followsMinimumValues()
-	if err != ErrZeroOutput {
+	if err != ErrZeroFileContractPayout {
 		t.Error(err)
 	}
 	txn.FileContracts[0].Payout = NewCurrency64(1)
@@ -461,3 +501,30 @@ func TestTransactionStandaloneValid(t *testing.T) {
 	}
 	txn.TransactionSignatures = nil
 }
+
+// TestTransactionArbitraryDataWithinLimit checks that transactions with more
+// arbitrary data than MaxArbitraryDataSize are rejected, starting at
+// ArbitraryDataHardforkHeight.
+func TestTransactionArbitraryDataWithinLimit(t *testing.T) {
+	// Split the data over two fields to check that the limit applies to the
+	// combined size.
+	txn := Transaction{ArbitraryData: [][]byte{
+		make([]byte, MaxArbitraryDataSize/2),
+		make([]byte, MaxArbitraryDataSize-MaxArbitraryDataSize/2),
+	}}
+	err := txn.StandaloneValid(ArbitraryDataHardforkHeight)
+	if err != nil {
+		t.Error("transaction at the arbitrary data limit was rejected:", err)
+	}
+	txn.ArbitraryData[1] = append(txn.ArbitraryData[1], 0)
+	err = txn.StandaloneValid(ArbitraryDataHardforkHeight)
+	if err != ErrArbitraryDataTooLarge {
+		t.Error("expected ErrArbitraryDataTooLarge, got", err)
+	}
+
+	// Before the hardfork, the limit should not be enforced.
+	err = txn.StandaloneValid(ArbitraryDataHardforkHeight - 1)
+	if err != nil {
+		t.Error("arbitrary data limit was enforced before the hardfork:", err)
+	}
+}